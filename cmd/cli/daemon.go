@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	. "github.com/rafa-mori/golife/internal"
+	"github.com/rafa-mori/golife/internal/control"
+	grpcsrv "github.com/rafa-mori/golife/services/grpc"
+	l "github.com/rafa-mori/logz"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// HTTPMux is the mux served by the daemon next to its control socket. It is set by the main package.
+var HTTPMux *http.ServeMux
+
+// CurrentManager returns the manager hosted by this process, or nil when it only acts as a client.
+func CurrentManager() LifeCycleManager { return manager }
+
+func daemonCommand() *cobra.Command {
+	var socketPath, manifestPath, httpAddr, grpcAddr string
+
+	var daemonCmd = &cobra.Command{
+		Use: "daemon",
+		Annotations: GetDescriptions([]string{
+			"Run the life cycle manager as a daemon",
+			"Run the life cycle manager as a daemon reachable through a Unix-domain control socket, so other golife commands can drive it",
+		}, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			var mgr LifeCycleManager
+			var mgrErr error
+			if manifestPath != "" {
+				mgr, mgrErr = createManagerFromManifest(manifestPath)
+			} else {
+				mgr, mgrErr = NewLifecycleMgrSig()
+			}
+			if mgrErr != nil {
+				l.Error(fmt.Sprintf("Fail to create manager: %s", mgrErr), map[string]interface{}{})
+				return
+			}
+			if runErr := runDaemon(mgr, socketPath, httpAddr, grpcAddr, manifestPath != ""); runErr != nil {
+				l.Error(fmt.Sprintf("Daemon error: %s", runErr), map[string]interface{}{})
+			}
+		},
+	}
+
+	daemonCmd.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
+	daemonCmd.Flags().StringVarP(&manifestPath, "file", "f", "", "Optional manifest to load and start on boot")
+	daemonCmd.Flags().StringVar(&httpAddr, "http", envOr("GOLIFE_HTTP_ADDR", ":8080"), "Address of the HTTP endpoint (empty to disable)")
+	daemonCmd.Flags().StringVar(&grpcAddr, "grpc", os.Getenv("GOLIFE_GRPC_ADDR"), "Address of the gRPC API, e.g. :50051 (empty to disable)")
+
+	return daemonCmd
+}
+
+// runDaemon hosts mgr behind the control socket until it is interrupted or asked to shut down.
+func runDaemon(mgr LifeCycleManager, socketPath, httpAddr, grpcAddr string, startAll bool) error {
+	manager = mgr
+
+	srv := control.NewServer(mgr, socketPath)
+	if err := srv.Listen(); err != nil {
+		return err
+	}
+	go func() {
+		if err := srv.Serve(); err != nil {
+			l.Error(fmt.Sprintf("Control socket error: %s", err), map[string]interface{}{})
+		}
+	}()
+
+	if httpAddr != "" && HTTPMux != nil {
+		go func() {
+			if err := http.ListenAndServe(httpAddr, HTTPMux); err != nil {
+				l.Error("Error starting web server: "+err.Error(), nil)
+			}
+		}()
+	}
+
+	var grpcServer *grpcsrv.Server
+	if grpcAddr != "" {
+		grpcServer = grpcsrv.NewServer(mgr, grpcsrv.Config{Addr: grpcAddr, Reflection: true})
+		if err := grpcServer.ListenAndServe(); err != nil {
+			l.Error(fmt.Sprintf("Error starting gRPC server: %s", err), map[string]interface{}{})
+			grpcServer = nil
+		}
+	}
+
+	if startAll {
+		if err := mgr.StartAll(); err != nil {
+			l.Error(fmt.Sprintf("Fail to start processes: %s", err), map[string]interface{}{})
+		}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case <-sigCh:
+	case <-srv.Done():
+	}
+	_ = srv.Close()
+	if grpcServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = grpcServer.Shutdown(ctx)
+		cancel()
+	}
+
+	stopErr := mgr.StopAll()
+	if j := mgr.Journal(); j != nil {
+		_ = j.Close()
+	}
+	return stopErr
+}
+
+// callDaemon sends a single request to the daemon listening on socketPath.
+func callDaemon(socketPath string, req control.Request) (*control.Response, error) {
+	client, err := control.Dial(socketPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }()
+
+	return client.Call(req)
+}
+
+// ensureDaemon starts a detached daemon on socketPath when none answers there.
+func ensureDaemon(socketPath string) error {
+	if control.Ping(socketPath) {
+		return nil
+	}
+	appFullPath, appFullPathErr := os.Executable()
+	if appFullPathErr != nil {
+		return appFullPathErr
+	}
+	daemonCmd := exec.Command(appFullPath, "daemon", "--socket", socketPath)
+	daemonCmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := daemonCmd.Start(); err != nil {
+		return err
+	}
+	if err := daemonCmd.Process.Release(); err != nil {
+		return err
+	}
+	for i := 0; i < 50; i++ {
+		if control.Ping(socketPath) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("daemon did not come up on %s", socketPath)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
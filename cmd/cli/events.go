@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"github.com/rafa-mori/golife/internal/control"
 	l "github.com/rafa-mori/logz"
 	"github.com/spf13/cobra"
 )
@@ -16,6 +17,7 @@ func EventsCmdList() []*cobra.Command {
 }
 
 func triggerCmd() *cobra.Command {
+	var socketPath string
 	var stage, event, data string
 
 	var cmdTrigger = &cobra.Command{
@@ -29,7 +31,10 @@ func triggerCmd() *cobra.Command {
 			stage = args[0]
 			event = args[1]
 			data = args[2]
-			manager.Trigger(stage, event, data)
+			if _, triggerErr := callDaemon(socketPath, control.Request{Op: control.OpTrigger, Stage: stage, Event: event, Data: data}); triggerErr != nil {
+				l.Error(fmt.Sprintf("Error triggering event: %s", triggerErr.Error()), map[string]interface{}{})
+				return
+			}
 			l.Info(fmt.Sprintf("Event %s triggered in stage %s with data: %s", event, stage, data), map[string]interface{}{})
 		},
 	}
@@ -37,11 +42,13 @@ func triggerCmd() *cobra.Command {
 	cmdTrigger.Flags().StringVarP(&stage, "stage", "s", "", "The stage to trigger the event in")
 	cmdTrigger.Flags().StringVarP(&event, "event", "e", "", "The event to trigger")
 	cmdTrigger.Flags().StringVarP(&data, "data", "d", "", "The data to pass to the event")
+	cmdTrigger.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
 
 	return cmdTrigger
 }
 
 func registerEventCmd() *cobra.Command {
+	var socketPath string
 	var stage, event string
 
 	var cmdRegisterEvent = &cobra.Command{
@@ -54,9 +61,7 @@ func registerEventCmd() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			stage = args[0]
 			event = args[1]
-			regEvErr := manager.RegisterEvent(stage, event, func(data interface{}) {
-				manager.Trigger(stage, event, data)
-			})
+			_, regEvErr := callDaemon(socketPath, control.Request{Op: control.OpRegEvent, Stage: stage, Event: event})
 			if regEvErr != nil {
 				l.Error(fmt.Sprintf("Error registering event: %s", regEvErr.Error()), map[string]interface{}{})
 				return
@@ -67,11 +72,13 @@ func registerEventCmd() *cobra.Command {
 
 	cmdRegisterEvent.Flags().StringVarP(&stage, "stage", "s", "", "The stage to register the event in")
 	cmdRegisterEvent.Flags().StringVarP(&event, "event", "e", "", "The event to register")
+	cmdRegisterEvent.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
 
 	return cmdRegisterEvent
 }
 
 func removeEventCmd() *cobra.Command {
+	var socketPath string
 	var stage, event string
 
 	var cmdRemoveEvent = &cobra.Command{
@@ -84,7 +91,7 @@ func removeEventCmd() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			stage = args[0]
 			event = args[1]
-			err := manager.RemoveEvent(stage, event)
+			_, err := callDaemon(socketPath, control.Request{Op: control.OpRemoveEvent, Stage: stage, Event: event})
 			if err != nil {
 				l.Error(fmt.Sprintf("Error removing event: %s", err.Error()), map[string]interface{}{})
 				return
@@ -95,11 +102,13 @@ func removeEventCmd() *cobra.Command {
 
 	cmdRemoveEvent.Flags().StringVarP(&stage, "stage", "s", "", "The stage to remove the event from")
 	cmdRemoveEvent.Flags().StringVarP(&event, "event", "e", "", "The event to remove")
+	cmdRemoveEvent.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
 
 	return cmdRemoveEvent
 }
 
 func stopEventsCmd() *cobra.Command {
+	var socketPath string
 	var cmdStopEvents = &cobra.Command{
 		Use:  "stopEvents",
 		Args: cobra.NoArgs,
@@ -108,7 +117,7 @@ func stopEventsCmd() *cobra.Command {
 			"Stop all events",
 		}, false),
 		Run: func(cmd *cobra.Command, args []string) {
-			err := manager.StopEvents()
+			_, err := callDaemon(socketPath, control.Request{Op: control.OpStopEvents})
 			if err != nil {
 				l.Error(fmt.Sprintf("Error stopping events: %s", err.Error()), map[string]interface{}{})
 				return
@@ -117,5 +126,7 @@ func stopEventsCmd() *cobra.Command {
 		},
 	}
 
+	cmdStopEvents.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
+
 	return cmdStopEvents
 }
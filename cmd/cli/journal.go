@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	. "github.com/rafa-mori/golife/internal"
+	l "github.com/rafa-mori/logz"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+func journalCommand() *cobra.Command {
+	var journalCmd = &cobra.Command{
+		Use: "journal",
+		Annotations: GetDescriptions([]string{
+			"Read the event journal",
+			"Read the durable journal of lifecycle events written by a manager whose manifest declares a journal",
+		}, false),
+	}
+
+	journalCmd.AddCommand(journalTailCommand(), journalInspectCommand())
+
+	return journalCmd
+}
+
+func journalTailCommand() *cobra.Command {
+	var dir, output string
+	var lines int
+	var from int64
+	var follow bool
+
+	var tailCmd = &cobra.Command{
+		Use: "tail",
+		Annotations: GetDescriptions([]string{
+			"Show the last journaled events",
+			"Show the last events of the journal, or the events from an offset on, optionally following new ones",
+		}, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			if dir == "" {
+				l.Error("No journal directory provided (--dir or GOLIFE_JOURNAL_DIR)", map[string]interface{}{})
+				return
+			}
+			var records []JournalRecord
+			start := uint64(0)
+			if from >= 0 {
+				start = uint64(from)
+			}
+			readErr := ReadJournal(dir, start, func(rec JournalRecord) error {
+				records = append(records, rec)
+				if from < 0 && lines > 0 && len(records) > lines {
+					records = records[1:]
+				}
+				return nil
+			})
+			if readErr != nil {
+				l.Error(fmt.Sprintf("Fail to read journal: %s", readErr), map[string]interface{}{})
+				return
+			}
+			next := start
+			for _, rec := range records {
+				if printErr := printJournalRecord(os.Stdout, rec, output); printErr != nil {
+					l.Error(fmt.Sprintf("Fail to print journal: %s", printErr), map[string]interface{}{})
+					return
+				}
+				next = rec.Offset + 1
+			}
+			for follow {
+				time.Sleep(500 * time.Millisecond)
+				readErr = ReadJournal(dir, next, func(rec JournalRecord) error {
+					next = rec.Offset + 1
+					return printJournalRecord(os.Stdout, rec, output)
+				})
+				if readErr != nil {
+					l.Error(fmt.Sprintf("Fail to read journal: %s", readErr), map[string]interface{}{})
+					return
+				}
+			}
+		},
+	}
+
+	tailCmd.Flags().StringVarP(&dir, "dir", "d", os.Getenv("GOLIFE_JOURNAL_DIR"), "Directory of the journal")
+	tailCmd.Flags().IntVarP(&lines, "lines", "l", 20, "Number of events to show (0 for every event)")
+	tailCmd.Flags().Int64Var(&from, "from", -1, "Show every event from this offset on instead of the last ones")
+	tailCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new events")
+	tailCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format: text or json")
+
+	return tailCmd
+}
+
+func journalInspectCommand() *cobra.Command {
+	var dir, output string
+
+	var inspectCmd = &cobra.Command{
+		Use: "inspect",
+		Annotations: GetDescriptions([]string{
+			"Describe the journal segments",
+			"Describe the segments of the journal: offsets, record counts, sizes, time span and checksum errors",
+		}, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			if dir == "" {
+				l.Error("No journal directory provided (--dir or GOLIFE_JOURNAL_DIR)", map[string]interface{}{})
+				return
+			}
+			segments, inspectErr := InspectJournal(dir)
+			if inspectErr != nil {
+				l.Error(fmt.Sprintf("Fail to inspect journal: %s", inspectErr), map[string]interface{}{})
+				return
+			}
+			if printErr := printJournalSegments(os.Stdout, segments, output); printErr != nil {
+				l.Error(fmt.Sprintf("Fail to print journal: %s", printErr), map[string]interface{}{})
+			}
+		},
+	}
+
+	inspectCmd.Flags().StringVarP(&dir, "dir", "d", os.Getenv("GOLIFE_JOURNAL_DIR"), "Directory of the journal")
+	inspectCmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table or json")
+
+	return inspectCmd
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/rafa-mori/golife/internal/control"
+	l "github.com/rafa-mori/logz"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+func logsCommand() *cobra.Command {
+	var socketPath string
+	var processName, stream string
+	var lines int
+	var follow bool
+
+	var logsCmd = &cobra.Command{
+		Use: "logs",
+		Annotations: GetDescriptions([]string{
+			"Show the output of a managed process",
+			"Show the last captured stdout and stderr lines of a managed process, optionally following new output",
+		}, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			if processName == "" && len(args) > 0 {
+				processName = args[0]
+			}
+			if processName == "" {
+				l.Error("No process name provided", map[string]interface{}{})
+				return
+			}
+			req := control.Request{Op: control.OpLogs, Name: processName, Stream: stream, Lines: lines}
+			for {
+				resp, logsErr := callDaemon(socketPath, req)
+				if logsErr != nil {
+					l.Error(fmt.Sprintf("Fail to get logs: %s", logsErr), map[string]interface{}{})
+					return
+				}
+				var result control.LogsResult
+				if decodeErr := resp.Decode(&result); decodeErr != nil {
+					l.Error(fmt.Sprintf("Fail to decode logs: %s", decodeErr), map[string]interface{}{})
+					return
+				}
+				for _, line := range result.Lines {
+					if line.Stream == "stderr" {
+						_, _ = fmt.Fprintln(os.Stderr, line.Text)
+					} else {
+						_, _ = fmt.Fprintln(os.Stdout, line.Text)
+					}
+				}
+				if !follow {
+					return
+				}
+				// Every line buffered after the previous call is new, however many there are.
+				req.Since, req.Lines = result.Next, 0
+				time.Sleep(500 * time.Millisecond)
+			}
+		},
+	}
+
+	logsCmd.Flags().StringVarP(&processName, "name", "n", "", "Name of the process")
+	logsCmd.Flags().StringVarP(&stream, "stream", "s", "", "Only show this stream (stdout or stderr)")
+	logsCmd.Flags().IntVarP(&lines, "lines", "l", 100, "Number of lines to show (0 for every buffered line)")
+	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new output")
+	logsCmd.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
+
+	return logsCmd
+}
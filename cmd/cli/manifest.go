@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	. "github.com/rafa-mori/golife/internal"
+	"github.com/rafa-mori/golife/internal/control"
+	l "github.com/rafa-mori/logz"
+	"github.com/spf13/cobra"
+)
+
+func upCommand() *cobra.Command {
+	var manifestPath, socketPath string
+
+	var upCmd = &cobra.Command{
+		Use: "up",
+		Annotations: GetDescriptions([]string{
+			"Start everything described in a manifest",
+			"Load a YAML, TOML or JSON manifest describing processes, stages and events, start the processes and keep them supervised until interrupted",
+		}, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			mgr, mgrErr := createManagerFromManifest(manifestPath)
+			if mgrErr != nil {
+				l.Error(fmt.Sprintf("Fail to load manifest: %s", mgrErr), map[string]interface{}{})
+				return
+			}
+			if runErr := runDaemon(mgr, socketPath, "", "", true); runErr != nil {
+				l.Error(fmt.Sprintf("Fail to run manifest: %s", runErr), map[string]interface{}{})
+			}
+		},
+	}
+
+	upCmd.Flags().StringVarP(&manifestPath, "file", "f", "golife.yaml", "Path to the manifest file (.yaml, .yml, .toml or .json)")
+	upCmd.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
+
+	return upCmd
+}
+
+func createManagerFromManifest(manifestPath string) (LifeCycleManager, error) {
+	if manifestPath == "" {
+		return nil, fmt.Errorf("no manifest provided")
+	}
+	manifest, manifestErr := LoadManifest(manifestPath)
+	if manifestErr != nil {
+		return nil, manifestErr
+	}
+	return NewLifecycleFromManifest(manifest)
+}
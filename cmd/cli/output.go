@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/rafa-mori/golife/internal"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// printSnapshot writes the process statuses as a table, JSON or YAML.
+func printSnapshot(w io.Writer, snapshot []ProcessStatus, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snapshot)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(snapshot); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "table", "":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "NAME\tPID\tSTATE\tUPTIME\tRESTARTS\tEXIT\tHEALTH\tREADY\tCPU\tRSS")
+		for _, s := range snapshot {
+			pid := "-"
+			if s.Pid > 0 {
+				pid = strconv.Itoa(s.Pid)
+			}
+			exit := strconv.Itoa(s.ExitCode)
+			if s.ExitSignal != "" {
+				exit = s.ExitSignal
+			}
+			if s.ExitReason == ExitOOMKilled {
+				exit = string(s.ExitReason)
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%t\t%.1f%%\t%s\n",
+				s.Name, pid, s.State, time.Duration(s.Uptime).Round(time.Second), s.Restarts, exit, s.Health.Status, s.Health.Ready, s.Resources.CPUPercent, formatBytes(s.Resources.RSSBytes))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q (table, json or yaml)", format)
+	}
+}
+
+// printJournalRecord writes a journal record as a line of text or of JSON.
+func printJournalRecord(w io.Writer, rec JournalRecord, format string) error {
+	switch format {
+	case "json":
+		return json.NewEncoder(w).Encode(rec)
+	case "text", "":
+		ev := rec.Event
+		subject := "-"
+		switch {
+		case ev.Process != "":
+			subject = ev.Process
+		case ev.Stage != "" && ev.Event != "":
+			subject = ev.Stage + "/" + ev.Event
+		case ev.Stage != "":
+			subject = ev.Stage
+		case ev.Event != "":
+			subject = ev.Event
+		}
+		data := ""
+		if ev.Data != nil {
+			raw, err := json.Marshal(ev.Data)
+			if err != nil {
+				return err
+			}
+			data = " " + string(raw)
+		}
+		_, err := fmt.Fprintf(w, "%d\t%s\t%s\t%s%s\n", rec.Offset, rec.Time.Format(time.RFC3339Nano), ev.Type, subject, data)
+		return err
+	default:
+		return fmt.Errorf("unknown output format %q (text or json)", format)
+	}
+}
+
+// printJournalSegments writes the segments of a journal as a table or JSON.
+func printJournalSegments(w io.Writer, segments []JournalSegment, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(segments)
+	case "table", "":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "SEGMENT\tOFFSETS\tRECORDS\tSIZE\tFIRST\tLAST\tSTATUS")
+		records, size := 0, int64(0)
+		for _, s := range segments {
+			offsets, first, last := "-", "-", "-"
+			if s.Records > 0 {
+				offsets = fmt.Sprintf("%d-%d", s.BaseOffset, s.BaseOffset+uint64(s.Records)-1)
+				first, last = s.First.Format(time.RFC3339), s.Last.Format(time.RFC3339)
+			}
+			status := "ok"
+			if s.Corrupt != "" {
+				status = s.Corrupt
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", filepath.Base(s.Path), offsets, s.Records, formatBytes(uint64(s.Size)), first, last, status)
+			records += s.Records
+			size += s.Size
+		}
+		_, _ = fmt.Fprintf(tw, "TOTAL\t\t%d\t%s\t\t\t%d segments\n", records, formatBytes(uint64(size)), len(segments))
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q (table or json)", format)
+	}
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
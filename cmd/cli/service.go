@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	. "github.com/rafa-mori/golife/internal"
+	"github.com/rafa-mori/golife/internal/control"
 	l "github.com/rafa-mori/logz"
 	"github.com/spf13/cobra"
 	"os"
@@ -19,7 +20,14 @@ func ServiceCmdList() []*cobra.Command {
 		stopCommand(),
 		statusCommand(),
 		restartCommand(),
+		pauseCommand(),
+		resumeCommand(),
+		reloadCommand(),
 		serviceCommand(),
+		upCommand(),
+		daemonCommand(),
+		logsCommand(),
+		journalCommand(),
 	}
 }
 
@@ -59,6 +67,7 @@ func lifeCycleManagerCmd() *cobra.Command {
 	return lCMCmd
 }
 func startCommand() *cobra.Command {
+	var socketPath string
 	var processName, processCmd string
 	var processArgs []string
 	var processWait, restart bool
@@ -82,31 +91,17 @@ func startCommand() *cobra.Command {
 					manager = mgr
 					return
 				}
-			} else {
-				appFullPath, appFullPathErr := exec.LookPath("golife")
-				if appFullPathErr != nil {
-					l.Error(fmt.Sprintf("Fail to find golife binary: %s", appFullPathErr), map[string]interface{}{})
-					return
-				}
-				argsStr, waitFlag, restartFlag, stagesStr, triggersStr := getFlagsAsSliceStr(processWait, restart, processArgs, stages, triggers)
-				mgrCmdStr := fmt.Sprintf("%s lfm -n %s -c %s %s %s %s -s %s -t %s", appFullPath, processName, processCmd, argsStr, waitFlag, restartFlag, stagesStr, triggersStr)
-				mgrCmd := exec.Command("/bin/sh", "-c", mgrCmdStr)
-				mgrCmd.Stdout = os.Stdout
-				mgrCmd.Stderr = os.Stderr
-				mgrCmd.Stdin = os.Stdin
-				mgrCmdErr := mgrCmd.Start()
-				if mgrCmdErr != nil {
-					l.Error(fmt.Sprintf("Fail to start manager command: %s", mgrCmdErr), map[string]interface{}{})
-					return
-				} else {
-					releaseErr := mgrCmd.Process.Release()
-					if releaseErr != nil {
-						l.Error(fmt.Sprintf("Fail to release process: %s", releaseErr), map[string]interface{}{})
-						return
-					}
-					return
-				}
 			}
+			if daemonErr := ensureDaemon(socketPath); daemonErr != nil {
+				l.Error(fmt.Sprintf("Fail to reach golife daemon: %s", daemonErr), map[string]interface{}{})
+				return
+			}
+			_, startErr := callDaemon(socketPath, control.Request{Op: control.OpStart, Name: processName, Command: processCmd, Args: processArgs, Restart: restart})
+			if startErr != nil {
+				l.Error(fmt.Sprintf("Fail to start process: %s", startErr), map[string]interface{}{})
+				return
+			}
+			l.Info(fmt.Sprintf("Process %s started", processName), map[string]interface{}{})
 		},
 	}
 
@@ -117,10 +112,12 @@ func startCommand() *cobra.Command {
 	startCmd.Flags().BoolVarP(&restart, "restart", "r", false, "Restart the process if it is already running")
 	startCmd.Flags().StringSliceVarP(&stages, "stages", "s", []string{}, "Stages to listen for and trigger")
 	startCmd.Flags().StringSliceVarP(&triggers, "triggers", "t", []string{}, "Triggers to listen for and trigger")
+	startCmd.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
 
 	return startCmd
 }
 func stopCommand() *cobra.Command {
+	var socketPath string
 	var processName string
 	var stopCmd = &cobra.Command{
 		Use: "stop",
@@ -129,40 +126,64 @@ func stopCommand() *cobra.Command {
 			"Stop a process with a life cycle manager",
 		}, false),
 		Run: func(cmd *cobra.Command, args []string) {
-			if manager == nil {
-				l.Error("no manager found", map[string]interface{}{})
-			} else {
-				stopErr := manager.Stop()
-				if stopErr != nil {
-					l.Error(fmt.Sprintf("Fail to stop process: %s", stopErr), map[string]interface{}{})
+			resp, stopErr := callDaemon(socketPath, control.Request{Op: control.OpStop, Name: processName})
+			if resp != nil {
+				var report StopReport
+				if decodeErr := resp.Decode(&report); decodeErr == nil {
+					for _, res := range report {
+						if res.Err != nil {
+							l.Error(fmt.Sprintf("%s: %s (%s)", res.Name, res.Outcome, res.Err), map[string]interface{}{})
+						} else {
+							l.Info(fmt.Sprintf("%s: %s", res.Name, res.Outcome), map[string]interface{}{})
+						}
+					}
 				}
 			}
+			if stopErr != nil {
+				l.Error(fmt.Sprintf("Fail to stop process: %s", stopErr), map[string]interface{}{})
+			}
 		},
 	}
 
-	stopCmd.Flags().StringVarP(&processName, "name", "n", "", "Name of the process")
+	stopCmd.Flags().StringVarP(&processName, "name", "n", "", "Name of the process (all processes when empty)")
+	stopCmd.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
 
 	return stopCmd
 }
 func statusCommand() *cobra.Command {
+	var socketPath string
+	var output string
 	var statusCmd = &cobra.Command{
 		Use: "status",
 		Annotations: GetDescriptions([]string{
 			"Get the status of a process with a life cycle manager",
-			"Get the status of a process with a life cycle manager. Shows PID, status, and other information.",
+			"Get the status of a process with a life cycle manager. Shows PID, state, uptime, restarts, exit status, health and resource usage.",
 		}, false),
 		Run: func(cmd *cobra.Command, args []string) {
-			if manager == nil {
-				l.Error("no manager found", map[string]interface{}{})
-			} else {
-				l.Info(manager.Status(), map[string]interface{}{})
+			resp, statusErr := callDaemon(socketPath, control.Request{Op: control.OpSnapshot})
+			if statusErr != nil {
+				l.Error(fmt.Sprintf("Fail to get status: %s", statusErr), map[string]interface{}{})
+				return
+			}
+			var snapshot []ProcessStatus
+			if decodeErr := resp.Decode(&snapshot); decodeErr != nil {
+				l.Error(fmt.Sprintf("Fail to decode status: %s", decodeErr), map[string]interface{}{})
+				return
+			}
+			if printErr := printSnapshot(os.Stdout, snapshot, output); printErr != nil {
+				l.Error(fmt.Sprintf("Fail to print status: %s", printErr), map[string]interface{}{})
 			}
 		},
 	}
 
+	statusCmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table, json or yaml")
+	statusCmd.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
+
 	return statusCmd
 }
 func restartCommand() *cobra.Command {
+	var socketPath string
+	var processName string
 	var restartCmd = &cobra.Command{
 		Use: "restart",
 		Annotations: GetDescriptions([]string{
@@ -170,20 +191,109 @@ func restartCommand() *cobra.Command {
 			"Restart a process with a life cycle manager",
 		}, false),
 		Run: func(cmd *cobra.Command, args []string) {
-			if manager == nil {
-				l.Error("no manager found", map[string]interface{}{})
+			if _, err := callDaemon(socketPath, control.Request{Op: control.OpRestart, Name: processName}); err != nil {
+				l.Error(fmt.Sprintf("Fail to restart process: %s", err), map[string]interface{}{})
 			} else {
-				if err := manager.Restart(); err != nil {
-					l.Error(fmt.Sprintf("Fail to restart process: %s", err), map[string]interface{}{})
-				} else {
-					l.Info("Process restarted successfully", map[string]interface{}{})
-				}
+				l.Info("Process restarted successfully", map[string]interface{}{})
 			}
 		},
 	}
 
+	restartCmd.Flags().StringVarP(&processName, "name", "n", "", "Name of the process (all processes when empty)")
+	restartCmd.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
+
 	return restartCmd
 }
+func pauseCommand() *cobra.Command {
+	var socketPath string
+	var processName string
+	var pauseCmd = &cobra.Command{
+		Use: "pause",
+		Annotations: GetDescriptions([]string{
+			"Pause a process with a life cycle manager",
+			"Freeze the whole process tree until it is resumed",
+		}, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			if processName == "" && len(args) > 0 {
+				processName = args[0]
+			}
+			if processName == "" {
+				l.Error("No process name provided", map[string]interface{}{})
+				return
+			}
+			if _, err := callDaemon(socketPath, control.Request{Op: control.OpPause, Name: processName}); err != nil {
+				l.Error(fmt.Sprintf("Fail to pause process: %s", err), map[string]interface{}{})
+			} else {
+				l.Info("Process paused successfully", map[string]interface{}{})
+			}
+		},
+	}
+
+	pauseCmd.Flags().StringVarP(&processName, "name", "n", "", "Name of the process")
+	pauseCmd.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
+
+	return pauseCmd
+}
+func resumeCommand() *cobra.Command {
+	var socketPath string
+	var processName string
+	var resumeCmd = &cobra.Command{
+		Use: "resume",
+		Annotations: GetDescriptions([]string{
+			"Resume a paused process with a life cycle manager",
+			"Let a paused process tree run again",
+		}, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			if processName == "" && len(args) > 0 {
+				processName = args[0]
+			}
+			if processName == "" {
+				l.Error("No process name provided", map[string]interface{}{})
+				return
+			}
+			if _, err := callDaemon(socketPath, control.Request{Op: control.OpResume, Name: processName}); err != nil {
+				l.Error(fmt.Sprintf("Fail to resume process: %s", err), map[string]interface{}{})
+			} else {
+				l.Info("Process resumed successfully", map[string]interface{}{})
+			}
+		},
+	}
+
+	resumeCmd.Flags().StringVarP(&processName, "name", "n", "", "Name of the process")
+	resumeCmd.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
+
+	return resumeCmd
+}
+func reloadCommand() *cobra.Command {
+	var socketPath string
+	var processName string
+	var reloadCmd = &cobra.Command{
+		Use: "reload",
+		Annotations: GetDescriptions([]string{
+			"Reload a process with a life cycle manager",
+			"Apply the reload strategy of a process (signal, command or callback) without restarting it",
+		}, false),
+		Run: func(cmd *cobra.Command, args []string) {
+			if processName == "" && len(args) > 0 {
+				processName = args[0]
+			}
+			if processName == "" {
+				l.Error("No process name provided", map[string]interface{}{})
+				return
+			}
+			if _, err := callDaemon(socketPath, control.Request{Op: control.OpReload, Name: processName}); err != nil {
+				l.Error(fmt.Sprintf("Fail to reload process: %s", err), map[string]interface{}{})
+			} else {
+				l.Info("Process reloaded successfully", map[string]interface{}{})
+			}
+		},
+	}
+
+	reloadCmd.Flags().StringVarP(&processName, "name", "n", "", "Name of the process")
+	reloadCmd.Flags().StringVarP(&socketPath, "socket", "S", control.DefaultSocketPath(), "Path of the control socket")
+
+	return reloadCmd
+}
 func serviceCommand() *cobra.Command {
 	var processName, processCmd string
 	var processArgs []string
@@ -238,7 +348,7 @@ func createManager(processName, processCmd string, stages []string, processEvent
 		for _, trigger := range triggers {
 			for _, stage := range stages {
 				processEvents[trigger] = func(data interface{}) {
-					manager.Trigger(stage, trigger, data)
+					_ = manager.Trigger(stage, trigger, data)
 				}
 			}
 		}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"github.com/rafa-mori/golife/cmd/cli"
+	"github.com/rafa-mori/golife/services/server"
 	l "github.com/rafa-mori/logz"
 	"net/http"
 	"os"
@@ -8,14 +10,11 @@ import (
 
 func main() {
 	mux := http.NewServeMux()
-	//log.RegisterSSEEndpoint(mux)
+	server.RegisterSSEEndpoint(mux, cli.CurrentManager)
+	server.RegisterMetricsEndpoint(mux, cli.CurrentManager)
 
-	go func() {
-		if err := http.ListenAndServe(":8080", mux); err != nil {
-			l.Error("Error starting web server: "+err.Error(), nil)
-			os.Exit(1)
-		}
-	}()
+	// The mux is served by `golife daemon`, the only long-lived process; client commands must not bind the port.
+	cli.HTTPMux = mux
 
 	if rootErr := RegX().Execute(); rootErr != nil {
 		l.Error("Error executing command: "+rootErr.Error(), nil)
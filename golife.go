@@ -1,8 +1,10 @@
 package golife
 
 import (
+	"context"
 	i "github.com/rafa-mori/golife/internal"
 	"os"
+	"time"
 )
 
 type LifeCycleManager = i.LifeCycleManager
@@ -57,32 +59,252 @@ func NewStage(name, desc, stageType string) Stage {
 	return i.NewStage(name, desc, stageType)
 }
 
+type TransitionGuard = i.TransitionGuard
+type Message = i.Message
+type Mailbox = i.Mailbox
+type OverflowPolicy = i.OverflowPolicy
+
+const (
+	OverflowBlock      = i.OverflowBlock
+	OverflowDropOldest = i.OverflowDropOldest
+	OverflowError      = i.OverflowError
+)
+
+var (
+	ErrMailboxFull   = i.ErrMailboxFull
+	ErrMailboxClosed = i.ErrMailboxClosed
+)
+
+func NewMailbox(capacity int, policy OverflowPolicy) *Mailbox {
+	return i.NewMailbox(capacity, policy)
+}
+
+type StageTransition = i.StageTransition
+type EventCall = i.EventCall
+type EventHandler = i.EventHandler
+type EventMiddleware = i.EventMiddleware
+type EventResult = i.EventResult
+type EventSubscription = i.EventSubscription
+
+// ErrHandlerTimeout is returned by handlers cut short by WithTimeout.
+var ErrHandlerTimeout = i.ErrHandlerTimeout
+
+func ChainEvent(h EventHandler, mw ...EventMiddleware) EventHandler {
+	return i.ChainEvent(h, mw...)
+}
+
+// MatchEvent reports whether the event name matches pattern ("process.*.exited", "order.#").
+func MatchEvent(pattern, name string) bool {
+	return i.MatchEvent(pattern, name)
+}
+
+// SetEventResult sets the value returned by the EventResult of the handler running with ctx.
+func SetEventResult(ctx context.Context, value interface{}) bool {
+	return i.SetEventResult(ctx, value)
+}
+func WithRecover() EventMiddleware {
+	return i.WithRecover()
+}
+func WithTimeout(d time.Duration) EventMiddleware {
+	return i.WithTimeout(d)
+}
+func WithRetry(attempts int, initial, max time.Duration) EventMiddleware {
+	return i.WithRetry(attempts, initial, max)
+}
+func WithLogging() EventMiddleware {
+	return i.WithLogging()
+}
+func WithMetrics() EventMiddleware {
+	return i.WithMetrics()
+}
+
 type WorkerPool = i.IWorkerPool
+type WorkerPoolConfig = i.WorkerPoolConfig
+type WorkerPoolStats = i.WorkerPoolStats
+
+var (
+	ErrPoolClosed = i.ErrPoolClosed
+	ErrPoolFull   = i.ErrPoolFull
+)
 
 func NewWorkerPool(size int) WorkerPool {
 	return i.NewWorkerPool(size)
 }
 
+func NewElasticWorkerPool(config WorkerPoolConfig) WorkerPool {
+	return i.NewElasticWorkerPool(config)
+}
+
 type ManagedProcess = i.IManagedProcess
 
 func NewManagedProcess(name string, command string, args []string, waitFor bool, customFn func() error) ManagedProcess {
 	return i.NewManagedProcess(name, command, args, waitFor, customFn)
 }
 
+type ManagedGoroutine = i.IManagedGoroutine
+type GoroutineFunc = i.GoroutineFunc
+type PanicError = i.PanicError
+
+func NewManagedGoroutine(name string, fn GoroutineFunc) ManagedGoroutine {
+	return i.NewManagedGoroutine(name, fn)
+}
+
+// Checkpoint blocks while the managed goroutine running with ctx is paused and returns ctx.Err().
+func Checkpoint(ctx context.Context) error {
+	return i.Checkpoint(ctx)
+}
+
+type ManagedUnit = i.IManagedUnit
+type UnitKind = i.UnitKind
+type Pausable = i.Pausable
+type Reloadable = i.Reloadable
+type Signalable = i.Signalable
+
+const (
+	KindProcess   = i.KindProcess
+	KindGoroutine = i.KindGoroutine
+	KindFunc      = i.KindFunc
+)
+
+func NewProcessUnit(process ManagedProcess) ManagedUnit {
+	return i.NewProcessUnit(process)
+}
+func NewGoroutineUnit(g ManagedGoroutine) ManagedUnit {
+	return i.NewGoroutineUnit(g)
+}
+func NewFuncUnit(name string, fn func() error) ManagedUnit {
+	return i.NewFuncUnit(name, fn)
+}
+
+type RestartPolicy = i.RestartPolicy
+type SupervisorPolicy = i.SupervisorPolicy
+type ProcessState = i.ProcessState
+
+const (
+	RestartAlways    = i.RestartAlways
+	RestartOnFailure = i.RestartOnFailure
+	RestartNever     = i.RestartNever
+)
+
+func DefaultSupervisorPolicy(restart RestartPolicy) SupervisorPolicy {
+	return i.DefaultSupervisorPolicy(restart)
+}
+
+type ReloadPolicy = i.ReloadPolicy
+type ReloadStrategy = i.ReloadStrategy
+type ReloadEvent = i.ReloadEvent
+
+const (
+	ReloadSignal   = i.ReloadSignal
+	ReloadExec     = i.ReloadExec
+	ReloadCallback = i.ReloadCallback
+)
+
+type ResourceLimits = i.ResourceLimits
+type ExitReason = i.ExitReason
+
+const (
+	ExitCompleted = i.ExitCompleted
+	ExitFailed    = i.ExitFailed
+	ExitSignaled  = i.ExitSignaled
+	ExitOOMKilled = i.ExitOOMKilled
+	ExitStopped   = i.ExitStopped
+)
+
+type StopPolicy = i.StopPolicy
+type StopReport = i.StopReport
+
+func DefaultStopPolicy() StopPolicy {
+	return i.DefaultStopPolicy()
+}
+
+type Probe = i.Probe
+type HealthState = i.HealthState
+type HealthChange = i.HealthChange
+type LogConfig = i.LogConfig
+type ProcessStatus = i.ProcessStatus
+type ResourceUsage = i.ResourceUsage
+type ResourceSample = i.ResourceSample
+
+type MetricsRegistry = i.MetricsRegistry
+type MetricsWriter = i.MetricsWriter
+
+// DefaultMetrics is the registry served by the /metrics endpoint of the daemon.
+var DefaultMetrics = i.DefaultMetrics
+
+func NewMetricsRegistry() *MetricsRegistry {
+	return i.NewMetricsRegistry()
+}
+
+type Journal = i.Journal
+type JournalConfig = i.JournalConfig
+type JournalRecord = i.JournalRecord
+type JournalSegment = i.JournalSegment
+type FsyncPolicy = i.FsyncPolicy
+
+const (
+	FsyncAlways   = i.FsyncAlways
+	FsyncInterval = i.FsyncInterval
+	FsyncNever    = i.FsyncNever
+)
+
+var (
+	ErrJournalClosed  = i.ErrJournalClosed
+	ErrJournalCorrupt = i.ErrJournalCorrupt
+)
+
+func OpenJournal(config JournalConfig) (*Journal, error) {
+	return i.OpenJournal(config)
+}
+
+// IsReplay reports whether a handler runs because the journal is replayed.
+func IsReplay(ctx context.Context) bool {
+	return i.IsReplay(ctx)
+}
+
+type EventBus = i.EventBus
+type EventType = i.EventType
+type LifecycleEvent = i.LifecycleEvent
+type Subscription = i.Subscription
+type ProcessStateChange = i.ProcessStateChange
+type LogLine = i.LogLine
+
+const (
+	ProbeExec = i.ProbeExec
+	ProbeTCP  = i.ProbeTCP
+	ProbeHTTP = i.ProbeHTTP
+)
+
 type Event = i.IManagedProcessEvents
 
 func NewEvent(eventFns map[string]func(interface{}), triggerCh chan interface{}) Event {
 	return i.NewManagedProcessEvents(eventFns, triggerCh)
 }
 
-func Trigger(lc LifeCycleManager, stage string, event string, data interface{}) {
-	lc.Trigger(stage, event, data)
+func Trigger(lc LifeCycleManager, stage string, event string, data interface{}) error {
+	return lc.Trigger(stage, event, data)
+}
+
+func TriggerAsync(lc LifeCycleManager, stage string, event string, data interface{}) *EventResult {
+	return lc.TriggerAsync(stage, event, data)
+}
+
+func TriggerAll(ctx context.Context, lc LifeCycleManager, event string, data interface{}, stages ...string) ([]*EventResult, error) {
+	return lc.TriggerAll(ctx, event, data, stages...)
+}
+
+func Subscribe(lc LifeCycleManager, pattern string, handler EventHandler) (*EventSubscription, error) {
+	return lc.Subscribe(pattern, handler)
 }
 
 func RegisterEvent(lc LifeCycleManager, stage string, event string, fn func(interface{})) error {
 	return lc.RegisterEvent(stage, event, fn)
 }
 
+func RegisterHandler(lc LifeCycleManager, stage string, event string, handler EventHandler) error {
+	return lc.RegisterHandler(event, stage, handler)
+}
+
 func RegisterStage(lc LifeCycleManager, stage Stage) error {
 	return lc.RegisterStage(stage)
 }
@@ -90,3 +312,13 @@ func RegisterStage(lc LifeCycleManager, stage Stage) error {
 func RegisterProcess(lc LifeCycleManager, process ManagedProcess) error {
 	return lc.RegisterProcess(process.GetName(), process.GetCommand(), process.GetArgs(), process.WillRestart(), process.GetCustomFunc())
 }
+
+type Manifest = i.Manifest
+
+func LoadManifest(path string) (*Manifest, error) {
+	return i.LoadManifest(path)
+}
+
+func NewLifecycleFromManifest(m *Manifest) (LifeCycleManager, error) {
+	return i.NewLifecycleFromManifest(m)
+}
@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType is the topic of a lifecycle event. Types are dotted: "<subject>.<verb>".
+type EventType string
+
+const (
+	EventLifecycleStarted  EventType = "lifecycle.started"
+	EventLifecycleStopped  EventType = "lifecycle.stopped"
+	EventSignalReceived    EventType = "signal.received"
+	EventProcessRegistered EventType = "process.registered"
+	EventProcessStarted    EventType = "process.started"
+	EventProcessPaused     EventType = "process.paused"
+	EventProcessResumed    EventType = "process.resumed"
+	EventProcessReloaded   EventType = "process.reloaded"
+	EventProcessMetrics    EventType = "process.metrics"
+	EventProcessExited     EventType = "process.exited"
+	EventProcessFatal      EventType = "process.fatal"
+	EventProcessStopped    EventType = "process.stopped"
+	EventStageRegistered   EventType = "stage.registered"
+	EventStageEntered      EventType = "stage.entered"
+	EventStageExited       EventType = "stage.exited"
+	EventRegistered        EventType = "event.registered"
+	EventRemoved           EventType = "event.removed"
+	EventTriggered         EventType = "event.triggered"
+	EventHealthChanged     EventType = HealthChangedEvent
+)
+
+// DefaultEventHistory is the number of events kept by a bus for replay.
+const DefaultEventHistory = 1000
+
+// recorded reports whether events of type t are kept in the bus history. Metrics samples are
+// only delivered: published every few seconds per process, they would push the lifecycle events
+// out of the history.
+func recorded(t EventType) bool { return t != EventProcessMetrics }
+
+// LifecycleEvent is a typed event published on the bus. IDs increase by one per event.
+type LifecycleEvent struct {
+	ID      uint64      `json:"id"`
+	Type    EventType   `json:"type"`
+	Time    time.Time   `json:"time"`
+	Process string      `json:"process,omitempty"`
+	Stage   string      `json:"stage,omitempty"`
+	Event   string      `json:"event,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// ProcessStateChange is the payload of the process events.
+type ProcessStateChange struct {
+	Process    string       `json:"process"`
+	Previous   ProcessState `json:"previous"`
+	Current    ProcessState `json:"current"`
+	Pid        int          `json:"pid,omitempty"`
+	ExitCode   int          `json:"exitCode"`
+	ExitSignal string       `json:"exitSignal,omitempty"`
+	ExitReason ExitReason   `json:"exitReason,omitempty"`
+	Restarts   int          `json:"restarts"`
+}
+
+// EventBus fans lifecycle events out to subscribers and keeps the last ones for replay.
+// Delivery never blocks the publisher: a subscriber whose buffer is full misses the event.
+type EventBus struct {
+	mu      sync.Mutex
+	next    uint64
+	subs    map[*Subscription]struct{}
+	history []LifecycleEvent
+	limit   int
+	sinks   []func(LifecycleEvent)
+}
+
+// NewEventBus creates a bus keeping up to historyLimit events (DefaultEventHistory when <= 0).
+func NewEventBus(historyLimit int) *EventBus {
+	if historyLimit <= 0 {
+		historyLimit = DefaultEventHistory
+	}
+	return &EventBus{next: 1, subs: make(map[*Subscription]struct{}), limit: historyLimit}
+}
+
+// Publish stamps ev with the next ID and the current time, records it (but process.metrics)
+// and delivers it.
+func (b *EventBus) Publish(ev LifecycleEvent) LifecycleEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ev.ID = b.next
+	b.next++
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if recorded(ev.Type) {
+		b.history = append(b.history, ev)
+		if len(b.history) > b.limit {
+			b.history = append([]LifecycleEvent(nil), b.history[len(b.history)-b.limit:]...)
+		}
+	}
+	for _, sink := range b.sinks {
+		sink(ev)
+	}
+	for sub := range b.subs {
+		if !sub.matches(ev) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			sub.dropped++
+		}
+	}
+	return ev
+}
+
+// OnPublish registers fn to receive every event synchronously, in ID order, before the
+// subscribers. Unlike subscriptions it never misses an event, so fn must be quick and must not
+// publish.
+func (b *EventBus) OnPublish(fn func(LifecycleEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sinks = append(b.sinks, fn)
+}
+
+// Subscribe returns a subscription receiving the events of the given topics, or every event
+// when no topic is given. A topic matches an event type exactly, or as a prefix when it ends
+// with ".*" ("process.*" matches "process.started").
+func (b *EventBus) Subscribe(buffer int, topics ...string) *Subscription {
+	return b.subscribe(buffer, topics, nil)
+}
+
+// SubscribeFunc returns a subscription receiving the events accepted by filter.
+func (b *EventBus) SubscribeFunc(buffer int, filter func(LifecycleEvent) bool) *Subscription {
+	return b.subscribe(buffer, nil, filter)
+}
+
+func (b *EventBus) subscribe(buffer int, topics []string, filter func(LifecycleEvent) bool) *Subscription {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	sub := &Subscription{bus: b, ch: make(chan LifecycleEvent, buffer), topics: topics, filter: filter}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Since returns the recorded events with an ID greater than id, oldest first. The process.metrics
+// events are not recorded, so their IDs are missing.
+func (b *EventBus) Since(id uint64) []LifecycleEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make([]LifecycleEvent, 0)
+	for _, ev := range b.history {
+		if ev.ID > id {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// LastID returns the ID of the last published event, 0 when nothing was published.
+func (b *EventBus) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.next - 1
+}
+
+// Subscription is a filtered stream of bus events.
+type Subscription struct {
+	bus     *EventBus
+	ch      chan LifecycleEvent
+	topics  []string
+	filter  func(LifecycleEvent) bool
+	dropped uint64
+	closed  bool
+}
+
+// C returns the channel the events are delivered on. It is closed by Close.
+func (s *Subscription) C() <-chan LifecycleEvent { return s.ch }
+
+// Dropped returns the number of events missed because the buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	return s.dropped
+}
+
+// Close stops the delivery and closes the channel.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(s.bus.subs, s)
+	close(s.ch)
+}
+
+func (s *Subscription) matches(ev LifecycleEvent) bool {
+	if s.filter != nil {
+		return s.filter(ev)
+	}
+	if len(s.topics) == 0 {
+		return true
+	}
+	for _, topic := range s.topics {
+		if MatchTopic(topic, ev.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchTopic reports whether topic selects events of type t: "*" selects everything, "x.*"
+// selects the types starting with "x." and any other topic selects its exact type.
+func MatchTopic(topic string, t EventType) bool {
+	if topic == "*" || topic == string(t) {
+		return true
+	}
+	return strings.HasSuffix(topic, ".*") && strings.HasPrefix(string(t), strings.TrimSuffix(topic, "*"))
+}
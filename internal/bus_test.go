@@ -0,0 +1,30 @@
+package internal
+
+import "testing"
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		topic string
+		event EventType
+		want  bool
+	}{
+		{"*", EventProcessStarted, true},
+		{"*", EventStageEntered, true},
+		{"process.started", EventProcessStarted, true},
+		{"process.started", EventProcessStopped, false},
+		{"process.*", EventProcessStarted, true},
+		{"process.*", EventProcessMetrics, true},
+		{"process.*", EventStageEntered, false},
+		{"process.*", "process", false},
+		{"process.*", "processes.started", false},
+		{"stage.*", EventStageExited, true},
+		{"event.*", EventTriggered, true},
+		{"event", EventTriggered, false},
+		{"process", EventProcessStarted, false},
+	}
+	for _, tt := range tests {
+		if got := MatchTopic(tt.topic, tt.event); got != tt.want {
+			t.Errorf("MatchTopic(%q, %q) = %t, want %t", tt.topic, tt.event, got, tt.want)
+		}
+	}
+}
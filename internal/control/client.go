@@ -0,0 +1,63 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Client talks to a running daemon over its control socket.
+type Client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	enc     *json.Encoder
+}
+
+// Dial connects to the daemon listening on path.
+func Dial(path string) (*Client, error) {
+	if path == "" {
+		path = DefaultSocketPath()
+	}
+	conn, err := net.DialTimeout("unix", path, 2*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("no golife daemon reachable on %s: %w", path, err)
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	return &Client{conn: conn, scanner: scanner, enc: json.NewEncoder(conn)}, nil
+}
+
+// Call sends a request and waits for its response. Remote failures are returned as errors.
+func (c *Client) Call(req Request) (*Response, error) {
+	if err := c.enc.Encode(req); err != nil {
+		return nil, err
+	}
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("control connection closed by the daemon")
+	}
+	var resp Response
+	if err := json.Unmarshal(c.scanner.Bytes(), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, resp.Err()
+}
+
+// Close closes the connection.
+func (c *Client) Close() error { return c.conn.Close() }
+
+// Ping reports whether a daemon answers on path.
+func Ping(path string) bool {
+	c, err := Dial(path)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = c.Close() }()
+
+	_, err = c.Call(Request{Op: OpPing})
+	return err == nil
+}
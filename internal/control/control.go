@@ -0,0 +1,84 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rafa-mori/golife/internal"
+	"os"
+	"path/filepath"
+)
+
+// Operations understood by the control socket.
+const (
+	OpPing        = "ping"
+	OpStart       = "start"
+	OpStop        = "stop"
+	OpRestart     = "restart"
+	OpPause       = "pause"
+	OpResume      = "resume"
+	OpReload      = "reload"
+	OpStatus      = "status"
+	OpSnapshot    = "snapshot"
+	OpTrigger     = "trigger"
+	OpRegEvent    = "regEvent"
+	OpRemoveEvent = "removeEvent"
+	OpStopEvents  = "stopEvents"
+	OpShutdown    = "shutdown"
+	OpLogs        = "logs"
+)
+
+// Request is a single command sent to the daemon. Each line on the socket holds one JSON request.
+type Request struct {
+	Op      string   `json:"op"`
+	Name    string   `json:"name,omitempty"`
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Restart bool     `json:"restart,omitempty"`
+	Stage   string   `json:"stage,omitempty"`
+	Event   string   `json:"event,omitempty"`
+	Data    string   `json:"data,omitempty"`
+	Stream  string   `json:"stream,omitempty"` // Output stream of logs: stdout, stderr or both when empty
+	Lines   int      `json:"lines,omitempty"`  // Return only the last lines of logs (every line when 0)
+	Since   uint64   `json:"since,omitempty"`  // Return only the log lines after this sequence number
+}
+
+// LogsResult is the payload of an OpLogs response. Next is the Since value of the following call.
+type LogsResult struct {
+	Lines []internal.LogLine `json:"lines"`
+	Next  uint64             `json:"next"`
+}
+
+// Response is the daemon answer to a Request.
+type Response struct {
+	OK    bool            `json:"ok"`
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// Err returns the remote error carried by the response, if any.
+func (r *Response) Err() error {
+	if r.OK {
+		return nil
+	}
+	return fmt.Errorf("%s", r.Error)
+}
+
+// Decode unmarshals the response payload into v.
+func (r *Response) Decode(v interface{}) error {
+	if len(r.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Data, v)
+}
+
+// DefaultSocketPath returns the control socket path: $GOLIFE_SOCKET, then
+// $XDG_RUNTIME_DIR/golife.sock, then a per-user socket in the temp dir.
+func DefaultSocketPath() string {
+	if p := os.Getenv("GOLIFE_SOCKET"); p != "" {
+		return p
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "golife.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("golife-%d.sock", os.Getuid()))
+}
@@ -0,0 +1,295 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rafa-mori/golife/internal"
+	l "github.com/rafa-mori/logz"
+	"golang.org/x/sys/unix"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// HandlerFunc serves one control operation and returns the payload of the response.
+type HandlerFunc func(req Request) (interface{}, error)
+
+// Server exposes a LifeCycleManager on a Unix-domain socket.
+type Server struct {
+	lm       internal.LifeCycleManager
+	path     string
+	listener net.Listener
+	handlers map[string]HandlerFunc
+	doneCh   chan struct{}
+	once     sync.Once
+	mu       sync.RWMutex
+}
+
+// NewServer creates a control server for lm listening on path.
+func NewServer(lm internal.LifeCycleManager, path string) *Server {
+	if path == "" {
+		path = DefaultSocketPath()
+	}
+	srv := &Server{
+		lm:       lm,
+		path:     path,
+		handlers: make(map[string]HandlerFunc),
+		doneCh:   make(chan struct{}),
+	}
+	srv.registerDefaults()
+	return srv
+}
+
+// Handle registers (or replaces) the handler of an operation.
+func (s *Server) Handle(op string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[op] = fn
+}
+
+// Path returns the socket path.
+func (s *Server) Path() string { return s.path }
+
+// Done is closed once the server has been shut down, locally or through OpShutdown.
+func (s *Server) Done() <-chan struct{} { return s.doneCh }
+
+// Listen binds the socket. A stale socket left by a dead daemon is removed; a live one is an error.
+// The socket is bound inside a private directory and only moved to its path once it is mode 0600,
+// so no other user can connect while it still has the permissions of the umask.
+func (s *Server) Listen() error {
+	if _, err := os.Stat(s.path); err == nil {
+		if conn, dialErr := net.Dial("unix", s.path); dialErr == nil {
+			_ = conn.Close()
+			return fmt.Errorf("a golife daemon is already listening on %s", s.path)
+		}
+		if err := os.Remove(s.path); err != nil {
+			return err
+		}
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(s.path), ".golife-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tmpPath := filepath.Join(dir, "sock")
+	lis, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return err
+	}
+	// The socket is removed by Close under its final path.
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		_ = lis.Close()
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		_ = lis.Close()
+		return err
+	}
+	s.listener = lis
+	return nil
+}
+
+// Serve accepts connections until Close is called.
+func (s *Server) Serve() error {
+	if s.listener == nil {
+		if err := s.Listen(); err != nil {
+			return err
+		}
+	}
+	l.Info(fmt.Sprintf("Control socket listening on %s", s.path), map[string]interface{}{"context": "GoLife", "socket": s.path, "showData": false})
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.doneCh:
+				return nil
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if err := checkPeer(conn); err != nil {
+			l.Warn(fmt.Sprintf("Control connection refused: %s", err), map[string]interface{}{"context": "GoLife", "socket": s.path, "showData": false})
+			_ = conn.Close()
+			continue
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections and removes the socket.
+func (s *Server) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.doneCh)
+		if s.listener != nil {
+			err = s.listener.Close()
+		}
+		_ = os.Remove(s.path)
+	})
+	return err
+}
+
+// checkPeer only lets in the user running the daemon and root.
+func checkPeer(conn net.Conn) error {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var cred *unix.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return err
+	}
+	if credErr != nil {
+		return credErr
+	}
+	if cred.Uid != 0 && int(cred.Uid) != os.Getuid() {
+		return fmt.Errorf("uid %d is not allowed to drive the daemon of uid %d", cred.Uid, os.Getuid())
+	}
+	return nil
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req Request
+		resp := Response{}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = fmt.Sprintf("invalid request: %v", err)
+		} else {
+			resp = s.dispatch(req)
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) dispatch(req Request) Response {
+	s.mu.RLock()
+	fn, ok := s.handlers[req.Op]
+	s.mu.RUnlock()
+	if !ok {
+		return Response{Error: fmt.Sprintf("unknown operation %q", req.Op)}
+	}
+
+	// The payload is kept on failures too, e.g. the per-process outcomes of a failed stop.
+	data, err := fn(req)
+	resp := Response{OK: err == nil}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	if data != nil {
+		raw, mErr := json.Marshal(data)
+		if mErr != nil {
+			return Response{Error: mErr.Error()}
+		}
+		resp.Data = raw
+	}
+	return resp
+}
+
+func (s *Server) registerDefaults() {
+	s.Handle(OpPing, func(req Request) (interface{}, error) { return "pong", nil })
+	s.Handle(OpStart, s.start)
+	s.Handle(OpStop, s.stop)
+	s.Handle(OpRestart, s.restart)
+	s.Handle(OpPause, func(req Request) (interface{}, error) { return nil, s.lm.Pause(req.Name) })
+	s.Handle(OpResume, func(req Request) (interface{}, error) { return nil, s.lm.Resume(req.Name) })
+	s.Handle(OpReload, func(req Request) (interface{}, error) { return nil, s.lm.Reload(req.Name) })
+	s.Handle(OpStatus, func(req Request) (interface{}, error) { return s.lm.Status(), nil })
+	s.Handle(OpSnapshot, func(req Request) (interface{}, error) { return s.lm.Snapshot(), nil })
+	s.Handle(OpTrigger, func(req Request) (interface{}, error) {
+		return nil, s.lm.Trigger(req.Stage, req.Event, req.Data)
+	})
+	s.Handle(OpRegEvent, s.regEvent)
+	s.Handle(OpRemoveEvent, func(req Request) (interface{}, error) {
+		return nil, s.lm.RemoveEvent(req.Event, req.Stage)
+	})
+	s.Handle(OpStopEvents, func(req Request) (interface{}, error) { return nil, s.lm.StopEvents() })
+	s.Handle(OpLogs, s.logs)
+	s.Handle(OpShutdown, func(req Request) (interface{}, error) {
+		go func() { _ = s.Close() }()
+		return nil, nil
+	})
+}
+
+// start registers the process when it is new and starts it.
+func (s *Server) start(req Request) (interface{}, error) {
+	if req.Name == "" {
+		return nil, s.lm.StartAll()
+	}
+	proc := s.lm.GetProcess(req.Name)
+	if proc == nil {
+		if req.Command == "" {
+			return nil, fmt.Errorf("process %s is not registered and no command was given", req.Name)
+		}
+		if err := s.lm.RegisterProcess(req.Name, req.Command, req.Args, req.Restart, nil); err != nil {
+			return nil, err
+		}
+		proc = s.lm.GetProcess(req.Name)
+	}
+	return nil, s.lm.StartProcess(proc)
+}
+
+// stop stops one process, or every process when no name is given, and returns the per-process outcomes.
+func (s *Server) stop(req Request) (interface{}, error) {
+	if req.Name == "" {
+		report := s.lm.StopProcesses()
+		return report, report.Err()
+	}
+	proc := s.lm.GetProcess(req.Name)
+	if proc == nil {
+		return nil, fmt.Errorf("process %s not found", req.Name)
+	}
+	outcome, err := proc.Shutdown()
+	return internal.StopReport{{Name: req.Name, Outcome: outcome, Err: err}}, err
+}
+
+func (s *Server) restart(req Request) (interface{}, error) {
+	if req.Name == "" {
+		return nil, s.lm.Restart()
+	}
+	proc := s.lm.GetProcess(req.Name)
+	if proc == nil {
+		return nil, fmt.Errorf("process %s not found", req.Name)
+	}
+	return nil, proc.Restart()
+}
+
+// logs returns the last captured lines of a process after req.Since, up to req.Lines of them.
+func (s *Server) logs(req Request) (interface{}, error) {
+	proc := s.lm.GetProcess(req.Name)
+	if proc == nil {
+		return nil, fmt.Errorf("process %s not found", req.Name)
+	}
+	lines, next := proc.Monit().Logs(req.Stream, req.Since, req.Lines)
+	return LogsResult{Lines: lines, Next: next}, nil
+}
+
+// regEvent registers an event whose handler logs the received data inside the daemon.
+func (s *Server) regEvent(req Request) (interface{}, error) {
+	stage, event := req.Stage, req.Event
+	return nil, s.lm.RegisterEvent(event, stage, func(data interface{}) {
+		l.Info(fmt.Sprintf("Event %s received in stage %s", event, stage), map[string]interface{}{"context": "GoLife", "stage": stage, "event": event, "data": data, "showData": true})
+	})
+}
@@ -0,0 +1,162 @@
+package control
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rafa-mori/golife/internal"
+)
+
+// TestServerConcurrentClients drives one daemon from several control connections while the
+// embedding code changes its stages. Run it with -race.
+func TestServerConcurrentClients(t *testing.T) {
+	lm := internal.NewLifecycleManager(nil, nil, nil, nil, nil, nil)
+	if err := lm.RegisterStage(internal.NewStage("work", "", "")); err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(lm, filepath.Join(t.TempDir(), "golife.sock"))
+	if err := srv.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve() }()
+	defer func() { _ = lm.StopAll() }()
+
+	const clients, rounds = 4, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, clients+2)
+	for c := 0; c < clients; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			client, err := Dial(srv.Path())
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer func() { _ = client.Close() }()
+
+			event := fmt.Sprintf("event-%d", c)
+			for i := 0; i < rounds; i++ {
+				for _, req := range []Request{
+					{Op: OpRegEvent, Stage: "work", Event: event},
+					{Op: OpTrigger, Stage: "work", Event: event, Data: "payload"},
+					{Op: OpStatus},
+					{Op: OpSnapshot},
+					{Op: OpRemoveEvent, Stage: "work", Event: event},
+				} {
+					if _, err := client.Call(req); err != nil {
+						errs <- fmt.Errorf("client %d, %s: %w", c, req.Op, err)
+						return
+					}
+				}
+			}
+		}(c)
+	}
+
+	// A process started, followed and stopped through the socket.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		client, err := Dial(srv.Path())
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer func() { _ = client.Close() }()
+
+		if _, err := client.Call(Request{Op: OpStart, Name: "ticker", Command: "sh", Args: []string{"-c", "while true; do echo tick; sleep 0.01; done"}}); err != nil {
+			errs <- fmt.Errorf("start: %w", err)
+			return
+		}
+		req := Request{Op: OpLogs, Name: "ticker", Lines: 10}
+		for i := 0; i < rounds; i++ {
+			resp, err := client.Call(req)
+			if err != nil {
+				errs <- fmt.Errorf("logs: %w", err)
+				return
+			}
+			var result LogsResult
+			if err := resp.Decode(&result); err != nil {
+				errs <- err
+				return
+			}
+			for _, line := range result.Lines {
+				if line.Seq <= req.Since {
+					errs <- fmt.Errorf("logs: line %d returned again after %d", line.Seq, req.Since)
+					return
+				}
+			}
+			req.Since, req.Lines = result.Next, 0
+			time.Sleep(5 * time.Millisecond)
+		}
+		if _, err := client.Call(Request{Op: OpStop, Name: "ticker"}); err != nil {
+			errs <- fmt.Errorf("stop: %w", err)
+		}
+	}()
+
+	// The embedding code registers stages and reads them meanwhile.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			name := fmt.Sprintf("stage-%d", i)
+			if err := lm.RegisterStage(internal.NewStage(name, "", "")); err != nil {
+				errs <- err
+				return
+			}
+			if err := lm.DefineStage(name); err != nil {
+				errs <- err
+				return
+			}
+			_ = lm.GetStages()
+			_ = lm.GetCurrentStage()
+		}
+	}()
+
+	// And reads the stages and their events as fast as it can until the clients are done.
+	stop := make(chan struct{})
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			for _, stage := range lm.GetStages() {
+				_ = stage.EventExists("event-0")
+			}
+			_ = lm.Handles("work", "event-1")
+		}
+	}()
+
+	wg.Wait()
+	close(stop)
+	<-read
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	client, err := Dial(srv.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+	if _, err := client.Call(Request{Op: OpShutdown}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-srv.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not done after shutdown")
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+}
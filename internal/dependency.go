@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// dependencyLevels groups processes in topological levels: every process only depends on
+// processes of earlier levels. Names inside a level are sorted to keep the order stable.
+func dependencyLevels(processes map[string]IManagedProcess) ([][]string, error) {
+	indegree := make(map[string]int, len(processes))
+	dependents := make(map[string][]string, len(processes))
+	for name, proc := range processes {
+		indegree[name] += 0
+		for _, dep := range proc.GetDependsOn() {
+			if _, ok := processes[dep]; !ok {
+				return nil, fmt.Errorf("process %s depends on unknown process %s", name, dep)
+			}
+			indegree[name]++
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	levels := make([][]string, 0)
+	current := make([]string, 0)
+	for name, degree := range indegree {
+		if degree == 0 {
+			current = append(current, name)
+		}
+	}
+	placed := 0
+	for len(current) > 0 {
+		sort.Strings(current)
+		levels = append(levels, current)
+		placed += len(current)
+		next := make([]string, 0)
+		for _, name := range current {
+			for _, dependent := range dependents[name] {
+				indegree[dependent]--
+				if indegree[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		current = next
+	}
+
+	if placed != len(processes) {
+		return nil, fmt.Errorf("dependency cycle detected: %s", strings.Join(findCycle(processes), " -> "))
+	}
+	return levels, nil
+}
+
+// findCycle returns one dependency cycle among processes, or nil. Unknown dependencies are ignored
+// so that processes can be registered in any order.
+func findCycle(processes map[string]IManagedProcess) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	marks := make(map[string]int, len(processes))
+	stack := make([]string, 0)
+
+	names := make([]string, 0, len(processes))
+	for name := range processes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		marks[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range processes[name].GetDependsOn() {
+			if _, ok := processes[dep]; !ok {
+				continue
+			}
+			switch marks[dep] {
+			case visiting:
+				for i, n := range stack {
+					if n == dep {
+						return append(append([]string(nil), stack[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		marks[name] = done
+		return nil
+	}
+
+	for _, name := range names {
+		if marks[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// dependencyGraph builds unstarted processes from a name -> dependencies map.
+func dependencyGraph(deps map[string][]string) map[string]IManagedProcess {
+	processes := make(map[string]IManagedProcess, len(deps))
+	for name, dependsOn := range deps {
+		p := NewManagedProcess(name, "true", nil, false, nil)
+		p.SetDependsOn(dependsOn)
+		processes[name] = p
+	}
+	return processes
+}
+
+func TestDependencyLevels(t *testing.T) {
+	tests := []struct {
+		name   string
+		deps   map[string][]string
+		levels [][]string
+		err    string // Part of the expected error, empty when the graph is valid
+	}{
+		{name: "empty", deps: map[string][]string{}, levels: [][]string{}},
+		{name: "independent", deps: map[string][]string{"b": nil, "a": nil, "c": nil}, levels: [][]string{{"a", "b", "c"}}},
+		{
+			name:   "chain",
+			deps:   map[string][]string{"api": {"cache"}, "cache": {"db"}, "db": nil},
+			levels: [][]string{{"db"}, {"cache"}, {"api"}},
+		},
+		{
+			name:   "diamond",
+			deps:   map[string][]string{"web": {"api", "auth"}, "api": {"db"}, "auth": {"db"}, "db": nil, "metrics": nil},
+			levels: [][]string{{"db", "metrics"}, {"api", "auth"}, {"web"}},
+		},
+		{
+			name:   "waits for its slowest dependency",
+			deps:   map[string][]string{"web": {"db", "api"}, "api": {"db"}, "db": nil},
+			levels: [][]string{{"db"}, {"api"}, {"web"}},
+		},
+		{name: "unknown dependency", deps: map[string][]string{"api": {"db"}}, err: "api depends on unknown process db"},
+		{name: "self dependency", deps: map[string][]string{"api": {"api"}}, err: "cycle detected: api -> api"},
+		{
+			name: "cycle",
+			deps: map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}, "d": nil},
+			err:  "cycle detected: a -> b -> c -> a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels, err := dependencyLevels(dependencyGraph(tt.deps))
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("err = %v, want one containing %q", err, tt.err)
+			}
+			if tt.err == "" && !reflect.DeepEqual(levels, tt.levels) {
+				t.Fatalf("levels = %v, want %v", levels, tt.levels)
+			}
+		})
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	tests := []struct {
+		name  string
+		deps  map[string][]string
+		cycle []string
+	}{
+		{name: "acyclic", deps: map[string][]string{"a": {"b"}, "b": nil}},
+		{name: "unknown dependencies are ignored", deps: map[string][]string{"a": {"x"}}},
+		{name: "self", deps: map[string][]string{"a": {"a"}}, cycle: []string{"a", "a"}},
+		{name: "two", deps: map[string][]string{"a": {"b"}, "b": {"a"}}, cycle: []string{"a", "b", "a"}},
+		{
+			name:  "reached through an acyclic prefix",
+			deps:  map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"d"}, "d": {"b"}},
+			cycle: []string{"b", "c", "d", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findCycle(dependencyGraph(tt.deps)); !reflect.DeepEqual(got, tt.cycle) {
+				t.Fatalf("findCycle = %v, want %v", got, tt.cycle)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package internal
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 type IManagedProcessEvents interface {
 	Event() string
@@ -74,6 +77,9 @@ func (m *ManagedProcessEvents) RegisterEvent(event string, fn func(interface{}))
 
 	m.EventFns[event] = fn
 }
+
+// Trigger calls the function registered for event, then the functions registered under a
+// pattern matching it (see MatchEvent), sorted by pattern.
 func (m *ManagedProcessEvents) Trigger(stage, event string, data interface{}) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -81,6 +87,16 @@ func (m *ManagedProcessEvents) Trigger(stage, event string, data interface{}) {
 	if fn, ok := m.EventFns[event]; ok {
 		fn(data)
 	}
+	patterns := make([]string, 0)
+	for pattern := range m.EventFns {
+		if pattern != event && IsEventPattern(pattern) && MatchEvent(pattern, event) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		m.EventFns[pattern](data)
+	}
 }
 func (m *ManagedProcessEvents) Send(stage string, msg interface{}) {
 	m.mu.Lock()
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// CgroupRoot is where the cgroup v2 hierarchy is mounted.
+var CgroupRoot = "/sys/fs/cgroup"
+
+// freezeTimeout bounds how long freezing or thawing a cgroup may take.
+const freezeTimeout = 2 * time.Second
+
+// freezeTree suspends the process tree of pid. The cgroup v2 freezer is used when cgroupDir, the
+// group the process was started in, is set; otherwise the process group is sent SIGSTOP. It
+// returns the frozen cgroup directory, empty when the group was stopped with a signal.
+func freezeTree(pid int, cgroupDir string) (string, error) {
+	if cgroupDir != "" {
+		if err := setCgroupFrozen(cgroupDir, true); err == nil {
+			return cgroupDir, nil
+		}
+		// The group may be left partly frozen, with nothing to thaw it on Resume.
+		_ = os.WriteFile(filepath.Join(cgroupDir, "cgroup.freeze"), []byte("0"), 0644)
+	}
+	return "", signalGroup(pid, syscall.SIGSTOP)
+}
+
+// thawTree resumes a process tree suspended by freezeTree.
+func thawTree(pid int, cgroupDir string) error {
+	if cgroupDir != "" {
+		return setCgroupFrozen(cgroupDir, false)
+	}
+	return signalGroup(pid, syscall.SIGCONT)
+}
+
+// cgroupPath returns the cgroup v2 path of pid, relative to CgroupRoot.
+func cgroupPath(pid string) string {
+	f, err := os.Open(filepath.Join("/proc", pid, "cgroup"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if path, ok := strings.CutPrefix(sc.Text(), "0::"); ok {
+			return path
+		}
+	}
+	return ""
+}
+
+// setCgroupFrozen writes cgroup.freeze and waits for cgroup.events to report the new state.
+func setCgroupFrozen(dir string, frozen bool) error {
+	value := "0"
+	if frozen {
+		value = "1"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cgroup.freeze"), []byte(value), 0644); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(freezeTimeout)
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "cgroup.events"))
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line == "frozen "+value {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("cgroup %s did not reach frozen=%s within %s", dir, value, freezeTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,252 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// ProbeType is the kind of check performed by a Probe.
+type ProbeType string
+
+const (
+	ProbeExec ProbeType = "exec" // Run a command, healthy on exit code 0
+	ProbeTCP  ProbeType = "tcp"  // Open a TCP connection
+	ProbeHTTP ProbeType = "http" // HTTP GET, healthy on 2xx/3xx
+)
+
+// HealthChangedEvent is the lifecycle event triggered when the health of a process changes.
+const HealthChangedEvent = "health.changed"
+
+// Probe describes a periodic health check.
+type Probe struct {
+	Type             ProbeType     // Kind of check
+	Command          []string      // Command and arguments of an exec probe
+	Address          string        // host:port of a tcp probe
+	URL              string        // URL of an http probe
+	InitialDelay     time.Duration // Delay after the process starts before the first check
+	Period           time.Duration // Time between checks (default 10s)
+	Timeout          time.Duration // Timeout of a single check (default 1s)
+	FailureThreshold int           // Consecutive failures before the probe fails (default 3)
+	SuccessThreshold int           // Consecutive successes before the probe succeeds (default 1)
+}
+
+// Check runs the probe once.
+func (p *Probe) Check(ctx context.Context) error {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch p.Type {
+	case ProbeExec:
+		if len(p.Command) == 0 {
+			return fmt.Errorf("exec probe without command")
+		}
+		return exec.CommandContext(ctx, p.Command[0], p.Command[1:]...).Run()
+	case ProbeTCP:
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", p.Address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case ProbeHTTP:
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown probe type %q", p.Type)
+	}
+}
+
+// HealthStatus is the liveness verdict of a process.
+type HealthStatus string
+
+const (
+	HealthUnknown   HealthStatus = "unknown"
+	HealthHealthy   HealthStatus = "healthy"
+	HealthUnhealthy HealthStatus = "unhealthy"
+)
+
+// HealthState is the current health of a process.
+type HealthState struct {
+	Status    HealthStatus `json:"status" yaml:"status"`
+	Ready     bool         `json:"ready" yaml:"ready"`
+	Failures  int          `json:"failures" yaml:"failures"`
+	LastError string       `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+	CheckedAt time.Time    `json:"checkedAt,omitempty" yaml:"checkedAt,omitempty"`
+}
+
+// HealthChange is the payload of HealthChangedEvent.
+type HealthChange struct {
+	Process  string      `json:"process"`
+	Previous HealthState `json:"previous"`
+	Current  HealthState `json:"current"`
+}
+
+// healthMonitor runs the liveness and readiness probes of a process while it is running.
+type healthMonitor struct {
+	mu        sync.Mutex
+	liveness  *Probe
+	readiness *Probe
+	state     HealthState
+	cancel    context.CancelFunc
+	onChange  func(previous, current HealthState)
+	onFailure func() // Called when the liveness probe fails
+}
+
+func newHealthMonitor() *healthMonitor {
+	return &healthMonitor{state: HealthState{Status: HealthUnknown}}
+}
+
+// start resets the health state and launches the probes for a new run of the process.
+func (h *healthMonitor) start() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.state = HealthState{Status: HealthUnknown, Ready: h.readiness == nil}
+	if h.liveness == nil && h.readiness == nil {
+		h.cancel = nil
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	if h.liveness != nil {
+		go h.run(ctx, *h.liveness, true)
+	}
+	if h.readiness != nil {
+		go h.run(ctx, *h.readiness, false)
+	}
+}
+
+// stop halts the probes; the process is no longer running.
+func (h *healthMonitor) stop() {
+	h.mu.Lock()
+	if h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+	}
+	previous := h.state
+	h.state.Ready = false
+	current := h.state
+	onChange := h.onChange
+	h.mu.Unlock()
+
+	if onChange != nil && previous.Ready != current.Ready {
+		onChange(previous, current)
+	}
+}
+
+func (h *healthMonitor) snapshot() HealthState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.state
+}
+
+func (h *healthMonitor) run(ctx context.Context, probe Probe, liveness bool) {
+	period := probe.Period
+	if period <= 0 {
+		period = 10 * time.Second
+	}
+	failureThreshold := probe.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = 3
+	}
+	successThreshold := probe.SuccessThreshold
+	if successThreshold <= 0 {
+		successThreshold = 1
+	}
+
+	if probe.InitialDelay > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(probe.InitialDelay):
+		}
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	successes, failures := 0, 0
+	for {
+		err := probe.Check(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			successes++
+			failures = 0
+		} else {
+			failures++
+			successes = 0
+		}
+		h.record(liveness, err, failures, successes >= successThreshold, failures >= failureThreshold)
+		if liveness && failures >= failureThreshold {
+			failures = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// record updates the state after a check and fires the callbacks outside the lock.
+func (h *healthMonitor) record(liveness bool, err error, failures int, passed, failed bool) {
+	h.mu.Lock()
+	previous := h.state
+	h.state.CheckedAt = time.Now()
+	if err != nil {
+		h.state.LastError = err.Error()
+	} else {
+		h.state.LastError = ""
+	}
+	if liveness {
+		h.state.Failures = failures
+		switch {
+		case passed:
+			h.state.Status = HealthHealthy
+		case failed:
+			h.state.Status = HealthUnhealthy
+		}
+	} else {
+		switch {
+		case passed:
+			h.state.Ready = true
+		case failed:
+			h.state.Ready = false
+		}
+	}
+	current := h.state
+	onChange, onFailure := h.onChange, h.onFailure
+	h.mu.Unlock()
+
+	if onChange != nil && (previous.Status != current.Status || previous.Ready != current.Ready) {
+		onChange(previous, current)
+	}
+	if liveness && failed && onFailure != nil {
+		onFailure()
+	}
+}
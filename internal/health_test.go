@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedProbe is an exec probe passing or failing as told by outcomes, one check per letter
+// (S or F). Once the outcomes run out the check hangs, so the monitor can be stopped before it
+// records anything else.
+func scriptedProbe(dir, outcomes string, failureThreshold, successThreshold int) *Probe {
+	script := fmt.Sprintf(`n=$(($(cat count 2>/dev/null || echo 0) + 1)); echo $n > count
+[ $n -gt %d ] && exec sleep 5
+[ "$(echo %s | cut -c$n)" = S ]`, len(outcomes), outcomes)
+	return &Probe{
+		Type:             ProbeExec,
+		Command:          []string{"sh", "-c", "cd " + dir + " && " + script},
+		Period:           10 * time.Millisecond,
+		Timeout:          10 * time.Second,
+		FailureThreshold: failureThreshold,
+		SuccessThreshold: successThreshold,
+	}
+}
+
+func TestProbeThresholds(t *testing.T) {
+	tests := []struct {
+		name      string
+		readiness bool
+		outcomes  string
+		failures  int // FailureThreshold
+		successes int // SuccessThreshold
+		changes   []string
+		restarts  int // onFailure calls
+	}{
+		{name: "fails after the threshold", outcomes: "FFF", failures: 3, changes: []string{"unhealthy"}, restarts: 1},
+		{name: "a success resets the failures", outcomes: "FFSFF", failures: 3, changes: []string{"healthy"}},
+		{name: "fails again after a restart", outcomes: "FFFFF", failures: 2, changes: []string{"unhealthy"}, restarts: 2},
+		{name: "recovers", outcomes: "SFSS", failures: 1, changes: []string{"healthy", "unhealthy", "healthy"}, restarts: 1},
+		{name: "success threshold", outcomes: "SFSS", failures: 3, successes: 2, changes: []string{"healthy"}},
+		{name: "default thresholds", outcomes: "FFS", changes: []string{"healthy"}},
+		{name: "readiness", readiness: true, outcomes: "FSSFF", failures: 2, changes: []string{"ready", "not ready"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			probe := scriptedProbe(dir, tt.outcomes, tt.failures, tt.successes)
+
+			var mu sync.Mutex
+			changes := make([]string, 0)
+			restarts := 0
+			h := newHealthMonitor()
+			if tt.readiness {
+				h.readiness = probe
+			} else {
+				h.liveness = probe
+			}
+			h.onChange = func(previous, current HealthState) {
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case previous.Status != current.Status:
+					changes = append(changes, string(current.Status))
+				case current.Ready:
+					changes = append(changes, "ready")
+				default:
+					changes = append(changes, "not ready")
+				}
+			}
+			h.onFailure = func() {
+				mu.Lock()
+				defer mu.Unlock()
+				restarts++
+			}
+
+			h.start()
+			deadline := time.Now().Add(10 * time.Second)
+			for {
+				raw, _ := os.ReadFile(filepath.Join(dir, "count"))
+				if n, _ := strconv.Atoi(strings.TrimSpace(string(raw))); n > len(tt.outcomes) {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("the probe did not run every check")
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			h.mu.Lock()
+			h.onChange = nil // The readiness drop of stop is not a check
+			h.mu.Unlock()
+			h.stop()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if strings.Join(changes, ",") != strings.Join(tt.changes, ",") {
+				t.Errorf("changes = %v, want %v", changes, tt.changes)
+			}
+			if restarts != tt.restarts {
+				t.Errorf("onFailure called %d times, want %d", restarts, tt.restarts)
+			}
+		})
+	}
+}
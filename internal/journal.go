@@ -0,0 +1,592 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	l "github.com/rafa-mori/logz"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FsyncPolicy tells when the journal flushes its writes to the disk.
+type FsyncPolicy string
+
+const (
+	FsyncAlways   FsyncPolicy = "always"   // After every record
+	FsyncInterval FsyncPolicy = "interval" // Every FsyncInterval, when something was written
+	FsyncNever    FsyncPolicy = "never"    // Left to the operating system
+)
+
+const (
+	DefaultJournalSegmentSize   = 4 << 20 // Bytes written to a segment before a new one is started
+	DefaultJournalFsyncInterval = time.Second
+	DefaultJournalQueueSize     = 4096 // Events queued by AppendAsync before it waits for the writer
+
+	// journalRetentionPeriod bounds the time between two checks of MaxAge.
+	journalRetentionPeriod = time.Minute
+
+	journalExt        = ".journal"
+	journalHeaderSize = 8 // Payload length and CRC-32C, both big-endian uint32
+	journalMaxRecord  = 16 << 20
+)
+
+var (
+	// ErrJournalClosed is returned when appending to a closed journal.
+	ErrJournalClosed = errors.New("journal is closed")
+	// ErrJournalCorrupt is returned when a record fails its checksum or cannot be decoded.
+	ErrJournalCorrupt = errors.New("journal record is corrupt")
+
+	journalTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// JournalConfig defines where and how lifecycle events are journaled.
+type JournalConfig struct {
+	Dir           string        // Directory of the segment files
+	SegmentSize   int64         // Start a new segment once the current one grows past this many bytes (default DefaultJournalSegmentSize)
+	Fsync         FsyncPolicy   // When writes reach the disk (default FsyncInterval)
+	FsyncInterval time.Duration // Period of FsyncInterval (default DefaultJournalFsyncInterval)
+	MaxSize       int64         // Delete the oldest segments once the journal grows past this many bytes (0 = never)
+	MaxAge        time.Duration // Delete the segments whose last record is older than this (0 = never)
+	Topics        []string      // Event types recorded (see MatchTopic); empty records every event but process.metrics
+	QueueSize     int           // Events queued by AppendAsync before it waits for the writer (default DefaultJournalQueueSize)
+}
+
+// JournalRecord is a journaled lifecycle event. Offsets start at 0 and increase by one per
+// record. Once read back, the event data holds decoded JSON rather than the original Go values.
+type JournalRecord struct {
+	Offset uint64         `json:"offset"`
+	Time   time.Time      `json:"time"`
+	Event  LifecycleEvent `json:"event"`
+}
+
+// JournalSegment describes one file of a journal.
+type JournalSegment struct {
+	Path       string    `json:"path"`
+	BaseOffset uint64    `json:"baseOffset"` // Offset of the first record of the segment
+	Records    int       `json:"records"`
+	Size       int64     `json:"size"`
+	First      time.Time `json:"first,omitempty"`   // Time of the first record
+	Last       time.Time `json:"last,omitempty"`    // Time of the last record
+	Corrupt    string    `json:"corrupt,omitempty"` // Error met past the valid records, if any
+}
+
+// Journal is a durable, append-only log of lifecycle events split in segment files named after
+// the offset of their first record. Each record is framed with its length and checksum, and a
+// torn record left at the end of the journal by a crash is dropped when it is opened.
+type Journal struct {
+	mu     sync.Mutex
+	cfg    JournalConfig
+	file   *os.File
+	base   uint64 // Base offset of the active segment
+	size   int64  // Size of the active segment
+	first  uint64 // Offset of the oldest record kept
+	next   uint64 // Offset of the next record
+	dirty  bool   // Written since the last fsync
+	closed bool
+
+	queueMu  sync.Mutex
+	queue    []LifecycleEvent // Events queued by AppendAsync
+	queued   chan struct{}    // Signalled when events are queued
+	drained  *sync.Cond       // Broadcast, with queueMu, when the writer takes the queue
+	stopping bool             // Set by Close, no event is queued any more
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// OpenJournal opens the journal in cfg.Dir, creating it when needed, and recovers its end.
+func OpenJournal(cfg JournalConfig) (*Journal, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("journal directory is required")
+	}
+	if cfg.SegmentSize <= 0 {
+		cfg.SegmentSize = DefaultJournalSegmentSize
+	}
+	if cfg.Fsync == "" {
+		cfg.Fsync = FsyncInterval
+	}
+	if cfg.FsyncInterval <= 0 {
+		cfg.FsyncInterval = DefaultJournalFsyncInterval
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultJournalQueueSize
+	}
+	switch cfg.Fsync {
+	case FsyncAlways, FsyncInterval, FsyncNever:
+	default:
+		return nil, fmt.Errorf("unknown fsync policy %q", cfg.Fsync)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	j := &Journal{cfg: cfg}
+	segments, err := listSegments(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		if err := j.openSegment(0); err != nil {
+			return nil, err
+		}
+	} else {
+		last := segments[len(segments)-1]
+		info, err := inspectSegment(last.Path, last.BaseOffset)
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, ErrJournalCorrupt) {
+			// Drop the torn or damaged tail: it was never acknowledged as written.
+			if err := os.Truncate(last.Path, info.Size); err != nil {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		j.first = segments[0].BaseOffset
+		j.next = last.BaseOffset + uint64(info.Records)
+		j.base = last.BaseOffset
+		j.size = info.Size
+		if j.file, err = os.OpenFile(last.Path, os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	if err := j.enforceRetention(); err != nil {
+		_ = j.file.Close()
+		return nil, err
+	}
+
+	j.queued = make(chan struct{}, 1)
+	j.drained = sync.NewCond(&j.queueMu)
+	j.stop = make(chan struct{})
+	j.done = make(chan struct{})
+	go j.run()
+	return j, nil
+}
+
+// Append writes ev to the journal and returns its offset.
+func (j *Journal) Append(ev LifecycleEvent) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return 0, ErrJournalClosed
+	}
+	rec := JournalRecord{Offset: j.next, Time: time.Now(), Event: ev}
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		// Keep the event even when its data cannot be encoded.
+		rec.Event.Data = fmt.Sprintf("%v", ev.Data)
+		if payload, err = json.Marshal(rec); err != nil {
+			return 0, err
+		}
+	}
+	if len(payload) > journalMaxRecord {
+		return 0, fmt.Errorf("journal record of %d bytes is too large", len(payload))
+	}
+
+	if j.size > 0 && j.size+int64(journalHeaderSize+len(payload)) > j.cfg.SegmentSize {
+		if err := j.roll(); err != nil {
+			return 0, err
+		}
+	}
+	frame := make([]byte, journalHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.Checksum(payload, journalTable))
+	copy(frame[journalHeaderSize:], payload)
+	if n, err := j.file.Write(frame); err != nil {
+		if n > 0 {
+			// Drop the partial frame, or the records appended after it could not be read back.
+			if tErr := j.file.Truncate(j.size); tErr != nil {
+				return 0, fmt.Errorf("%w (dropping the partial record: %v)", err, tErr)
+			}
+		}
+		return 0, err
+	}
+	j.size += int64(len(frame))
+	j.next++
+	j.dirty = true
+	if j.cfg.Fsync == FsyncAlways {
+		if err := j.syncLocked(); err != nil {
+			return rec.Offset, err
+		}
+	}
+	return rec.Offset, nil
+}
+
+// AppendAsync queues ev to be appended by the writer goroutine of the journal, so that the
+// caller does not wait for the disk as long as the writer keeps up. Once QueueSize events are
+// queued, it waits for the writer to take them. Queued events are never dropped: Close writes
+// them before closing the journal. Errors of the writer are logged.
+func (j *Journal) AppendAsync(ev LifecycleEvent) error {
+	j.queueMu.Lock()
+	defer j.queueMu.Unlock()
+
+	for len(j.queue) >= j.cfg.QueueSize && !j.stopping {
+		j.drained.Wait()
+	}
+	if j.stopping {
+		return ErrJournalClosed
+	}
+	j.queue = append(j.queue, ev)
+	select {
+	case j.queued <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Read calls fn with every record from offset on, oldest first. It stops at the first error of fn.
+func (j *Journal) Read(offset uint64, fn func(JournalRecord) error) error {
+	return ReadJournal(j.cfg.Dir, offset, fn)
+}
+
+// Segments describes the files of the journal.
+func (j *Journal) Segments() ([]JournalSegment, error) { return InspectJournal(j.cfg.Dir) }
+
+// Dir returns the directory of the journal.
+func (j *Journal) Dir() string { return j.cfg.Dir }
+
+// FirstOffset returns the offset of the oldest record kept.
+func (j *Journal) FirstOffset() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.first
+}
+
+// NextOffset returns the offset the next record will get.
+func (j *Journal) NextOffset() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.next
+}
+
+// Accepts reports whether ev is recorded under the topics of the journal.
+func (j *Journal) Accepts(ev LifecycleEvent) bool {
+	if len(j.cfg.Topics) == 0 {
+		return ev.Type != EventProcessMetrics
+	}
+	for _, topic := range j.cfg.Topics {
+		if MatchTopic(topic, ev.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sync flushes the written records to the disk.
+func (j *Journal) Sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return ErrJournalClosed
+	}
+	return j.syncLocked()
+}
+
+// Close writes the events queued by AppendAsync, then flushes and closes the journal.
+func (j *Journal) Close() error {
+	j.queueMu.Lock()
+	stopping := j.stopping
+	j.stopping = true
+	j.drained.Broadcast()
+	j.queueMu.Unlock()
+	if !stopping {
+		close(j.stop)
+	}
+	<-j.done
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return nil
+	}
+	j.closed = true
+	err := j.syncLocked()
+	if cErr := j.file.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+func (j *Journal) syncLocked() error {
+	if !j.dirty {
+		return nil
+	}
+	j.dirty = false
+	return j.file.Sync()
+}
+
+// run writes the events queued by AppendAsync until Close is called, then writes the last ones.
+// It also flushes the journal every FsyncInterval under FsyncInterval, and deletes the segments
+// older than MaxAge as time goes by, even when nothing is written any more.
+func (j *Journal) run() {
+	defer close(j.done)
+
+	var syncC, retainC <-chan time.Time
+	if j.cfg.Fsync == FsyncInterval {
+		ticker := time.NewTicker(j.cfg.FsyncInterval)
+		defer ticker.Stop()
+		syncC = ticker.C
+	}
+	if j.cfg.MaxAge > 0 {
+		ticker := time.NewTicker(min(j.cfg.MaxAge, journalRetentionPeriod))
+		defer ticker.Stop()
+		retainC = ticker.C
+	}
+	for {
+		select {
+		case <-j.stop:
+			j.writeQueued()
+			return
+		case <-j.queued:
+			j.writeQueued()
+		case <-syncC:
+			j.mu.Lock()
+			if !j.closed {
+				_ = j.syncLocked()
+			}
+			j.mu.Unlock()
+		case <-retainC:
+			j.mu.Lock()
+			if !j.closed {
+				if err := j.enforceRetention(); err != nil {
+					l.Error(fmt.Sprintf("Fail to enforce the retention of journal %s: %v", j.cfg.Dir, err), map[string]interface{}{"context": "GoLife", "showData": false})
+				}
+			}
+			j.mu.Unlock()
+		}
+	}
+}
+
+// writeQueued appends the events queued by AppendAsync.
+func (j *Journal) writeQueued() {
+	j.queueMu.Lock()
+	events := j.queue
+	j.queue = nil
+	j.drained.Broadcast()
+	j.queueMu.Unlock()
+
+	for _, ev := range events {
+		if _, err := j.Append(ev); err != nil {
+			l.Error(fmt.Sprintf("Fail to journal event %s: %v", ev.Type, err), map[string]interface{}{"context": "GoLife", "showData": false})
+		}
+	}
+}
+
+// roll closes the active segment and starts a new one at the next offset. Callers must hold j.mu.
+func (j *Journal) roll() error {
+	if err := j.file.Sync(); err != nil {
+		return err
+	}
+	j.dirty = false
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	if err := j.openSegment(j.next); err != nil {
+		return err
+	}
+	return j.enforceRetention()
+}
+
+func (j *Journal) openSegment(base uint64) error {
+	file, err := os.OpenFile(segmentPath(j.cfg.Dir, base), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	j.file, j.base, j.size = file, base, 0
+	return nil
+}
+
+// enforceRetention deletes the oldest segments exceeding MaxSize or MaxAge. The active segment
+// is always kept. Callers must hold j.mu or own j exclusively.
+func (j *Journal) enforceRetention() error {
+	if j.cfg.MaxSize <= 0 && j.cfg.MaxAge <= 0 {
+		return nil
+	}
+	segments, err := listSegments(j.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, s := range segments {
+		total += s.Size
+	}
+	for _, s := range segments {
+		if s.BaseOffset == j.base {
+			break
+		}
+		expired := false
+		if j.cfg.MaxAge > 0 {
+			// A segment is as old as its last record, i.e. its last write.
+			expired = time.Since(s.modified) > j.cfg.MaxAge
+		}
+		if !expired && (j.cfg.MaxSize <= 0 || total <= j.cfg.MaxSize) {
+			break
+		}
+		if err := os.Remove(s.Path); err != nil {
+			return err
+		}
+		total -= s.Size
+	}
+	segments, err = listSegments(j.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	if len(segments) > 0 {
+		j.first = segments[0].BaseOffset
+	}
+	return nil
+}
+
+// segmentFile is a segment found in a journal directory.
+type segmentFile struct {
+	Path       string
+	BaseOffset uint64
+	Size       int64
+	modified   time.Time
+}
+
+func segmentPath(dir string, base uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", base, journalExt))
+}
+
+// listSegments returns the segments of dir sorted by base offset.
+func listSegments(dir string) ([]segmentFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]segmentFile, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, journalExt) {
+			continue
+		}
+		base, err := strconv.ParseUint(strings.TrimSuffix(name, journalExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segmentFile{Path: filepath.Join(dir, name), BaseOffset: base, Size: info.Size(), modified: info.ModTime()})
+	}
+	sort.Slice(segments, func(a, b int) bool { return segments[a].BaseOffset < segments[b].BaseOffset })
+	return segments, nil
+}
+
+// scanSegment calls fn with the records of the segment at path. It returns the size of the
+// valid records and, when it stopped early, why: io.ErrUnexpectedEOF for a record cut short,
+// ErrJournalCorrupt for a damaged one, or the error of fn.
+func scanSegment(path string, fn func(JournalRecord) error) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = file.Close() }()
+
+	r := bufio.NewReader(file)
+	var valid int64
+	header := make([]byte, journalHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return valid, nil
+			}
+			return valid, io.ErrUnexpectedEOF
+		}
+		length := binary.BigEndian.Uint32(header[0:4])
+		if length > journalMaxRecord {
+			return valid, fmt.Errorf("%w: record of %d bytes at byte %d of %s", ErrJournalCorrupt, length, valid, path)
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return valid, io.ErrUnexpectedEOF
+		}
+		if crc32.Checksum(payload, journalTable) != binary.BigEndian.Uint32(header[4:8]) {
+			return valid, fmt.Errorf("%w: checksum mismatch at byte %d of %s", ErrJournalCorrupt, valid, path)
+		}
+		var rec JournalRecord
+		if err := json.Unmarshal(payload, &rec); err != nil {
+			return valid, fmt.Errorf("%w: %v at byte %d of %s", ErrJournalCorrupt, err, valid, path)
+		}
+		if err := fn(rec); err != nil {
+			return valid, err
+		}
+		valid += int64(journalHeaderSize) + int64(length)
+	}
+}
+
+// inspectSegment describes the segment at path.
+func inspectSegment(path string, base uint64) (JournalSegment, error) {
+	info := JournalSegment{Path: path, BaseOffset: base}
+	size, err := scanSegment(path, func(rec JournalRecord) error {
+		if info.Records == 0 {
+			info.First = rec.Time
+		}
+		info.Last = rec.Time
+		info.Records++
+		return nil
+	})
+	info.Size = size
+	if err != nil {
+		info.Corrupt = err.Error()
+	}
+	return info, err
+}
+
+// InspectJournal describes the segments of the journal in dir without opening it for writing.
+func InspectJournal(dir string) ([]JournalSegment, error) {
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]JournalSegment, 0, len(segments))
+	for _, s := range segments {
+		info, _ := inspectSegment(s.Path, s.BaseOffset)
+		info.Size = s.Size
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// ReadJournal calls fn with every record of the journal in dir from offset on, oldest first,
+// without opening it for writing. A record cut short at the very end is the one being written
+// and ends the read; a damaged record anywhere else fails it.
+func ReadJournal(dir string, offset uint64, fn func(JournalRecord) error) error {
+	segments, err := listSegments(dir)
+	if err != nil {
+		return err
+	}
+	for i, s := range segments {
+		if i+1 < len(segments) && segments[i+1].BaseOffset <= offset {
+			continue
+		}
+		_, err := scanSegment(s.Path, func(rec JournalRecord) error {
+			if rec.Offset < offset {
+				return nil
+			}
+			return fn(rec)
+		})
+		if errors.Is(err, io.ErrUnexpectedEOF) && i == len(segments)-1 {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package internal
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// appendEvents appends n events to j and returns the size of its active segment after each one.
+func appendEvents(t *testing.T, j *Journal, n int) []int64 {
+	t.Helper()
+	sizes := make([]int64, 0, n)
+	for i := 0; i < n; i++ {
+		if _, err := j.Append(LifecycleEvent{ID: uint64(i + 1), Type: EventTriggered, Stage: "work", Event: "request", Data: i}); err != nil {
+			t.Fatalf("Append %d: %v", i, err)
+		}
+		info, err := os.Stat(segmentPath(j.Dir(), j.base))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, info.Size())
+	}
+	return sizes
+}
+
+// readOffsets returns the offsets of the records of the journal in dir.
+func readOffsets(t *testing.T, dir string) []uint64 {
+	t.Helper()
+	var offsets []uint64
+	if err := ReadJournal(dir, 0, func(rec JournalRecord) error {
+		offsets = append(offsets, rec.Offset)
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	}
+	return offsets
+}
+
+func TestJournalRecoversTornTail(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(path string, before, after int64) error // before and after the last record
+	}{
+		{
+			name:   "header cut short",
+			damage: func(path string, before, after int64) error { return os.Truncate(path, before+3) },
+		},
+		{
+			name:   "payload cut short",
+			damage: func(path string, before, after int64) error { return os.Truncate(path, after-2) },
+		},
+		{
+			name: "checksum mismatch",
+			damage: func(path string, before, after int64) error {
+				f, err := os.OpenFile(path, os.O_RDWR, 0)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				b := make([]byte, 1)
+				if _, err := f.ReadAt(b, after-2); err != nil {
+					return err
+				}
+				b[0] ^= 0xff
+				_, err = f.WriteAt(b, after-2)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := JournalConfig{Dir: t.TempDir(), Fsync: FsyncNever}
+			j, err := OpenJournal(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sizes := appendEvents(t, j, 5)
+			if err := j.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.damage(segmentPath(cfg.Dir, 0), sizes[3], sizes[4]); err != nil {
+				t.Fatal(err)
+			}
+
+			j, err = OpenJournal(cfg)
+			if err != nil {
+				t.Fatalf("reopening a torn journal: %v", err)
+			}
+			if next := j.NextOffset(); next != 4 {
+				t.Fatalf("NextOffset = %d, want 4", next)
+			}
+			if offset, err := j.Append(LifecycleEvent{Type: EventTriggered}); err != nil || offset != 4 {
+				t.Fatalf("Append after recovery = %d, %v", offset, err)
+			}
+			if err := j.Close(); err != nil {
+				t.Fatal(err)
+			}
+			offsets := readOffsets(t, cfg.Dir)
+			for i, offset := range offsets {
+				if offset != uint64(i) {
+					t.Fatalf("offsets after recovery = %v", offsets)
+				}
+			}
+			if len(offsets) != 5 {
+				t.Fatalf("%d records after recovery, want 5", len(offsets))
+			}
+		})
+	}
+}
+
+func TestJournalRetention(t *testing.T) {
+	const segments = 5
+	tests := []struct {
+		name      string
+		maxSize   int  // Newest segments fitting in MaxSize, 0 for no limit
+		maxAge    bool // Segments older than an hour expire
+		aged      int  // Oldest segments made two hours old
+		wantFirst uint64
+	}{
+		{name: "no limit", aged: segments, wantFirst: 0},
+		{name: "max size", maxSize: 2, wantFirst: 3},
+		{name: "max size of the active segment", maxSize: 1, wantFirst: segments - 1},
+		{name: "max age", maxAge: true, aged: 3, wantFirst: 3},
+		{name: "max age keeps the active segment", maxAge: true, aged: segments, wantFirst: segments - 1},
+		{name: "max age with fresh segments", maxAge: true, wantFirst: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := JournalConfig{Dir: t.TempDir(), Fsync: FsyncNever, SegmentSize: 1} // One record per segment
+			j, err := OpenJournal(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			appendEvents(t, j, segments)
+			if err := j.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			files, err := listSegments(cfg.Dir)
+			if err != nil || len(files) != segments {
+				t.Fatalf("%d segments written (%v), want %d", len(files), err, segments)
+			}
+			old := time.Now().Add(-2 * time.Hour)
+			for _, f := range files[:tt.aged] {
+				if err := os.Chtimes(f.Path, old, old); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.maxSize > 0 {
+				for _, f := range files[segments-tt.maxSize:] {
+					cfg.MaxSize += f.Size
+				}
+			}
+			if tt.maxAge {
+				cfg.MaxAge = time.Hour
+			}
+
+			j, err = OpenJournal(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = j.Close() }()
+			if first := j.FirstOffset(); first != tt.wantFirst {
+				t.Fatalf("FirstOffset = %d, want %d", first, tt.wantFirst)
+			}
+			offsets := readOffsets(t, cfg.Dir)
+			if len(offsets) != segments-int(tt.wantFirst) || offsets[0] != tt.wantFirst {
+				t.Fatalf("offsets kept = %v, want %d to %d", offsets, tt.wantFirst, segments-1)
+			}
+		})
+	}
+}
+
+func TestJournalAppendAsyncBackpressure(t *testing.T) {
+	j, err := OpenJournal(JournalConfig{Dir: t.TempDir(), Fsync: FsyncNever, QueueSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stall the writer: it takes the first event, then waits for the journal.
+	j.mu.Lock()
+	if err := j.AppendAsync(LifecycleEvent{Type: EventTriggered}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		j.queueMu.Lock()
+		taken := len(j.queue) == 0
+		j.queueMu.Unlock()
+		if taken {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the writer never took the queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := j.AppendAsync(LifecycleEvent{Type: EventTriggered}); err != nil {
+		t.Fatal(err)
+	}
+
+	queued := make(chan error, 1)
+	go func() { queued <- j.AppendAsync(LifecycleEvent{Type: EventTriggered}) }()
+	select {
+	case err := <-queued:
+		t.Fatalf("AppendAsync returned %v with a full queue", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	j.mu.Unlock()
+	select {
+	case err := <-queued:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("AppendAsync still waiting once the writer went on")
+	}
+
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if offsets := readOffsets(t, j.Dir()); len(offsets) != 3 {
+		t.Fatalf("offsets written = %v, want 3 records", offsets)
+	}
+	if err := j.AppendAsync(LifecycleEvent{Type: EventTriggered}); err != ErrJournalClosed {
+		t.Fatalf("AppendAsync after Close = %v, want %v", err, ErrJournalClosed)
+	}
+}
+
+func TestSetJournalFsyncAlways(t *testing.T) {
+	j, err := OpenJournal(JournalConfig{Dir: t.TempDir(), Fsync: FsyncAlways})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = j.Close() }()
+	lm := NewLifecycleManager(nil, nil, nil, nil, nil, nil)
+	lm.SetJournal(j)
+
+	// The event is on the disk once Publish returns, without waiting for the writer.
+	lm.Bus().Publish(LifecycleEvent{Type: EventTriggered, Stage: "boot", Event: "go"})
+	if next := j.NextOffset(); next != 1 {
+		t.Fatalf("NextOffset after Publish = %d, want 1", next)
+	}
+	if offsets := readOffsets(t, j.Dir()); len(offsets) != 1 {
+		t.Fatalf("offsets written = %v, want one record", offsets)
+	}
+}
@@ -1,12 +1,20 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	l "github.com/rafa-mori/logz"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
 type LifeCycleManager interface {
@@ -14,35 +22,73 @@ type LifeCycleManager interface {
 	Stop() error
 	Restart() error
 	Status() string
+	Snapshot() []ProcessStatus
 
 	RegisterProcess(name string, command string, args []string, restart bool, customFn func() error) error
+	AddProcess(proc IManagedProcess) error
+	GetProcess(name string) IManagedProcess
+	GetProcesses() []IManagedProcess
+	AddGoroutine(g IManagedGoroutine) error
+	GetGoroutine(name string) IManagedGoroutine
+	GetGoroutines() []IManagedGoroutine
+	AddUnit(u IManagedUnit) error
+	GetUnit(name string) IManagedUnit
+	GetUnits() []IManagedUnit
 	RegisterStage(stage IStage) error
 	RegisterEvent(event, stage string, callback func(interface{})) error
+	RegisterHandler(event, stage string, handler EventHandler) error
+	Use(mw ...EventMiddleware)
+	Subscribe(pattern string, handler EventHandler) (*EventSubscription, error)
+	Handles(stage, event string) bool
+	SetJournal(j *Journal)
+	Journal() *Journal
+	ReplayFrom(offset uint64) (uint64, error)
 
 	RemoveEvent(event, stage string) error
 	StopEvents() error
 
 	StartProcess(proc IManagedProcess) error
+	Pause(name string) error
+	Resume(name string) error
+	Reload(name string) error
 	StartAll() error
 	StopAll() error
+	StopProcesses() StopReport
+	SetDependencies(name string, deps ...string) error
+	SetParallelStart(parallel bool)
+	SetReadyTimeout(timeout time.Duration)
 
-	Trigger(stage, event string, data interface{})
+	Trigger(stage, event string, data interface{}) error
+	TriggerContext(ctx context.Context, stage, event string, data interface{}) error
+	TriggerAsync(stage, event string, data interface{}) *EventResult
+	TriggerAll(ctx context.Context, event string, data interface{}, stages ...string) ([]*EventResult, error)
 	DefineStage(name string) error
+	TransitionTo(stage string, data interface{}) error
+	AddGuard(stage string, guard TransitionGuard)
+	History() []StageTransition
 	GetCurrentStage() IStage
+	GetLastStage() IStage
 	GetStage(name string) IStage
 	GetStages() []IStage
 	UpdateStage(stage IStage) error
 
 	IsStageAllowed(stage string) bool
 
-	Send(stage string, msg string)
-	Receive(stage string) interface{}
+	Send(stage string, msg interface{}) error
+	SendMessage(ctx context.Context, msg Message) error
+	Receive(stage string) (Message, error)
+	ReceiveContext(ctx context.Context, stage string) (Message, error)
+	TryReceive(stage string) (Message, bool)
+	Request(ctx context.Context, stage string, body interface{}) (Message, error)
+	Pipe(ctx context.Context, from, to string, fn func(Message) (interface{}, error)) error
 	ListenForSignals() error
+	Bus() *EventBus
 
 	getStageIDByName(name string) string
 }
 type LifeCycle struct {
 	processes map[string]IManagedProcess
+	units     map[string]IManagedUnit // Units other than processes
 	stages    map[string]IStage
 	events    []IManagedProcessEvents
 
@@ -52,60 +98,273 @@ type LifeCycle struct {
 	lastEvent string
 	lastStage string
 
+	stagesMu sync.RWMutex // Guards stages, currentStage and lastStage
+
 	sigChan  chan os.Signal
 	doneChan chan struct{}
 
-	eventsMu  sync.Mutex
-	eventsCh  chan IManagedProcessEvents
-	triggerCh chan interface{}
+	eventsMu      sync.Mutex
+	eventsCh      chan IManagedProcessEvents
+	triggerCh     chan interface{}
+	middleware    []EventMiddleware // Wraps the event handlers of every stage
+	subscriptions subscriptionSet   // Handlers run in every stage
+
+	notifyMu      sync.Mutex
+	notifications map[string][]notification // State changes pending per process, see notifyProcess
+
+	transitionMu sync.Mutex
+	guards       []transitionGuard
+	history      []StageTransition
+	historyLimit int
+
+	parallelStart bool
+	readyTimeout  time.Duration
+
+	bus         *EventBus
+	journal     atomic.Pointer[Journal]
+	journalOnce sync.Once
 
 	mu sync.Mutex
 }
 
-func (lm *LifeCycle) Trigger(stageName, eventName string, data interface{}) {
-	stage := lm.GetStage(stageName)
-	if stage == nil {
-		l.Error(fmt.Sprintf("Stage %s not found when triggering event %s", stageName, eventName), nil)
-		return
+// Trigger runs the handler of event in stage and returns its error.
+func (lm *LifeCycle) Trigger(stageName, eventName string, data interface{}) error {
+	return lm.TriggerContext(context.Background(), stageName, eventName, data)
+}
+
+// TriggerContext runs the handlers of event in stage with ctx and returns their errors. The
+// handlers of the stage, wrapped by its middleware, run before the subscriptions of the manager;
+// the middleware of the manager wraps them all.
+func (lm *LifeCycle) TriggerContext(ctx context.Context, stageName, eventName string, data interface{}) error {
+	return lm.trigger(ctx, stageName, eventName, data, true)
+}
+
+// trigger runs the handlers of event in stage, followed by the subscriptions of the manager
+// when subscriptions is set. An empty stageName runs the subscriptions of the manager alone.
+// The handlers of the stage and the subscriptions of the manager each publish their own
+// EventTriggered, the latter without a stage, so that ReplayFrom runs each of them once.
+func (lm *LifeCycle) trigger(ctx context.Context, stageName, eventName string, data interface{}, subscriptions bool) error {
+	var stage IStage
+	var handlers []EventHandler
+	if stageName != "" {
+		if stage = lm.GetStage(stageName); stage == nil {
+			l.Error(fmt.Sprintf("Stage %s not found when triggering event %s", stageName, eventName), nil)
+			eventTriggersTotal.Inc(stageName, eventName, "not_found")
+			return fmt.Errorf("stage %s not found when triggering event %s", stageName, eventName)
+		}
+		if h := stage.Handler(eventName); h != nil {
+			handlers = append(handlers, h)
+		}
+	}
+	var subs []EventHandler
+	if subscriptions || stage == nil {
+		subs = lm.subscriptions.matching(eventName)
 	}
+	staged, subscribed := len(handlers) > 0, len(subs) > 0
+	handlers = append(handlers, subs...)
 
 	// Execute the event callback
-	callback := stage.GetEvent(eventName)
-	if callback == nil {
+	handler := sequenceHandlers(handlers)
+	if handler == nil {
 		l.Error(fmt.Sprintf("Event %s not found in stage %s", eventName, stageName), nil)
-		return
+		eventTriggersTotal.Inc(stageName, eventName, "not_found")
+		return fmt.Errorf("event %s not found in stage %s", eventName, stageName)
 	}
+	lm.eventsMu.Lock()
+	handler = ChainEvent(handler, lm.middleware...)
+	lm.eventsMu.Unlock()
 
 	// Log the trigger
 	l.Info(fmt.Sprintf("Triggering event %s in %s...", eventName, stageName), nil)
 
 	// Execute the callback
-	callback(data)
+	started := time.Now()
+	err := handler(ctx, EventCall{Stage: stageName, Event: eventName, Data: data})
+	eventTriggerSeconds.ObserveDuration(started, stageName)
+	if err != nil {
+		l.Error(fmt.Sprintf("Event %s in %s failed: %v", eventName, stageName, err), nil)
+		eventTriggersTotal.Inc(stageName, eventName, "error")
+	} else {
+		eventTriggersTotal.Inc(stageName, eventName, "ok")
+	}
+	if IsReplay(ctx) {
+		return err
+	}
+	if staged {
+		lm.bus.Publish(LifecycleEvent{Type: EventTriggered, Stage: stageName, Event: eventName, Data: data})
+	}
+	if subscribed {
+		lm.bus.Publish(LifecycleEvent{Type: EventTriggered, Event: eventName, Data: data})
+	}
 
 	// Send to the channel, if necessary
-	if lm.eventsCh != nil {
-		lm.eventsCh <- NewManagedProcessEvents(map[string]func(interface{}){eventName: callback}, lm.triggerCh)
+	if lm.eventsCh != nil && stage != nil {
+		lm.eventsCh <- NewManagedProcessEvents(map[string]func(interface{}){eventName: stage.GetEvent(eventName)}, lm.triggerCh)
 	}
+	return err
+}
+
+// Handles reports whether triggering event in stage runs a handler, one of the stage or a
+// subscription of the manager. An empty stage stands for the subscriptions of the manager alone.
+func (lm *LifeCycle) Handles(stageName, event string) bool {
+	if stageName != "" {
+		stage := lm.GetStage(stageName)
+		if stage == nil {
+			return false
+		}
+		if stage.EventExists(event) {
+			return true
+		}
+	}
+	return len(lm.subscriptions.matching(event)) > 0
+}
+
+// TriggerAsync runs the handler of event in stage on the worker pool of the stage, or on a new
+// goroutine when it has none, and returns its future at once, even when the pool is full. The
+// error of the future tells when the handler could not be queued.
+func (lm *LifeCycle) TriggerAsync(stageName, eventName string, data interface{}) *EventResult {
+	return lm.triggerAsync(context.Background(), stageName, eventName, data, true)
+}
+
+// TriggerAll triggers event asynchronously in stages, or in every stage handling it when none is
+// given, and waits for all the handlers. The subscriptions of the manager matching the event run
+// once, after the handlers of the stages. The handlers are cancelled when ctx is done. The
+// results follow the order of the stages, followed by the result of the subscriptions of the
+// manager, with an empty Stage, when some match; the error joins the failures of the handlers.
+func (lm *LifeCycle) TriggerAll(ctx context.Context, event string, data interface{}, stages ...string) ([]*EventResult, error) {
+	if len(stages) == 0 {
+		for _, stage := range lm.GetStages() {
+			if stage.EventExists(event) {
+				stages = append(stages, stage.Name())
+			}
+		}
+		sort.Strings(stages)
+	}
+
+	results := make([]*EventResult, 0, len(stages)+1)
+	for _, name := range stages {
+		if stage := lm.GetStage(name); stage != nil && !stage.EventExists(event) && lm.Handles(name, event) {
+			continue // Handled by the subscriptions of the manager only, which run below
+		}
+		results = append(results, lm.triggerAsync(ctx, name, event, data, false))
+	}
+	errs, err := waitResults(ctx, results)
+	if err != nil {
+		return results, err
+	}
+	if lm.Handles("", event) {
+		result := lm.triggerAsync(ctx, "", event, data, false)
+		results = append(results, result)
+		more, err := waitResults(ctx, []*EventResult{result})
+		if err != nil {
+			return results, err
+		}
+		errs = append(errs, more...)
+	}
+	return results, errors.Join(errs...)
+}
+
+// waitResults waits for the handlers of results and collects their errors. It returns the error
+// of ctx as soon as ctx is done.
+func waitResults(ctx context.Context, results []*EventResult) ([]error, error) {
+	var errs []error
+	for _, result := range results {
+		if err := result.Wait(ctx); err != nil {
+			if ctx.Err() != nil {
+				return errs, ctx.Err()
+			}
+			if result.Stage == "" {
+				errs = append(errs, fmt.Errorf("subscriptions: %w", err))
+			} else {
+				errs = append(errs, fmt.Errorf("stage %s: %w", result.Stage, err))
+			}
+		}
+	}
+	return errs, nil
+}
+
+func (lm *LifeCycle) triggerAsync(ctx context.Context, stageName, eventName string, data interface{}, subscriptions bool) *EventResult {
+	ctx, cancel := context.WithCancel(ctx)
+	result := newEventResult(stageName, eventName, cancel)
+	ctx = context.WithValue(ctx, eventResultKey{}, result)
+	task := func() {
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+			result.complete(err)
+		}()
+		err = lm.trigger(ctx, stageName, eventName, data, subscriptions)
+	}
+
+	if stage := lm.GetStage(stageName); stage != nil {
+		if _, pooled := stage.PoolStats(); pooled {
+			// Queued from a goroutine of its own, so that a full pool never blocks the caller. The
+			// wait ends with ctx, which Cancel cancels too.
+			go func() {
+				if err := stage.DispatchContext(ctx, task); err != nil {
+					result.complete(err)
+				}
+			}()
+			return result
+		}
+	}
+	go task()
+	return result
+}
+
+// Subscribe adds a handler for the events matching pattern (see MatchEvent) in every stage. It
+// runs after the handlers of the stage, in subscription order.
+func (lm *LifeCycle) Subscribe(pattern string, handler EventHandler) (*EventSubscription, error) {
+	sub, err := lm.subscriptions.add(pattern, handler)
+	if err != nil {
+		return nil, err
+	}
+	lm.bus.Publish(LifecycleEvent{Type: EventRegistered, Event: pattern})
+	return sub, nil
+}
+
+// Use adds middleware wrapping the event handlers of every stage. The first middleware is the
+// outermost one.
+func (lm *LifeCycle) Use(mw ...EventMiddleware) {
+	lm.eventsMu.Lock()
+	defer lm.eventsMu.Unlock()
+
+	lm.middleware = append(lm.middleware, mw...)
 }
 func (lm *LifeCycle) DefineStage(name string) error {
-	if id := lm.getStageIDByName(name); id == "" {
+	lm.stagesMu.Lock()
+	if id := lm.stageIDLocked(name); id == "" {
+		lm.stagesMu.Unlock()
 		return fmt.Errorf("stage %s not found", name)
 	} else {
 		lm.currentStage = id
 	}
+	lm.stagesMu.Unlock()
+	lm.bus.Publish(LifecycleEvent{Type: EventStageEntered, Stage: name})
 	return nil
 }
 func (lm *LifeCycle) IsStageAllowed(stage string) bool {
+	lm.stagesMu.RLock()
+	defer lm.stagesMu.RUnlock()
+
 	return lm.currentStage == stage
 }
 func (lm *LifeCycle) GetCurrentStage() IStage {
+	lm.stagesMu.RLock()
+	defer lm.stagesMu.RUnlock()
+
 	if s, ok := lm.stages[lm.currentStage]; ok {
 		return s
 	}
 	return nil
 }
 func (lm *LifeCycle) GetStage(name string) IStage {
-	if id := lm.getStageIDByName(name); id != "" {
+	lm.stagesMu.RLock()
+	defer lm.stagesMu.RUnlock()
+
+	if id := lm.stageIDLocked(name); id != "" {
 		if s, ok := lm.stages[id]; ok {
 			return s
 		}
@@ -113,6 +372,9 @@ func (lm *LifeCycle) GetStage(name string) IStage {
 	return nil
 }
 func (lm *LifeCycle) GetStages() []IStage {
+	lm.stagesMu.RLock()
+	defer lm.stagesMu.RUnlock()
+
 	stages := make([]IStage, 0, len(lm.stages))
 	for _, stage := range lm.stages {
 		stages = append(stages, stage)
@@ -120,7 +382,10 @@ func (lm *LifeCycle) GetStages() []IStage {
 	return stages
 }
 func (lm *LifeCycle) UpdateStage(stage IStage) error {
-	if id := lm.getStageIDByName(stage.Name()); id != "" {
+	lm.stagesMu.Lock()
+	defer lm.stagesMu.Unlock()
+
+	if id := lm.stageIDLocked(stage.Name()); id != "" {
 		if s, ok := lm.stages[id]; ok {
 			lm.stages[s.ID()] = stage
 			return nil
@@ -129,68 +394,136 @@ func (lm *LifeCycle) UpdateStage(stage IStage) error {
 	return fmt.Errorf("stage %s not found", stage.Name())
 }
 
-func (lm *LifeCycle) Send(stageName string, msg string) {
-	if id := lm.getStageIDByName(stageName); id != "" {
-		if s, ok := lm.stages[id]; ok {
-			s.Dispatch(func() {
-				sB := s.(*Stage)
-				if sB.OnEnterFn != nil {
-					s.OnEnter(sB.OnEnterFn)
-				}
-				if sB.OnExitFn != nil {
-					s.OnExit(sB.OnExitFn)
-				}
-				if sB.EventFns != nil {
-					for event, fn := range sB.EventFns {
-						s.OnEvent(event, fn)
-					}
-				}
-			})
+// Send puts msg in the mailbox of the stage, as a message from outside the lifecycle.
+func (lm *LifeCycle) Send(stageName string, msg interface{}) error {
+	return lm.SendMessage(context.Background(), Message{To: stageName, Body: msg})
+}
 
-			l.Info(msg, map[string]interface{}{
-				"context": "GoLife",
-				"stage":   stageName,
-				"message": msg,
-			})
-		}
-	} else {
-		l.Error(fmt.Sprintf("Stage %s not found", stageName), map[string]interface{}{
-			"context": "GoLife",
-			"stage":   stageName,
-		})
+// SendMessage puts msg in the mailbox of msg.To, waiting until ctx is done when the mailbox is
+// full and blocks. The ID and send time are filled in when missing.
+func (lm *LifeCycle) SendMessage(ctx context.Context, msg Message) error {
+	mailbox, err := lm.mailbox(msg.To)
+	if err != nil {
+		return err
+	}
+	if msg.ID == "" {
+		msg.ID = uuid.New().String()
 	}
+	if msg.SentAt.IsZero() {
+		msg.SentAt = time.Now()
+	}
+	if err := mailbox.Put(ctx, msg); err != nil {
+		return fmt.Errorf("sending to stage %s: %w", msg.To, err)
+	}
+	return nil
 }
-func (lm *LifeCycle) Receive(stageName string) interface{} {
-	fmt.Printf("Receiving message from %s...\n", stageName)
-	if id := lm.getStageIDByName(stageName); id != "" {
-		if s, ok := lm.stages[id]; ok {
-			fmt.Printf("Message received from %s!\n", stageName)
-			sB := s.(*Stage)
-			return sB.Data
-		} else {
-			fmt.Printf("No message received from %s!\n", stageName)
-			return nil
+
+// Receive takes the oldest message of the stage, waiting for one.
+func (lm *LifeCycle) Receive(stageName string) (Message, error) {
+	return lm.ReceiveContext(context.Background(), stageName)
+}
+
+// ReceiveContext takes the oldest message of the stage, waiting for one until ctx is done.
+func (lm *LifeCycle) ReceiveContext(ctx context.Context, stageName string) (Message, error) {
+	mailbox, err := lm.mailbox(stageName)
+	if err != nil {
+		return Message{}, err
+	}
+	return mailbox.Get(ctx)
+}
+
+// TryReceive takes the oldest message of the stage, if any, without waiting.
+func (lm *LifeCycle) TryReceive(stageName string) (Message, bool) {
+	mailbox, err := lm.mailbox(stageName)
+	if err != nil {
+		return Message{}, false
+	}
+	return mailbox.TryGet()
+}
+
+// Request sends body to the stage and waits, until ctx is done, for the receiver to answer with
+// Message.Reply. The reply carries the ID of the request as its CorrelationID.
+func (lm *LifeCycle) Request(ctx context.Context, stageName string, body interface{}) (Message, error) {
+	id := uuid.New().String()
+	reply := &replyPath{ch: make(chan Message, 1), request: id}
+	if err := lm.SendMessage(ctx, Message{ID: id, To: stageName, Body: body, reply: reply}); err != nil {
+		return Message{}, err
+	}
+	select {
+	case msg := <-reply.ch:
+		return msg, nil
+	case <-ctx.Done():
+		return Message{}, fmt.Errorf("waiting for the reply of stage %s: %w", stageName, ctx.Err())
+	}
+}
+
+// Pipe wires two stages: every message of from is passed to fn and what fn returns is sent to
+// to, correlated with the original message. A request keeps its reply along the pipeline, and
+// with an empty to the result answers it. A nil result is not forwarded; an error is logged and
+// the message dropped. Pipe runs until ctx is done or the mailbox of from is closed.
+func (lm *LifeCycle) Pipe(ctx context.Context, from, to string, fn func(Message) (interface{}, error)) error {
+	source, err := lm.mailbox(from)
+	if err != nil {
+		return err
+	}
+	if to != "" {
+		if _, err := lm.mailbox(to); err != nil {
+			return err
 		}
-	} else {
-		fmt.Printf("Stage %s not found!\n", stageName)
-		return nil
 	}
+	go func() {
+		for {
+			msg, err := source.Get(ctx)
+			if err != nil {
+				return
+			}
+			result, err := fn(msg)
+			if err != nil {
+				l.Error(fmt.Sprintf("Pipe %s -> %s failed on message %s: %v", from, to, msg.ID, err), map[string]interface{}{"context": "GoLife", "stage": from, "message": msg.ID, "showData": true})
+				continue
+			}
+			if result == nil {
+				continue
+			}
+			if to == "" {
+				if msg.ExpectsReply() {
+					_ = msg.Reply(result)
+				}
+				continue
+			}
+			out := Message{CorrelationID: msg.ID, From: from, To: to, Body: result, reply: msg.reply}
+			if err := lm.SendMessage(ctx, out); err != nil {
+				l.Error(fmt.Sprintf("Pipe %s -> %s cannot forward message %s: %v", from, to, msg.ID, err), map[string]interface{}{"context": "GoLife", "stage": from, "message": msg.ID, "showData": true})
+			}
+		}
+	}()
+	return nil
+}
+
+// mailbox returns the mailbox of the stage named stageName.
+func (lm *LifeCycle) mailbox(stageName string) (*Mailbox, error) {
+	stage := lm.GetStage(stageName)
+	if stage == nil {
+		return nil, fmt.Errorf("stage %s not found", stageName)
+	}
+	return stage.Mailbox(), nil
 }
 func (lm *LifeCycle) Start() error {
 	l.Info("Starting processes...", map[string]interface{}{
 		"context":  "GoLife",
 		"showData": false,
 	})
-	for _, proc := range lm.processes {
-		if err := proc.Start(); err != nil {
-			l.Error(fmt.Sprintf("Error starting process %s: %v", proc.String(), err), map[string]interface{}{
-				"context":  "GoLife",
-				"process":  proc.String(),
-				"showData": true,
-			})
-			return err
-		}
+	if err := lm.startOrdered(); err != nil {
+		return err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if err := lm.startUnits(); err != nil {
+		return err
 	}
+	lm.bus.Publish(LifecycleEvent{Type: EventLifecycleStarted})
 	l.Info(fmt.Sprintf("Processes started successfully!"), map[string]interface{}{
 		"context":   "GoLife",
 		"processes": len(lm.processes),
@@ -199,80 +532,247 @@ func (lm *LifeCycle) Start() error {
 	return nil
 }
 func (lm *LifeCycle) Stop() error {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
 	l.Info("Stopping processes...", map[string]interface{}{
 		"context":  "GoLife",
 		"showData": false,
 	})
-	for _, proc := range lm.processes {
-		l.Info(fmt.Sprintf("Stopping process %s...", proc.String()), map[string]interface{}{
-			"context":  "GoLife",
-			"process":  proc.String(),
-			"showData": false,
-		})
-		if err := proc.Stop(); err != nil {
-			l.Error(fmt.Sprintf("Error stopping process %s: %v", proc.String(), err), map[string]interface{}{
-				"context":  "GoLife",
-				"process":  proc.String(),
-				"showData": true,
-			})
-			return err
-		}
-	}
-	l.Info(fmt.Sprintf("Processes stopped successfully!"), map[string]interface{}{
+	report := lm.stopProcesses()
+	lm.bus.Publish(LifecycleEvent{Type: EventLifecycleStopped, Data: report})
+	l.Info("Processes stopped!", map[string]interface{}{
 		"context":   "GoLife",
-		"processes": len(lm.processes),
+		"processes": len(report),
 		"showData":  false,
 	})
-	return nil
+	return report.Err()
+}
+func (lm *LifeCycle) StopProcesses() StopReport {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	return lm.stopProcesses()
 }
 func (lm *LifeCycle) Restart() error {
-	for _, proc := range lm.processes {
+	lm.mu.Lock()
+	procs := make(map[string]IManagedProcess, len(lm.processes))
+	for name, proc := range lm.processes {
+		procs[name] = proc
+	}
+	units := make(map[string]IManagedUnit, len(lm.units))
+	for name, u := range lm.units {
+		units[name] = u
+	}
+	lm.mu.Unlock()
+
+	// Every process and unit gets its restart; one failing does not keep the others down.
+	var errs []error
+	for name, proc := range procs {
 		if err := proc.Restart(); err != nil {
-			return err
+			errs = append(errs, fmt.Errorf("restarting %s: %w", name, err))
 		}
 	}
-	return nil
+	for name, u := range units {
+		if err := u.Restart(); err != nil {
+			errs = append(errs, fmt.Errorf("restarting %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 func (lm *LifeCycle) Status() string {
-	lm.mu.Lock()
-	defer lm.mu.Unlock()
 	l.Info("Checking process status...", map[string]interface{}{"context": "GoLife", "showData": false})
 	var status string
-	for name, proc := range lm.processes {
-		l.Info(fmt.Sprintf("Process %s (PID %d) is running: %t", name, proc.Pid(), proc.IsRunning()), map[string]interface{}{
+	for _, proc := range lm.Snapshot() {
+		l.Info(fmt.Sprintf("Process %s (PID %d) is %s", proc.Name, proc.Pid, proc.State), map[string]interface{}{
 			"context":  "GoLife",
-			"process":  name,
-			"pid":      proc.Pid(),
-			"running":  proc.IsRunning(),
+			"process":  proc.Name,
+			"pid":      proc.Pid,
+			"state":    proc.State,
 			"showData": false,
 		})
-		status += fmt.Sprintf("Process %s (PID %d) is running: %t\n", name, proc.Pid(), proc.IsRunning())
+		status += proc.String() + "\n"
 	}
 	l.Info("Process status checked successfully!", map[string]interface{}{"context": "GoLife", "showData": false})
 	return status
 }
 
+// Snapshot returns the status of every unit, sorted by name.
+func (lm *LifeCycle) Snapshot() []ProcessStatus {
+	units := lm.GetUnits()
+	snapshot := make([]ProcessStatus, 0, len(units))
+	for _, u := range units {
+		snapshot = append(snapshot, u.Snapshot())
+	}
+	return snapshot
+}
+
 func (lm *LifeCycle) RegisterProcess(name string, command string, args []string, restart bool, customFn func() error) error {
 	lm.mu.Lock()
 	defer lm.mu.Unlock()
 
 	l.Info(fmt.Sprintf("Registering process %s...", name), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
-	lm.processes[name] = NewManagedProcess(name, command, args, restart, customFn)
+	proc := NewManagedProcess(name, command, args, false, customFn)
+	if restart {
+		proc.SetRestartPolicy(DefaultSupervisorPolicy(RestartAlways))
+	}
+	lm.watchProcess(proc)
+	lm.processes[name] = proc
+	lm.bus.Publish(LifecycleEvent{Type: EventProcessRegistered, Process: name})
 
 	l.Info(fmt.Sprintf("Process %s registered successfully!", name), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
 	return nil
 }
-func (lm *LifeCycle) RegisterStage(stage IStage) error {
+func (lm *LifeCycle) AddProcess(proc IManagedProcess) error {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	name := proc.GetName()
+	if name == "" {
+		return fmt.Errorf("process name is required")
+	}
+	if _, ok := lm.processes[name]; ok {
+		return fmt.Errorf("process %s already registered", name)
+	}
+	if u, ok := lm.units[name]; ok {
+		return fmt.Errorf("%s is already registered as a %s", name, u.Kind())
+	}
+	lm.processes[name] = proc
+	if cycle := findCycle(lm.processes); cycle != nil {
+		delete(lm.processes, name)
+		return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+	lm.watchProcess(proc)
+	lm.bus.Publish(LifecycleEvent{Type: EventProcessRegistered, Process: name})
+	l.Info(fmt.Sprintf("Process %s registered successfully!", name), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
+	return nil
+}
+func (lm *LifeCycle) GetProcess(name string) IManagedProcess {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if proc, ok := lm.processes[name]; ok {
+		return proc
+	}
+	return nil
+}
+
+// GetProcesses returns the registered processes sorted by name.
+func (lm *LifeCycle) GetProcesses() []IManagedProcess {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	procs := make([]IManagedProcess, 0, len(lm.processes))
+	for _, proc := range lm.processes {
+		procs = append(procs, proc)
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].GetName() < procs[j].GetName() })
+	return procs
+}
+
+// AddGoroutine registers g next to the processes: it is started after them and stopped before them.
+func (lm *LifeCycle) AddGoroutine(g IManagedGoroutine) error {
+	return lm.AddUnit(NewGoroutineUnit(g))
+}
+func (lm *LifeCycle) GetGoroutine(name string) IManagedGoroutine {
+	if g, ok := AsGoroutine(lm.GetUnit(name)); ok {
+		return g
+	}
+	return nil
+}
+
+// GetGoroutines returns the registered goroutines sorted by name.
+func (lm *LifeCycle) GetGoroutines() []IManagedGoroutine {
+	goroutines := make([]IManagedGoroutine, 0)
+	for _, u := range lm.GetUnits() {
+		if g, ok := AsGoroutine(u); ok {
+			goroutines = append(goroutines, g)
+		}
+	}
+	return goroutines
+}
+
+// AddUnit registers u. Process units join the processes and their dependency ordering, the
+// other units are started after the processes and stopped before them.
+func (lm *LifeCycle) AddUnit(u IManagedUnit) error {
+	if proc, ok := AsProcess(u); ok {
+		return lm.AddProcess(proc)
+	}
+
 	lm.mu.Lock()
 	defer lm.mu.Unlock()
 
-	if id := lm.getStageIDByName(stage.Name()); id != "" {
+	name := u.GetName()
+	if name == "" {
+		return fmt.Errorf("unit name is required")
+	}
+	if existing, ok := lm.units[name]; ok {
+		return fmt.Errorf("%s %s already registered", existing.Kind(), name)
+	}
+	if _, ok := lm.processes[name]; ok {
+		return fmt.Errorf("%s is already registered as a process", name)
+	}
+	lm.units[name] = u
+	u.OnStateChange(lm.publishStateChange)
+	lm.bus.Publish(LifecycleEvent{Type: EventProcessRegistered, Process: name, Data: u.Kind()})
+	l.Info(fmt.Sprintf("Unit %s (%s) registered successfully!", name, u.Kind()), map[string]interface{}{"context": "GoLife", "process": name, "kind": u.Kind(), "showData": false})
+	return nil
+}
+
+// GetUnit returns the unit registered as name, processes included, or nil.
+func (lm *LifeCycle) GetUnit(name string) IManagedUnit {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if proc, ok := lm.processes[name]; ok {
+		return NewProcessUnit(proc)
+	}
+	if u, ok := lm.units[name]; ok {
+		return u
+	}
+	return nil
+}
+
+// GetUnits returns every registered unit, processes included, sorted by name.
+func (lm *LifeCycle) GetUnits() []IManagedUnit {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	units := make([]IManagedUnit, 0, len(lm.processes)+len(lm.units))
+	for _, proc := range lm.processes {
+		units = append(units, NewProcessUnit(proc))
+	}
+	for _, u := range lm.units {
+		units = append(units, u)
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].GetName() < units[j].GetName() })
+	return units
+}
+
+// startUnits starts the units other than processes that are not running. Callers must hold lm.mu.
+func (lm *LifeCycle) startUnits() error {
+	for name, u := range lm.units {
+		if u.IsRunning() {
+			continue
+		}
+		if err := u.Start(); err != nil {
+			return fmt.Errorf("starting %s: %w", name, err)
+		}
+	}
+	return nil
+}
+func (lm *LifeCycle) RegisterStage(stage IStage) error {
+	lm.stagesMu.Lock()
+	if id := lm.stageIDLocked(stage.Name()); id != "" {
+		lm.stagesMu.Unlock()
 		l.Error(fmt.Sprintf("Stage %s already registered", stage.Name()), map[string]interface{}{"context": "GoLife", "stage": stage.Name(), "showData": false})
 		return nil
 	}
 
 	l.Info(fmt.Sprintf("Registering stage %s...", stage.Name()), map[string]interface{}{"context": "GoLife", "stage": stage.Name(), "showData": false})
 	lm.stages[stage.ID()] = stage
+	lm.stagesMu.Unlock()
+	lm.bus.Publish(LifecycleEvent{Type: EventStageRegistered, Stage: stage.Name()})
 	l.Info(fmt.Sprintf("Stage %s registered successfully!", stage.Name()), map[string]interface{}{"context": "GoLife", "stage": stage.Name(), "showData": false})
 
 	return nil
@@ -289,6 +789,7 @@ func (lm *LifeCycle) RegisterEvent(event, stageName string, callback func(interf
 
 	// Add the event to the stage
 	stage.OnEvent(event, callback)
+	lm.bus.Publish(LifecycleEvent{Type: EventRegistered, Stage: stageName, Event: event})
 
 	// Log success
 	l.Info(fmt.Sprintf("Event %s registered in %s successfully!", event, stageName), map[string]interface{}{
@@ -300,11 +801,46 @@ func (lm *LifeCycle) RegisterEvent(event, stageName string, callback func(interf
 	return nil
 }
 
+// RegisterHandler sets the handler of event in stage. Its error is returned by Trigger.
+func (lm *LifeCycle) RegisterHandler(event, stageName string, handler EventHandler) error {
+	lm.eventsMu.Lock()
+	defer lm.eventsMu.Unlock()
+
+	stage := lm.GetStage(stageName)
+	if stage == nil {
+		return fmt.Errorf("stage %s not found when registering event %s", stageName, event)
+	}
+	stage.Handle(event, handler)
+	lm.bus.Publish(LifecycleEvent{Type: EventRegistered, Stage: stageName, Event: event})
+	l.Info(fmt.Sprintf("Event %s registered in %s successfully!", event, stageName), map[string]interface{}{
+		"context": "GoLife",
+		"event":   event,
+		"stage":   stageName,
+	})
+
+	return nil
+}
+
+// RemoveEvent removes the handler of event from stage, set by RegisterEvent or RegisterHandler.
 func (lm *LifeCycle) RemoveEvent(event, stageName string) error {
 	l.Info(fmt.Sprintf("Removing event %s from %s...", event, stageName), map[string]interface{}{"context": "GoLife", "event": event, "stage": stageName, "showData": false})
+	stage := lm.GetStage(stageName)
+	if stage == nil {
+		return fmt.Errorf("stage %s not found when removing event %s", stageName, event)
+	}
+	if stage.RemoveEvent(event) {
+		lm.bus.Publish(LifecycleEvent{Type: EventRemoved, Stage: stageName, Event: event})
+		l.Info(fmt.Sprintf("Event %s removed from %s successfully!", event, stageName), map[string]interface{}{"context": "GoLife", "event": event, "stage": stageName, "showData": false})
+		return nil
+	}
+
+	lm.eventsMu.Lock()
+	defer lm.eventsMu.Unlock()
+
 	for i, e := range lm.events {
 		if e.Event() == event {
 			lm.events = append(lm.events[:i], lm.events[i+1:]...)
+			lm.bus.Publish(LifecycleEvent{Type: EventRemoved, Stage: stageName, Event: event})
 			l.Info(fmt.Sprintf("Event %s removed from %s successfully!", event, stageName), map[string]interface{}{"context": "GoLife", "event": event, "stage": stageName, "showData": false})
 			return nil
 		}
@@ -324,18 +860,232 @@ func (lm *LifeCycle) StopEvents() error {
 	return nil
 }
 func (lm *LifeCycle) StartAll() error {
+	if err := lm.startOrdered(); err != nil {
+		return err
+	}
+
 	lm.mu.Lock()
 	defer lm.mu.Unlock()
+
+	if err := lm.startUnits(); err != nil {
+		return err
+	}
+	lm.bus.Publish(LifecycleEvent{Type: EventLifecycleStarted})
+	l.Info(fmt.Sprintf("%d Processes started successfully!", len(lm.processes)), map[string]interface{}{"context": "GoLife", "processes": len(lm.processes), "showData": false})
+	return nil
+}
+
+// startOrdered starts processes level by level so that every process starts after the ones it
+// depends on. Processes of the same level start concurrently when parallel start is enabled.
+// It works on a copy of the registered processes, so lm.mu is not held while the processes
+// start and become ready; callers must not hold it either.
+func (lm *LifeCycle) startOrdered() error {
+	lm.mu.Lock()
+	procs := make(map[string]IManagedProcess, len(lm.processes))
 	for name, proc := range lm.processes {
-		l.Info(fmt.Sprintf("Starting %s...", name), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
-		if err := lm.StartProcess(proc); err != nil {
-			l.Error(fmt.Sprintf("Error starting %s: %v", name, err), map[string]interface{}{"context": "GoLife", "process": name, "showData": true})
-			return err
+		procs[name] = proc
+	}
+	parallel, readyTimeout := lm.parallelStart, lm.readyTimeout
+	lm.mu.Unlock()
+
+	levels, err := dependencyLevels(procs)
+	if err != nil {
+		return err
+	}
+	required := make(map[string]bool)
+	for _, proc := range procs {
+		for _, dep := range proc.GetDependsOn() {
+			required[dep] = true
+		}
+	}
+	for _, level := range levels {
+		if !parallel || len(level) == 1 {
+			for _, name := range level {
+				if err := lm.startNamed(procs[name]); err != nil {
+					return err
+				}
+			}
+		} else {
+			errCh := make(chan error, len(level))
+			for _, name := range level {
+				go func(proc IManagedProcess) { errCh <- lm.startNamed(proc) }(procs[name])
+			}
+			var errs []error
+			for range level {
+				if err := <-errCh; err != nil {
+					errs = append(errs, err)
+				}
+			}
+			if len(errs) > 0 {
+				return errors.Join(errs...)
+			}
+		}
+
+		// Dependents only start once the processes they need report ready.
+		for _, name := range level {
+			if !required[name] {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+			err := procs[name].WaitReady(ctx)
+			cancel()
+			if err != nil {
+				return err
+			}
 		}
 	}
-	l.Info(fmt.Sprintf("%b Processes started successfully!", len(lm.processes)), map[string]interface{}{"context": "GoLife", "processes": len(lm.processes), "showData": false})
 	return nil
 }
+
+// SetReadyTimeout bounds how long StartAll waits for a process to become ready before
+// starting the processes that depend on it.
+func (lm *LifeCycle) SetReadyTimeout(timeout time.Duration) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.readyTimeout = timeout
+}
+
+// watchProcess publishes the state and health changes of proc on the bus and forwards the
+// health changes to the lifecycle events.
+func (lm *LifeCycle) watchProcess(proc IManagedProcess) {
+	proc.OnStateChange(lm.publishStateChange)
+	proc.OnReload(func(ev ReloadEvent) {
+		lm.bus.Publish(LifecycleEvent{Type: EventProcessReloaded, Process: ev.Process, Data: ev})
+	})
+	proc.OnMetrics(func(sample ResourceSample) {
+		lm.bus.Publish(LifecycleEvent{Type: EventProcessMetrics, Process: sample.Process, Data: sample})
+	})
+	proc.OnHealthChange(func(change HealthChange) {
+		l.Info(fmt.Sprintf("Health of %s changed: %s (ready: %t)", change.Process, change.Current.Status, change.Current.Ready), map[string]interface{}{"context": "GoLife", "process": change.Process, "status": change.Current.Status, "ready": change.Current.Ready, "showData": false})
+		lm.bus.Publish(LifecycleEvent{Type: EventHealthChanged, Process: change.Process, Data: change})
+		lm.notify(HealthChangedEvent, change)
+	})
+}
+
+// publishStateChange publishes the process event matching a state change and triggers it in
+// the stages.
+func (lm *LifeCycle) publishStateChange(change ProcessStateChange) {
+	var evType EventType
+	switch change.Current {
+	case StateRunning:
+		evType = EventProcessStarted
+		if change.Previous == StatePaused {
+			evType = EventProcessResumed
+		}
+	case StatePaused:
+		evType = EventProcessPaused
+	case StateBackoff, StateExited:
+		evType = EventProcessExited
+	case StateFatal:
+		evType = EventProcessFatal
+	case StateStopped:
+		evType = EventProcessStopped
+	default:
+		return
+	}
+	lm.bus.Publish(LifecycleEvent{Type: evType, Process: change.Process, Data: change})
+
+	// Stages see the change as the event process.<name>.<state>, e.g. process.api.exited. The
+	// handlers run on the queue of the process, so they may act on the process.
+	lm.notifyProcess(change.Process, "process."+change.Process+strings.TrimPrefix(string(evType), "process"), change)
+}
+
+// notification is a process event waiting to be triggered in the stages.
+type notification struct {
+	event string
+	data  interface{}
+}
+
+// notifyProcess queues event for the stages behind the pending events of process. One
+// goroutine per process drains the queue while it is not empty, so the handlers see the changes
+// of a process one at a time and in order.
+func (lm *LifeCycle) notifyProcess(process, event string, data interface{}) {
+	lm.notifyMu.Lock()
+	defer lm.notifyMu.Unlock()
+
+	if lm.notifications == nil {
+		lm.notifications = make(map[string][]notification)
+	}
+	pending, draining := lm.notifications[process]
+	lm.notifications[process] = append(pending, notification{event: event, data: data})
+	if !draining {
+		go lm.drainNotifications(process)
+	}
+}
+
+// drainNotifications triggers the queued events of process until none is left.
+func (lm *LifeCycle) drainNotifications(process string) {
+	for {
+		lm.notifyMu.Lock()
+		pending := lm.notifications[process]
+		if len(pending) == 0 {
+			delete(lm.notifications, process)
+			lm.notifyMu.Unlock()
+			return
+		}
+		next := pending[0]
+		lm.notifications[process] = pending[1:]
+		lm.notifyMu.Unlock()
+
+		lm.notify(next.event, next.data)
+	}
+}
+
+// Bus returns the event bus every lifecycle operation publishes to.
+func (lm *LifeCycle) Bus() *EventBus { return lm.bus }
+
+// notify triggers event in every stage that handles it, then runs the subscriptions of the
+// manager matching it once. Failures are logged by trigger.
+func (lm *LifeCycle) notify(event string, data interface{}) {
+	for _, stage := range lm.GetStages() {
+		if stage.EventExists(event) {
+			_ = lm.trigger(context.Background(), stage.Name(), event, data, false)
+		}
+	}
+	if lm.Handles("", event) {
+		_ = lm.trigger(context.Background(), "", event, data, false)
+	}
+}
+
+func (lm *LifeCycle) startNamed(proc IManagedProcess) error {
+	name := proc.GetName()
+	if proc.IsRunning() {
+		return nil
+	}
+	l.Info(fmt.Sprintf("Starting %s...", name), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
+	if err := lm.StartProcess(proc); err != nil {
+		return fmt.Errorf("starting %s: %w", name, err)
+	}
+	return nil
+}
+
+// SetDependencies declares that name must start after deps and stop before them.
+// It fails without changing anything when the new edges would create a cycle.
+func (lm *LifeCycle) SetDependencies(name string, deps ...string) error {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	proc, ok := lm.processes[name]
+	if !ok {
+		return fmt.Errorf("process %s not found", name)
+	}
+	previous := proc.GetDependsOn()
+	proc.SetDependsOn(deps)
+	if cycle := findCycle(lm.processes); cycle != nil {
+		proc.SetDependsOn(previous)
+		return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// SetParallelStart makes StartAll start the processes of the same dependency level concurrently.
+func (lm *LifeCycle) SetParallelStart(parallel bool) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.parallelStart = parallel
+}
 func (lm *LifeCycle) StartProcess(proc IManagedProcess) error {
 	if err := proc.Start(); err != nil {
 		l.Error(fmt.Sprintf("Error starting %s: %v", proc.String(), err), map[string]interface{}{"context": "GoLife", "process": proc.String(), "showData": true})
@@ -344,49 +1094,174 @@ func (lm *LifeCycle) StartProcess(proc IManagedProcess) error {
 	l.Info(fmt.Sprintf("%s started successfully!", proc.String()), map[string]interface{}{"context": "GoLife", "process": proc.String(), "showData": false})
 	return nil
 }
+
+// Pause suspends the unit registered as name; it must be Pausable.
+func (lm *LifeCycle) Pause(name string) error {
+	u, err := lm.pausable(name)
+	if err != nil {
+		return err
+	}
+	return u.Pause()
+}
+
+// Resume lets the paused unit registered as name run again.
+func (lm *LifeCycle) Resume(name string) error {
+	u, err := lm.pausable(name)
+	if err != nil {
+		return err
+	}
+	return u.Resume()
+}
+
+// Reload reloads the unit registered as name without restarting it; it must be Reloadable.
+func (lm *LifeCycle) Reload(name string) error {
+	u := lm.GetUnit(name)
+	if u == nil {
+		return fmt.Errorf("process %s not found", name)
+	}
+	r, ok := u.(Reloadable)
+	if !ok {
+		return fmt.Errorf("%s %s cannot be reloaded", u.Kind(), name)
+	}
+	return r.Reload()
+}
+func (lm *LifeCycle) pausable(name string) (Pausable, error) {
+	u := lm.GetUnit(name)
+	if u == nil {
+		return nil, fmt.Errorf("process %s not found", name)
+	}
+	p, ok := u.(Pausable)
+	if !ok {
+		return nil, fmt.Errorf("%s %s cannot be paused", u.Kind(), name)
+	}
+	return p, nil
+}
+
+// StopAll stops every unit and unregisters the ones that stopped, then shuts the worker pools of
+// the stages down, waiting up to DefaultPoolShutdownTimeout for their queued tasks.
 func (lm *LifeCycle) StopAll() error {
 	lm.mu.Lock()
-	defer lm.mu.Unlock()
-	for name, proc := range lm.processes {
-		if err := proc.Stop(); err != nil {
-			l.Error(fmt.Sprintf("Error stopping %s: %v", name, err), map[string]interface{}{"context": "GoLife", "process": name, "showData": true})
-			return err
-		} else {
-			l.Info(fmt.Sprintf("%s stopped successfully!", name), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
-			delete(lm.processes, name)
+	report := lm.stopProcesses()
+	for _, res := range report {
+		if res.Err == nil {
+			delete(lm.processes, res.Name)
+			delete(lm.units, res.Name)
 		}
 	}
-	l.Info(fmt.Sprintf("%b Processes stopped successfully!", len(lm.processes)), map[string]interface{}{"context": "GoLife", "processes": len(lm.processes), "showData": false})
-	return nil
+	lm.mu.Unlock()
+
+	poolErr := lm.shutdownPools()
+	lm.bus.Publish(LifecycleEvent{Type: EventLifecycleStopped, Data: report})
+	l.Info(fmt.Sprintf("%d Processes stopped!", len(report)), map[string]interface{}{"context": "GoLife", "processes": len(report), "showData": false})
+	return errors.Join(report.Err(), poolErr)
 }
-func (lm *LifeCycle) ListenForSignals() error {
-	select {
-	case ev := <-lm.eventsCh:
-		if ev != nil {
-			for _, event := range lm.events {
-				event.RegisterEvent(event.Event(), func(data interface{}) {
-					event.Trigger(data.(string), event.Event(), data)
-				})
-			}
+
+// shutdownPools shuts the worker pools of the stages down concurrently.
+func (lm *LifeCycle) shutdownPools() error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultPoolShutdownTimeout)
+	defer cancel()
+
+	stages := lm.GetStages()
+	errCh := make(chan error, len(stages))
+	for _, stage := range stages {
+		go func(stage IStage) { errCh <- stage.ShutdownPool(ctx) }(stage)
+	}
+	var errs []error
+	for range stages {
+		if err := <-errCh; err != nil {
+			l.Error(fmt.Sprintf("Error shutting a worker pool down: %v", err), map[string]interface{}{"context": "GoLife", "showData": true})
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopProcesses stops the units other than processes concurrently, then runs the stop sequence of every process
+// in reverse dependency order, stopping the processes of a same level concurrently, and collects
+// the outcome of each one instead of aborting on the first failure. Callers must hold lm.mu.
+func (lm *LifeCycle) stopProcesses() StopReport {
+	levels, err := dependencyLevels(lm.processes)
+	if err != nil {
+		levels = [][]string{make([]string, 0, len(lm.processes))}
+		for name := range lm.processes {
+			levels[0] = append(levels[0], name)
+		}
+	}
+
+	report := make(StopReport, 0, len(lm.processes)+len(lm.units))
+	results := make(chan StopResult, len(lm.units))
+	for name, u := range lm.units {
+		go func(name string, u IManagedUnit) {
+			started := time.Now()
+			outcome, err := u.Shutdown()
+			results <- StopResult{Name: name, Outcome: outcome, Duration: time.Since(started), Err: err}
+		}(name, u)
+	}
+	for range lm.units {
+		report = append(report, <-results)
+	}
+	for i := len(levels) - 1; i >= 0; i-- {
+		results := make(chan StopResult, len(levels[i]))
+		for _, name := range levels[i] {
+			go func(name string, proc IManagedProcess) {
+				started := time.Now()
+				outcome, err := proc.Shutdown()
+				results <- StopResult{Name: name, Outcome: outcome, Duration: time.Since(started), Err: err}
+			}(name, lm.processes[name])
 		}
-	case <-lm.doneChan:
-		if len(lm.processes) > 0 {
-			startAllErr := lm.StartAll()
-			if startAllErr != nil {
-				return startAllErr
+		for range levels[i] {
+			res := <-results
+			if res.Err != nil {
+				l.Error(fmt.Sprintf("Error stopping %s: %v", res.Name, res.Err), map[string]interface{}{"context": "GoLife", "process": res.Name, "showData": true})
+			} else {
+				l.Info(fmt.Sprintf("%s stopped (%s)", res.Name, res.Outcome), map[string]interface{}{"context": "GoLife", "process": res.Name, "outcome": res.Outcome, "duration": res.Duration, "showData": false})
 			}
+			report = append(report, res)
 		}
-	case <-lm.sigChan:
-		stopAllErr := lm.StopAll()
-		if stopAllErr != nil {
+	}
+	return report
+}
+
+// ListenForSignals serves the lifecycle channels until the lifecycle is done: triggered events are
+// drained from eventsCh, a value on doneChan starts every process, closing doneChan ends the loop
+// and SIGINT/SIGTERM stop every process.
+func (lm *LifeCycle) ListenForSignals() error {
+	eventsCh := lm.eventsCh
+	for {
+		select {
+		case _, ok := <-eventsCh:
+			if !ok {
+				eventsCh = nil
+			}
+		case _, ok := <-lm.doneChan:
+			if !ok {
+				return nil
+			}
+			lm.mu.Lock()
+			hasUnits := len(lm.processes) > 0 || len(lm.units) > 0
+			lm.mu.Unlock()
+			if hasUnits {
+				if startAllErr := lm.StartAll(); startAllErr != nil {
+					l.Error(fmt.Sprintf("Error starting processes: %v", startAllErr), map[string]interface{}{"context": "GoLife", "showData": true})
+				}
+			}
+		case sig := <-lm.sigChan:
+			lm.bus.Publish(LifecycleEvent{Type: EventSignalReceived, Data: sig.String()})
+			stopAllErr := lm.StopAll()
+			close(lm.doneChan)
 			return stopAllErr
 		}
-		close(lm.doneChan)
 	}
-
-	return nil
 }
 func (lm *LifeCycle) getStageIDByName(name string) string {
+	lm.stagesMu.RLock()
+	defer lm.stagesMu.RUnlock()
+
+	return lm.stageIDLocked(name)
+}
+
+// stageIDLocked returns the ID of the stage named name, or "". Callers must hold lm.stagesMu.
+func (lm *LifeCycle) stageIDLocked(name string) string {
 	for id, stage := range lm.stages {
 		if stage.Name() == name {
 			return id
@@ -400,6 +1275,9 @@ func NewLifecycleManager(processes map[string]IManagedProcess, stages map[string
 	if stages != nil {
 		stg = stages
 	}
+	if processes == nil {
+		processes = make(map[string]IManagedProcess)
+	}
 	if sigChan == nil {
 		sigChan = make(chan os.Signal, 2)
 	}
@@ -415,6 +1293,7 @@ func NewLifecycleManager(processes map[string]IManagedProcess, stages map[string
 
 	mgr := LifeCycle{
 		processes: processes,
+		units:     make(map[string]IManagedUnit),
 		stages:    stg,
 		sigChan:   sigChan,
 		doneChan:  doneChan,
@@ -422,6 +1301,14 @@ func NewLifecycleManager(processes map[string]IManagedProcess, stages map[string
 		eventsMu:  sync.Mutex{},
 		eventsCh:  eventsCh,
 		mu:        sync.Mutex{},
+
+		historyLimit: DefaultTransitionHistory,
+		readyTimeout: 60 * time.Second,
+
+		bus: NewEventBus(DefaultEventHistory),
+	}
+	for _, proc := range processes {
+		mgr.watchProcess(proc)
 	}
 
 	signal.Notify(mgr.sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	lg "github.com/rafa-mori/logz"
+	"golang.org/x/sys/unix"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CgroupParent is the cgroup v2 directory the per-process groups are created in. The parent must
+// be able to enable the memory, cpu and pids controllers for its children, as a group delegated
+// by systemd does.
+//
+// When empty, golife moves itself into a golife-supervisor leaf of its own cgroup and creates the
+// groups next to it: cgroup v2 only enables controllers below a group holding no processes. Any
+// other process left in that cgroup still prevents it, and the parent must then be set.
+var CgroupParent = os.Getenv("GOLIFE_CGROUP_PARENT")
+
+// supervisorCgroup is the leaf golife moves into when the groups are created in its own cgroup.
+const supervisorCgroup = "golife-supervisor"
+
+var (
+	ownParentOnce sync.Once
+	ownParent     string
+	ownParentErr  error
+)
+
+// ResourceLimits caps what a managed process and its children may use. Zero means no limit.
+//
+// The limits are enforced by a cgroup v2 group created for the process. When that is not
+// possible the process gets rlimits instead: MemoryMax bounds its address space and PidsMax the
+// processes of its user, while the CPU limits are not enforced. OpenFiles is always an rlimit.
+// Rlimits are set right after the process starts, so its first instructions run without them,
+// and RLIMIT_NPROC counts every process of the user, not only those of the managed process.
+type ResourceLimits struct {
+	MemoryMax int64   `json:"memoryMax,omitempty" yaml:"memoryMax,omitempty"` // Bytes (memory.max)
+	CPUQuota  float64 `json:"cpuQuota,omitempty" yaml:"cpuQuota,omitempty"`   // CPUs, 0.5 is half a CPU (cpu.max)
+	CPUWeight int     `json:"cpuWeight,omitempty" yaml:"cpuWeight,omitempty"` // 1-10000, 100 is the default share (cpu.weight)
+	PidsMax   int     `json:"pidsMax,omitempty" yaml:"pidsMax,omitempty"`     // Tasks (pids.max)
+	OpenFiles uint64  `json:"openFiles,omitempty" yaml:"openFiles,omitempty"` // File descriptors (RLIMIT_NOFILE)
+}
+
+// needsCgroup reports whether some limit is enforced through a cgroup.
+func (rl ResourceLimits) needsCgroup() bool {
+	return rl.MemoryMax > 0 || rl.CPUQuota > 0 || rl.CPUWeight > 0 || rl.PidsMax > 0
+}
+
+// controllers returns the cgroup controllers the limits need.
+func (rl ResourceLimits) controllers() []string {
+	var out []string
+	if rl.MemoryMax > 0 {
+		out = append(out, "memory")
+	}
+	if rl.CPUQuota > 0 || rl.CPUWeight > 0 {
+		out = append(out, "cpu")
+	}
+	if rl.PidsMax > 0 {
+		out = append(out, "pids")
+	}
+	return out
+}
+
+// applyRlimits sets the rlimits of a started process: the open files limit always, the memory
+// and pids limits when no cgroup enforces them. Go cannot run code between fork and exec, so
+// they are set with prlimit once the process runs.
+func (rl ResourceLimits) applyRlimits(name string, pid int, inCgroup bool) {
+	set := func(resource int, value uint64, what string) {
+		limit := unix.Rlimit{Cur: value, Max: value}
+		if err := unix.Prlimit(pid, resource, &limit, nil); err != nil {
+			lg.Warn(fmt.Sprintf("Cannot limit the %s of %s: %v", what, name, err), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
+		}
+	}
+	if rl.OpenFiles > 0 {
+		set(unix.RLIMIT_NOFILE, rl.OpenFiles, "open files")
+	}
+	if inCgroup {
+		return
+	}
+	if rl.MemoryMax > 0 {
+		set(unix.RLIMIT_AS, uint64(rl.MemoryMax), "address space")
+	}
+	if rl.PidsMax > 0 {
+		set(unix.RLIMIT_NPROC, uint64(rl.PidsMax), "processes")
+	}
+	if rl.CPUQuota > 0 || rl.CPUWeight > 0 {
+		lg.Warn(fmt.Sprintf("CPU limits of %s need a cgroup and are not enforced", name), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
+	}
+}
+
+// processCgroup is the cgroup v2 group of a managed process.
+type processCgroup struct {
+	dir string
+}
+
+// createCgroup creates, or reuses, the group of the process named name and writes its limits.
+func createCgroup(name string, limits ResourceLimits) (*processCgroup, error) {
+	if _, err := os.Stat(filepath.Join(CgroupRoot, "cgroup.controllers")); err != nil {
+		return nil, fmt.Errorf("cgroup v2 is not mounted at %s", CgroupRoot)
+	}
+	parent, err := cgroupParent()
+	if err != nil {
+		return nil, err
+	}
+	if err := enableControllers(parent, limits.controllers()); err != nil {
+		return nil, err
+	}
+
+	cg := &processCgroup{dir: filepath.Join(parent, "golife-"+strings.ReplaceAll(name, "/", "_"))}
+	if err := os.Mkdir(cg.dir, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, err
+	}
+	files := map[string]string{}
+	if limits.MemoryMax > 0 {
+		files["memory.max"] = strconv.FormatInt(limits.MemoryMax, 10)
+	}
+	if limits.CPUQuota > 0 {
+		files["cpu.max"] = fmt.Sprintf("%d 100000", int64(limits.CPUQuota*100000))
+	}
+	if limits.CPUWeight > 0 {
+		files["cpu.weight"] = strconv.Itoa(limits.CPUWeight)
+	}
+	if limits.PidsMax > 0 {
+		files["pids.max"] = strconv.Itoa(limits.PidsMax)
+	}
+	for file, value := range files {
+		if err := os.WriteFile(filepath.Join(cg.dir, file), []byte(value), 0644); err != nil {
+			cg.remove()
+			return nil, fmt.Errorf("writing %s: %w", file, err)
+		}
+	}
+	return cg, nil
+}
+
+// cgroupParent returns CgroupParent or, when it is empty, the cgroup of this process once this
+// process has left it for its supervisor leaf.
+func cgroupParent() (string, error) {
+	if CgroupParent != "" {
+		return CgroupParent, nil
+	}
+	ownParentOnce.Do(func() {
+		ownParent, ownParentErr = moveToLeaf(filepath.Join(CgroupRoot, cgroupPath("self")), os.Getpid())
+	})
+	return ownParent, ownParentErr
+}
+
+// moveToLeaf moves pid from the cgroup dir into its supervisor leaf and returns dir. A pid
+// already in a supervisor leaf stays there.
+func moveToLeaf(dir string, pid int) (string, error) {
+	if filepath.Base(dir) == supervisorCgroup {
+		return filepath.Dir(dir), nil
+	}
+	leaf := filepath.Join(dir, supervisorCgroup)
+	if err := os.Mkdir(leaf, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644); err != nil {
+		return "", fmt.Errorf("moving into %s: %w", leaf, err)
+	}
+	return dir, nil
+}
+
+// enableControllers makes the controllers available to the children of parent.
+func enableControllers(parent string, controllers []string) error {
+	data, err := os.ReadFile(filepath.Join(parent, "cgroup.subtree_control"))
+	if err != nil {
+		return err
+	}
+	enabled := strings.Fields(string(data))
+	for _, c := range controllers {
+		if slices.Contains(enabled, c) {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+"+c), 0644); err != nil {
+			return fmt.Errorf("enabling the %s controller in %s: %w", c, parent, err)
+		}
+	}
+	return nil
+}
+
+// open returns a descriptor of the group, used to start a process inside it.
+func (cg *processCgroup) open() (*os.File, error) {
+	return os.Open(cg.dir)
+}
+
+// oomKills returns the number of processes of the group killed by the OOM killer.
+func (cg *processCgroup) oomKills() uint64 {
+	f, err := os.Open(filepath.Join(cg.dir, "memory.events"))
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if v, ok := strings.CutPrefix(sc.Text(), "oom_kill "); ok {
+			n, _ := strconv.ParseUint(v, 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// remove deletes the group once it is empty; a group still holding processes is left in place.
+func (cg *processCgroup) remove() {
+	_ = os.Remove(cg.dir)
+}
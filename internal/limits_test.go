@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResourceLimitsControllers(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      ResourceLimits
+		needsCgroup bool
+		controllers []string
+	}{
+		{name: "none", limits: ResourceLimits{}},
+		{name: "open files only", limits: ResourceLimits{OpenFiles: 1024}},
+		{name: "memory", limits: ResourceLimits{MemoryMax: 1 << 20}, needsCgroup: true, controllers: []string{"memory"}},
+		{name: "cpu quota", limits: ResourceLimits{CPUQuota: 0.5}, needsCgroup: true, controllers: []string{"cpu"}},
+		{name: "cpu weight", limits: ResourceLimits{CPUWeight: 200}, needsCgroup: true, controllers: []string{"cpu"}},
+		{
+			name:        "everything",
+			limits:      ResourceLimits{MemoryMax: 1 << 20, CPUQuota: 1, CPUWeight: 50, PidsMax: 10, OpenFiles: 64},
+			needsCgroup: true,
+			controllers: []string{"memory", "cpu", "pids"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.needsCgroup(); got != tt.needsCgroup {
+				t.Errorf("needsCgroup = %t, want %t", got, tt.needsCgroup)
+			}
+			if got := tt.limits.controllers(); !reflect.DeepEqual(got, tt.controllers) {
+				t.Errorf("controllers = %v, want %v", got, tt.controllers)
+			}
+		})
+	}
+}
+
+// fakeCgroupRoot points CgroupRoot and CgroupParent at a temporary directory laid out like a
+// cgroup v2 hierarchy and returns the parent.
+func fakeCgroupRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	parent := filepath.Join(root, "golife.service")
+	if err := os.Mkdir(parent, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		filepath.Join(root, "cgroup.controllers"):       "cpu memory pids",
+		filepath.Join(parent, "cgroup.subtree_control"): "cpu memory pids",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldRoot, oldParent := CgroupRoot, CgroupParent
+	CgroupRoot, CgroupParent = root, parent
+	t.Cleanup(func() { CgroupRoot, CgroupParent = oldRoot, oldParent })
+	return parent
+}
+
+func TestCreateCgroup(t *testing.T) {
+	parent := fakeCgroupRoot(t)
+	cg, err := createCgroup("web/api", ResourceLimits{MemoryMax: 64 << 20, CPUQuota: 1.5, CPUWeight: 200, PidsMax: 32, OpenFiles: 256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(parent, "golife-web_api"); cg.dir != want {
+		t.Fatalf("dir = %s, want %s", cg.dir, want)
+	}
+	for file, want := range map[string]string{
+		"memory.max": "67108864",
+		"cpu.max":    "150000 100000",
+		"cpu.weight": "200",
+		"pids.max":   "32",
+	} {
+		got, err := os.ReadFile(filepath.Join(cg.dir, file))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q (%v), want %q", file, got, err, want)
+		}
+	}
+
+	// A group left by a previous run is reused.
+	if _, err := createCgroup("web/api", ResourceLimits{PidsMax: 8}); err != nil {
+		t.Fatalf("reusing the group: %v", err)
+	}
+}
+
+func TestCreateCgroupWithoutCgroupV2(t *testing.T) {
+	fakeCgroupRoot(t)
+	CgroupRoot = t.TempDir()
+	if _, err := createCgroup("api", ResourceLimits{PidsMax: 8}); err == nil {
+		t.Fatal("createCgroup succeeded without a cgroup v2 hierarchy")
+	}
+}
+
+func TestMoveToLeaf(t *testing.T) {
+	dir := t.TempDir()
+	parent, err := moveToLeaf(dir, 4242)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent != dir {
+		t.Fatalf("parent = %s, want %s", parent, dir)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, supervisorCgroup, "cgroup.procs")); string(got) != "4242" {
+		t.Fatalf("cgroup.procs of the leaf = %q, want 4242", got)
+	}
+
+	// A process already in a supervisor leaf stays there.
+	parent, err = moveToLeaf(filepath.Join(dir, supervisorCgroup), 4242)
+	if err != nil || parent != dir {
+		t.Fatalf("moveToLeaf from the leaf = %s, %v; want %s", parent, err, dir)
+	}
+}
@@ -0,0 +1,325 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	l "github.com/rafa-mori/logz"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTailLines is the number of output lines kept in memory per process.
+const DefaultTailLines = 1000
+
+// LogConfig defines how the output of a managed process is captured.
+type LogConfig struct {
+	Dir       string        // Directory of the log files; empty keeps the output in memory only
+	MaxSize   int64         // Rotate a file once it grows past this many bytes (0 = never)
+	MaxAge    time.Duration // Rotate a file once it is older than this (0 = never)
+	MaxFiles  int           // Rotated files kept per stream (default 5)
+	TailLines int           // Lines kept in the in-memory ring buffer (default DefaultTailLines)
+	Forward   bool          // Forward every line to logz with the process name as context
+}
+
+// LogLine is a single captured line of output.
+type LogLine struct {
+	Seq    uint64    `json:"seq"`
+	Stream string    `json:"stream"`
+	Time   time.Time `json:"time"`
+	Text   string    `json:"text"`
+}
+
+// RingBuffer keeps the last lines written by a process, numbered so readers can follow it.
+type RingBuffer struct {
+	mu    sync.Mutex
+	lines []LogLine
+	size  int
+	next  uint64
+}
+
+// NewRingBuffer creates a ring buffer holding up to size lines.
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = DefaultTailLines
+	}
+	return &RingBuffer{lines: make([]LogLine, 0, size), size: size, next: 1}
+}
+
+// Append stores a line and returns its sequence number.
+func (r *RingBuffer) Append(stream, text string) LogLine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	line := LogLine{Seq: r.next, Stream: stream, Time: time.Now(), Text: text}
+	r.next++
+	if len(r.lines) == r.size {
+		copy(r.lines, r.lines[1:])
+		r.lines[len(r.lines)-1] = line
+	} else {
+		r.lines = append(r.lines, line)
+	}
+	return line
+}
+
+// Tail returns the last n lines of stream ("" for every stream).
+func (r *RingBuffer) Tail(stream string, n int) []LogLine {
+	lines, _ := r.Lines(stream, 0, n)
+	return lines
+}
+
+// Since returns the buffered lines with a sequence number greater than seq and the sequence
+// number to pass on the next call.
+func (r *RingBuffer) Since(seq uint64) ([]LogLine, uint64) {
+	return r.Lines("", seq, 0)
+}
+
+// Lines returns the last n lines (every one when n <= 0) of stream ("" for every stream) with a
+// sequence number greater than seq, and the sequence number to pass on the next call. Both come
+// from the same snapshot, so a reader following the buffer sees every line once.
+func (r *RingBuffer) Lines(stream string, seq uint64, n int) ([]LogLine, uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]LogLine, 0)
+	for i := len(r.lines) - 1; i >= 0 && r.lines[i].Seq > seq && (n <= 0 || len(out) < n); i-- {
+		if stream == "" || r.lines[i].Stream == stream {
+			out = append(out, r.lines[i])
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, r.next - 1
+}
+
+// RotatingWriter writes to a file and rotates it by size and age.
+type RotatingWriter struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxAge   time.Duration
+	maxFiles int
+	file     *os.File
+	size     int64
+	opened   time.Time
+}
+
+// NewRotatingWriter opens (or creates) path for appending.
+func NewRotatingWriter(path string, maxSize int64, maxAge time.Duration, maxFiles int) (*RotatingWriter, error) {
+	if maxFiles <= 0 {
+		maxFiles = 5
+	}
+	w := &RotatingWriter{path: path, maxSize: maxSize, maxAge: maxAge, maxFiles: maxFiles}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if (w.maxSize > 0 && w.size+int64(len(p)) > w.maxSize && w.size > 0) || (w.maxAge > 0 && time.Since(w.opened) > w.maxAge) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.file.Close()
+}
+
+func (w *RotatingWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	w.opened = time.Now()
+	return nil
+}
+
+// rotate shifts name.N to name.N+1, dropping the oldest, and starts a fresh file.
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	_ = os.Remove(fmt.Sprintf("%s.%d", w.path, w.maxFiles))
+	for i := w.maxFiles - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+	}
+	if err := os.Rename(w.path, w.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return w.open()
+}
+
+// outputCapture collects the stdout and stderr of a process.
+type outputCapture struct {
+	mu     sync.Mutex
+	name   string
+	config LogConfig
+	ring   *RingBuffer
+	files  []io.Closer // Log files and line writers of the current run
+}
+
+func newOutputCapture(name string, config LogConfig) *outputCapture {
+	return &outputCapture{name: name, config: config, ring: NewRingBuffer(config.TailLines)}
+}
+
+// configure replaces the capture settings; the ring buffer is resized only when its size changes.
+func (c *outputCapture) configure(config LogConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if config.TailLines != c.config.TailLines {
+		c.ring = NewRingBuffer(config.TailLines)
+	}
+	c.config = config
+}
+
+// writers returns the writers to attach to a new run of the process named name.
+func (c *outputCapture) writers(name string) (io.Writer, io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closeFiles()
+	c.name = name
+	return c.writer("stdout"), c.writer("stderr")
+}
+
+// writer builds the writer of one stream. Callers must hold c.mu.
+func (c *outputCapture) writer(stream string) io.Writer {
+	name, config, ring := c.name, c.config, c.ring
+	lw := &lineWriter{emit: func(text string) {
+		ring.Append(stream, text)
+		if config.Forward {
+			ctx := map[string]interface{}{"context": name, "process": name, "stream": stream, "showData": false}
+			if stream == "stderr" {
+				l.Warn(text, ctx)
+			} else {
+				l.Info(text, ctx)
+			}
+		}
+	}}
+	c.files = append(c.files, lw)
+	if config.Dir == "" {
+		return lw
+	}
+	fw, err := NewRotatingWriter(filepath.Join(config.Dir, fmt.Sprintf("%s.%s.log", name, stream)), config.MaxSize, config.MaxAge, config.MaxFiles)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error opening %s log of %s: %v", stream, name, err), map[string]interface{}{"context": "GoLife", "process": name, "showData": true})
+		return lw
+	}
+	c.files = append(c.files, fw)
+	return io.MultiWriter(lw, &logFileWriter{file: fw, name: name, stream: stream})
+}
+
+// buffer returns the ring buffer currently in use.
+func (c *outputCapture) buffer() *RingBuffer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ring
+}
+
+// close releases the log files of the last run.
+func (c *outputCapture) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closeFiles()
+}
+
+func (c *outputCapture) closeFiles() {
+	for _, f := range c.files {
+		_ = f.Close()
+	}
+	c.files = nil
+}
+
+// maxLineLength bounds a captured line; longer lines are emitted in pieces of this size.
+const maxLineLength = 64 << 10
+
+// lineWriter splits a byte stream in lines; a trailing partial line waits for its newline.
+type lineWriter struct {
+	mu      sync.Mutex
+	pending bytes.Buffer
+	emit    func(string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending.Write(p)
+	for {
+		buf := w.pending.Bytes()
+		i := bytes.IndexByte(buf, '\n')
+		switch {
+		case i >= 0 && i <= maxLineLength:
+			w.emit(strings.TrimRight(string(buf[:i]), "\r"))
+			w.pending.Next(i + 1)
+		case len(buf) >= maxLineLength:
+			w.emit(string(buf[:maxLineLength]))
+			w.pending.Next(maxLineLength)
+		default:
+			return len(p), nil
+		}
+	}
+}
+
+// Close emits the trailing partial line, if any.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.pending.Len() > 0 {
+		w.emit(strings.TrimRight(w.pending.String(), "\r"))
+		w.pending.Reset()
+	}
+	return nil
+}
+
+// logFileWriter writes to a log file without ever failing, so a full disk or a removed directory
+// does not stop the capture in memory. The first error of a series is logged.
+type logFileWriter struct {
+	file    io.Writer
+	name    string
+	stream  string
+	failing bool
+}
+
+func (w *logFileWriter) Write(p []byte) (int, error) {
+	if _, err := w.file.Write(p); err != nil {
+		if !w.failing {
+			l.Error(fmt.Sprintf("Error writing %s log of %s: %v", w.stream, w.name, err), map[string]interface{}{"context": "GoLife", "process": w.name, "showData": true})
+		}
+		w.failing = true
+	} else {
+		w.failing = false
+	}
+	return len(p), nil
+}
@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRingBufferLines(t *testing.T) {
+	r := NewRingBuffer(5)
+	for i := 1; i <= 7; i++ {
+		stream := "stdout"
+		if i%2 == 0 {
+			stream = "stderr"
+		}
+		r.Append(stream, fmt.Sprintf("line %d", i))
+	}
+
+	tests := []struct {
+		name   string
+		stream string
+		seq    uint64
+		n      int
+		want   []uint64 // Sequence numbers of the returned lines
+	}{
+		{name: "everything buffered", want: []uint64{3, 4, 5, 6, 7}},
+		{name: "last lines", n: 2, want: []uint64{6, 7}},
+		{name: "after a sequence number", seq: 5, want: []uint64{6, 7}},
+		{name: "after a dropped line", seq: 1, want: []uint64{3, 4, 5, 6, 7}},
+		{name: "nothing new", seq: 7, want: []uint64{}},
+		{name: "one stream", stream: "stderr", want: []uint64{4, 6}},
+		{name: "last line of one stream", stream: "stdout", n: 1, want: []uint64{7}},
+		{name: "one stream after a sequence number", stream: "stdout", seq: 4, n: 10, want: []uint64{5, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, next := r.Lines(tt.stream, tt.seq, tt.n)
+			got := make([]uint64, 0, len(lines))
+			for _, line := range lines {
+				got = append(got, line.Seq)
+				if line.Text != fmt.Sprintf("line %d", line.Seq) {
+					t.Errorf("line %d has text %q", line.Seq, line.Text)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %v, want %v", got, tt.want)
+			}
+			if next != 7 {
+				t.Errorf("next = %d, want 7", next)
+			}
+		})
+	}
+}
+
+func TestRotatingWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api.stdout.log")
+	w, err := NewRotatingWriter(path, 10, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n", "eeee\n", "ffff\n", "gggggggggggggggg\n"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"api.stdout.log":   "gggggggggggggggg\n", // Larger than maxSize, written whole to a fresh file
+		"api.stdout.log.1": "eeee\nffff\n",
+		"api.stdout.log.2": "cccc\ndddd\n",
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		t.Errorf("%d files after rotation, want %d", len(entries), len(want))
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+
+	// Reopening appends to the current file.
+	w, err = NewRotatingWriter(path, 100, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("hhhh\n"))
+	_ = w.Close()
+	if got, _ := os.ReadFile(path); string(got) != "gggggggggggggggg\nhhhh\n" {
+		t.Errorf("reopened file = %q", got)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	long := strings.Repeat("x", maxLineLength)
+	tests := []struct {
+		name   string
+		writes []string
+		want   []string // Lines emitted before Close
+		close  []string // Lines emitted by Close
+	}{
+		{name: "one line per write", writes: []string{"a\n", "b\n"}, want: []string{"a", "b"}},
+		{name: "several lines in a write", writes: []string{"a\nb\nc\n"}, want: []string{"a", "b", "c"}},
+		{name: "line split across writes", writes: []string{"he", "llo", " world\nne"}, want: []string{"hello world"}, close: []string{"ne"}},
+		{name: "crlf", writes: []string{"a\r\nb\r"}, want: []string{"a"}, close: []string{"b"}},
+		{name: "empty lines", writes: []string{"\n\na\n"}, want: []string{"", "", "a"}},
+		{name: "line at the cap", writes: []string{long + "\n"}, want: []string{long}},
+		{name: "line over the cap", writes: []string{long, "yz\n"}, want: []string{long, "yz"}},
+		{name: "line much longer than the cap", writes: []string{long + long + "end"}, want: []string{long, long}, close: []string{"end"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			w := &lineWriter{emit: func(text string) { got = append(got, text) }}
+			for _, p := range tt.writes {
+				if n, err := w.Write([]byte(p)); n != len(p) || err != nil {
+					t.Fatalf("Write = %d, %v", n, err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("lines = %q, want %q", got, tt.want)
+			}
+			got = got[:0]
+			_ = w.Close()
+			if len(got) != len(tt.close) || (len(got) > 0 && !reflect.DeepEqual(got, tt.close)) {
+				t.Fatalf("lines on Close = %q, want %q", got, tt.close)
+			}
+		})
+	}
+}
+
+func TestOutputCaptureSurvivesLogFileErrors(t *testing.T) {
+	c := newOutputCapture("api", LogConfig{Dir: t.TempDir()})
+	stdout, _ := c.writers("api")
+	defer c.close()
+
+	if _, err := stdout.Write([]byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+	// Break the log file under the writer; the lines must still reach the ring buffer.
+	for _, f := range c.files {
+		if fw, ok := f.(*RotatingWriter); ok {
+			_ = fw.Close()
+		}
+	}
+	if n, err := stdout.Write([]byte("after\n")); n != len("after\n") || err != nil {
+		t.Fatalf("Write with a broken log file = %d, %v", n, err)
+	}
+
+	lines := c.buffer().Tail("stdout", 0)
+	if len(lines) != 2 || lines[0].Text != "before" || lines[1].Text != "after" {
+		t.Fatalf("buffered lines = %+v", lines)
+	}
+}
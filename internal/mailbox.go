@@ -0,0 +1,194 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy tells what a full mailbox does with a new message.
+type OverflowPolicy string
+
+const (
+	OverflowBlock      OverflowPolicy = "block"       // The sender waits for room
+	OverflowDropOldest OverflowPolicy = "drop-oldest" // The oldest message is dropped
+	OverflowError      OverflowPolicy = "error"       // The sender gets ErrMailboxFull
+)
+
+// ErrMailboxClosed is returned when sending to, or receiving from an empty, closed mailbox.
+var ErrMailboxClosed = errors.New("mailbox is closed")
+
+// Message is what stages exchange through their mailboxes.
+type Message struct {
+	ID            string      `json:"id"`
+	CorrelationID string      `json:"correlationId,omitempty"` // ID of the request a reply answers
+	From          string      `json:"from,omitempty"`          // Sending stage, empty from outside the lifecycle
+	To            string      `json:"to"`
+	Body          interface{} `json:"body,omitempty"`
+	SentAt        time.Time   `json:"sentAt"`
+
+	reply *replyPath // Set on the messages sent by Request and kept along a Pipe
+}
+
+// replyPath routes the reply of a request back to its sender, however many stages the request
+// was piped through.
+type replyPath struct {
+	ch      chan Message
+	request string // ID of the request
+	from    string // Sender of the request
+}
+
+// ExpectsReply reports whether the sender of m waits for a Reply.
+func (m Message) ExpectsReply() bool { return m.reply != nil }
+
+// Reply answers a message sent by Request, or forwarded from one by Pipe. The reply carries the
+// ID of the original request as its CorrelationID. Only the first reply is delivered.
+func (m Message) Reply(body interface{}) error {
+	if m.reply == nil {
+		return fmt.Errorf("message %s expects no reply", m.ID)
+	}
+	select {
+	case m.reply.ch <- Message{ID: uuid.New().String(), CorrelationID: m.reply.request, From: m.To, To: m.reply.from, Body: body, SentAt: time.Now()}:
+		return nil
+	default:
+		return fmt.Errorf("message %s was already answered", m.ID)
+	}
+}
+
+// Mailbox is a bounded FIFO queue of messages.
+type Mailbox struct {
+	mu       sync.Mutex
+	capacity int
+	policy   OverflowPolicy
+	queue    []Message
+	changed  chan struct{} // Closed and replaced whenever a message is put or taken
+	closed   bool
+	dropped  uint64
+}
+
+// NewMailbox creates a mailbox holding up to capacity messages (DefaultMailboxSize when <= 0).
+func NewMailbox(capacity int, policy OverflowPolicy) *Mailbox {
+	if capacity <= 0 {
+		capacity = DefaultMailboxSize
+	}
+	if policy == "" {
+		policy = OverflowBlock
+	}
+	return &Mailbox{capacity: capacity, policy: policy, changed: make(chan struct{})}
+}
+
+// Put adds msg, applying the overflow policy when the mailbox is full. A blocked Put returns
+// when ctx is done.
+func (mb *Mailbox) Put(ctx context.Context, msg Message) error {
+	mb.mu.Lock()
+	for {
+		if mb.closed {
+			mb.mu.Unlock()
+			return ErrMailboxClosed
+		}
+		if len(mb.queue) < mb.capacity {
+			break
+		}
+		switch mb.policy {
+		case OverflowDropOldest:
+			mb.queue = mb.queue[1:]
+			mb.dropped++
+			continue
+		case OverflowError:
+			mb.mu.Unlock()
+			return ErrMailboxFull
+		}
+		changed := mb.changed
+		mb.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+		mb.mu.Lock()
+	}
+	mb.queue = append(mb.queue, msg)
+	mb.notify()
+	mb.mu.Unlock()
+	return nil
+}
+
+// Get removes and returns the oldest message, waiting for one until ctx is done. The messages
+// left in a closed mailbox are still returned; then Get fails with ErrMailboxClosed.
+func (mb *Mailbox) Get(ctx context.Context) (Message, error) {
+	mb.mu.Lock()
+	for len(mb.queue) == 0 {
+		if mb.closed {
+			mb.mu.Unlock()
+			return Message{}, ErrMailboxClosed
+		}
+		changed := mb.changed
+		mb.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-changed:
+		}
+		mb.mu.Lock()
+	}
+	msg := mb.take()
+	mb.mu.Unlock()
+	return msg, nil
+}
+
+// TryGet removes and returns the oldest message, if any, without waiting.
+func (mb *Mailbox) TryGet() (Message, bool) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if len(mb.queue) == 0 {
+		return Message{}, false
+	}
+	return mb.take(), true
+}
+
+// Close rejects new messages and wakes the waiting senders and receivers.
+func (mb *Mailbox) Close() {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if !mb.closed {
+		mb.closed = true
+		mb.notify()
+	}
+}
+
+func (mb *Mailbox) Len() int {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	return len(mb.queue)
+}
+func (mb *Mailbox) Cap() int               { return mb.capacity }
+func (mb *Mailbox) Policy() OverflowPolicy { return mb.policy }
+
+// Dropped returns the number of messages discarded by OverflowDropOldest.
+func (mb *Mailbox) Dropped() uint64 {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	return mb.dropped
+}
+
+// take pops the oldest message. Callers must hold mb.mu.
+func (mb *Mailbox) take() Message {
+	msg := mb.queue[0]
+	mb.queue[0] = Message{}
+	mb.queue = mb.queue[1:]
+	mb.notify()
+	return msg
+}
+
+// notify wakes everyone waiting for a change. Callers must hold mb.mu.
+func (mb *Mailbox) notify() {
+	close(mb.changed)
+	mb.changed = make(chan struct{})
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMailboxOverflow(t *testing.T) {
+	tests := []struct {
+		policy      OverflowPolicy
+		wantErr     error
+		wantBodies  []int
+		wantDropped uint64
+	}{
+		{policy: OverflowBlock, wantErr: context.DeadlineExceeded, wantBodies: []int{1, 2}},
+		{policy: OverflowDropOldest, wantBodies: []int{2, 3}, wantDropped: 1},
+		{policy: OverflowError, wantErr: ErrMailboxFull, wantBodies: []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			mb := NewMailbox(2, tt.policy)
+			for i := 1; i <= 2; i++ {
+				if err := mb.Put(context.Background(), Message{Body: i}); err != nil {
+					t.Fatalf("Put %d: %v", i, err)
+				}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if err := mb.Put(ctx, Message{Body: 3}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Put on a full mailbox: err = %v, want %v", err, tt.wantErr)
+			}
+			if mb.Len() != 2 {
+				t.Fatalf("Len = %d, want 2", mb.Len())
+			}
+			if mb.Dropped() != tt.wantDropped {
+				t.Fatalf("Dropped = %d, want %d", mb.Dropped(), tt.wantDropped)
+			}
+			for _, want := range tt.wantBodies {
+				msg, ok := mb.TryGet()
+				if !ok || msg.Body != want {
+					t.Fatalf("TryGet = %v (%t), want %d", msg.Body, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMailboxBlockedPutResumes(t *testing.T) {
+	mb := NewMailbox(1, OverflowBlock)
+	if err := mb.Put(context.Background(), Message{Body: 1}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- mb.Put(context.Background(), Message{Body: 2}) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Put returned on a full mailbox: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if msg, err := mb.Get(context.Background()); err != nil || msg.Body != 1 {
+		t.Fatalf("Get = %v, %v", msg.Body, err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("blocked Put: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked Put did not resume after Get")
+	}
+	if msg, err := mb.Get(context.Background()); err != nil || msg.Body != 2 {
+		t.Fatalf("Get = %v, %v", msg.Body, err)
+	}
+}
+
+func TestMailboxClose(t *testing.T) {
+	mb := NewMailbox(2, OverflowBlock)
+	if err := mb.Put(context.Background(), Message{Body: 1}); err != nil {
+		t.Fatal(err)
+	}
+	mb.Close()
+	if err := mb.Put(context.Background(), Message{Body: 2}); !errors.Is(err, ErrMailboxClosed) {
+		t.Fatalf("Put after Close: err = %v, want %v", err, ErrMailboxClosed)
+	}
+	if msg, err := mb.Get(context.Background()); err != nil || msg.Body != 1 {
+		t.Fatalf("Get of a queued message after Close = %v, %v", msg.Body, err)
+	}
+	if _, err := mb.Get(context.Background()); !errors.Is(err, ErrMailboxClosed) {
+		t.Fatalf("Get on an empty closed mailbox: err = %v, want %v", err, ErrMailboxClosed)
+	}
+}
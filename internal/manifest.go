@@ -0,0 +1,775 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	l "github.com/rafa-mori/logz"
+	"gopkg.in/yaml.v3"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ManifestVersion is the manifest format version understood by this release.
+const ManifestVersion = 1
+
+// Manifest is the declarative description of a lifecycle: processes, stages and event handlers.
+type Manifest struct {
+	Version      int               `json:"version" yaml:"version" toml:"version"` // ManifestVersion, or 0 for the current one
+	InitialStage string            `json:"initialStage,omitempty" yaml:"initialStage,omitempty" toml:"initialStage,omitempty"`
+	Parallel     bool              `json:"parallel,omitempty" yaml:"parallel,omitempty" toml:"parallel,omitempty"`
+	ReadyTimeout Duration          `json:"readyTimeout,omitempty" yaml:"readyTimeout,omitempty" toml:"readyTimeout,omitempty"`
+	Processes    []ManifestProcess `json:"processes,omitempty" yaml:"processes,omitempty" toml:"processes,omitempty"`
+	Stages       []ManifestStage   `json:"stages,omitempty" yaml:"stages,omitempty" toml:"stages,omitempty"`
+	Journal      *ManifestJournal  `json:"journal,omitempty" yaml:"journal,omitempty" toml:"journal,omitempty"`
+}
+
+// ManifestJournal describes the event journal, replayed when the lifecycle is built.
+type ManifestJournal struct {
+	Dir           string      `json:"dir" yaml:"dir" toml:"dir"`
+	SegmentSize   ByteSize    `json:"segmentSize,omitempty" yaml:"segmentSize,omitempty" toml:"segmentSize,omitempty"`
+	Fsync         FsyncPolicy `json:"fsync,omitempty" yaml:"fsync,omitempty" toml:"fsync,omitempty"` // always | interval | never
+	FsyncInterval Duration    `json:"fsyncInterval,omitempty" yaml:"fsyncInterval,omitempty" toml:"fsyncInterval,omitempty"`
+	MaxSize       ByteSize    `json:"maxSize,omitempty" yaml:"maxSize,omitempty" toml:"maxSize,omitempty"`
+	MaxAge        Duration    `json:"maxAge,omitempty" yaml:"maxAge,omitempty" toml:"maxAge,omitempty"`
+	Topics        []string    `json:"topics,omitempty" yaml:"topics,omitempty" toml:"topics,omitempty"`
+	QueueSize     int         `json:"queueSize,omitempty" yaml:"queueSize,omitempty" toml:"queueSize,omitempty"`
+}
+
+func (mj *ManifestJournal) validate() error {
+	if mj.Dir == "" {
+		return fmt.Errorf("manifest: journal has no dir")
+	}
+	if mj.SegmentSize < 0 || mj.FsyncInterval < 0 || mj.MaxSize < 0 || mj.MaxAge < 0 || mj.QueueSize < 0 {
+		return fmt.Errorf("manifest: journal has a negative size or duration")
+	}
+	switch mj.Fsync {
+	case "", FsyncAlways, FsyncInterval, FsyncNever:
+	default:
+		return fmt.Errorf("manifest: journal has an invalid fsync policy %q", mj.Fsync)
+	}
+	return nil
+}
+
+func (mj *ManifestJournal) config() JournalConfig {
+	return JournalConfig{
+		Dir:           mj.Dir,
+		SegmentSize:   int64(mj.SegmentSize),
+		Fsync:         mj.Fsync,
+		FsyncInterval: time.Duration(mj.FsyncInterval),
+		MaxSize:       int64(mj.MaxSize),
+		MaxAge:        time.Duration(mj.MaxAge),
+		Topics:        mj.Topics,
+		QueueSize:     mj.QueueSize,
+	}
+}
+
+// ManifestProcess describes a managed process.
+type ManifestProcess struct {
+	Name      string            `json:"name" yaml:"name" toml:"name"`
+	Command   string            `json:"command" yaml:"command" toml:"command"`
+	Args      []string          `json:"args,omitempty" yaml:"args,omitempty" toml:"args,omitempty"`
+	Env       map[string]string `json:"env,omitempty" yaml:"env,omitempty" toml:"env,omitempty"`
+	Dir       string            `json:"dir,omitempty" yaml:"dir,omitempty" toml:"dir,omitempty"`
+	Wait      bool              `json:"wait,omitempty" yaml:"wait,omitempty" toml:"wait,omitempty"`
+	DependsOn []string          `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty" toml:"dependsOn,omitempty"`
+	Restart   *ManifestRestart  `json:"restart,omitempty" yaml:"restart,omitempty" toml:"restart,omitempty"`
+	Stop      *ManifestStop     `json:"stop,omitempty" yaml:"stop,omitempty" toml:"stop,omitempty"`
+	Reload    *ManifestReload   `json:"reload,omitempty" yaml:"reload,omitempty" toml:"reload,omitempty"`
+	Limits    *ManifestLimits   `json:"limits,omitempty" yaml:"limits,omitempty" toml:"limits,omitempty"`
+	Liveness  *ManifestProbe    `json:"liveness,omitempty" yaml:"liveness,omitempty" toml:"liveness,omitempty"`
+	Readiness *ManifestProbe    `json:"readiness,omitempty" yaml:"readiness,omitempty" toml:"readiness,omitempty"`
+	Logs      *ManifestLogs     `json:"logs,omitempty" yaml:"logs,omitempty" toml:"logs,omitempty"`
+	Metrics   *ManifestMetrics  `json:"metrics,omitempty" yaml:"metrics,omitempty" toml:"metrics,omitempty"`
+}
+
+// ManifestLogs describes how the output of a process is captured.
+type ManifestLogs struct {
+	Dir       string   `json:"dir,omitempty" yaml:"dir,omitempty" toml:"dir,omitempty"`
+	MaxSize   ByteSize `json:"maxSize,omitempty" yaml:"maxSize,omitempty" toml:"maxSize,omitempty"`
+	MaxAge    Duration `json:"maxAge,omitempty" yaml:"maxAge,omitempty" toml:"maxAge,omitempty"`
+	MaxFiles  int      `json:"maxFiles,omitempty" yaml:"maxFiles,omitempty" toml:"maxFiles,omitempty"`
+	TailLines int      `json:"tailLines,omitempty" yaml:"tailLines,omitempty" toml:"tailLines,omitempty"`
+	Forward   bool     `json:"forward,omitempty" yaml:"forward,omitempty" toml:"forward,omitempty"`
+}
+
+// ManifestMetrics describes how the resource usage of a process is sampled.
+type ManifestMetrics struct {
+	Interval Duration `json:"interval,omitempty" yaml:"interval,omitempty" toml:"interval,omitempty"`
+	Window   int      `json:"window,omitempty" yaml:"window,omitempty" toml:"window,omitempty"`
+}
+
+// ManifestProbe describes a liveness or readiness probe.
+type ManifestProbe struct {
+	Type             ProbeType `json:"type" yaml:"type" toml:"type"`
+	Command          []string  `json:"command,omitempty" yaml:"command,omitempty" toml:"command,omitempty"`
+	Address          string    `json:"address,omitempty" yaml:"address,omitempty" toml:"address,omitempty"`
+	URL              string    `json:"url,omitempty" yaml:"url,omitempty" toml:"url,omitempty"`
+	InitialDelay     Duration  `json:"initialDelay,omitempty" yaml:"initialDelay,omitempty" toml:"initialDelay,omitempty"`
+	Period           Duration  `json:"period,omitempty" yaml:"period,omitempty" toml:"period,omitempty"`
+	Timeout          Duration  `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+	FailureThreshold int       `json:"failureThreshold,omitempty" yaml:"failureThreshold,omitempty" toml:"failureThreshold,omitempty"`
+	SuccessThreshold int       `json:"successThreshold,omitempty" yaml:"successThreshold,omitempty" toml:"successThreshold,omitempty"`
+}
+
+func (mp *ManifestProbe) validate() error {
+	switch mp.Type {
+	case ProbeExec:
+		if len(mp.Command) == 0 {
+			return fmt.Errorf("exec probe without command")
+		}
+	case ProbeTCP:
+		if mp.Address == "" {
+			return fmt.Errorf("tcp probe without address")
+		}
+	case ProbeHTTP:
+		if mp.URL == "" {
+			return fmt.Errorf("http probe without url")
+		}
+	default:
+		return fmt.Errorf("unknown probe type %q", mp.Type)
+	}
+	return nil
+}
+
+func (mp *ManifestProbe) probe() *Probe {
+	return &Probe{
+		Type:             mp.Type,
+		Command:          mp.Command,
+		Address:          mp.Address,
+		URL:              mp.URL,
+		InitialDelay:     time.Duration(mp.InitialDelay),
+		Period:           time.Duration(mp.Period),
+		Timeout:          time.Duration(mp.Timeout),
+		FailureThreshold: mp.FailureThreshold,
+		SuccessThreshold: mp.SuccessThreshold,
+	}
+}
+
+// ManifestRestart describes the restart policy of a process.
+type ManifestRestart struct {
+	Policy      RestartPolicy `json:"policy" yaml:"policy" toml:"policy"`
+	Backoff     Duration      `json:"backoff,omitempty" yaml:"backoff,omitempty" toml:"backoff,omitempty"`
+	MaxBackoff  Duration      `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty" toml:"maxBackoff,omitempty"`
+	MaxRestarts *int          `json:"maxRestarts,omitempty" yaml:"maxRestarts,omitempty" toml:"maxRestarts,omitempty"`
+	Window      Duration      `json:"window,omitempty" yaml:"window,omitempty" toml:"window,omitempty"`
+}
+
+// ManifestStop describes the stop sequence of a process.
+type ManifestStop struct {
+	Signal string   `json:"signal,omitempty" yaml:"signal,omitempty" toml:"signal,omitempty"`
+	Grace  Duration `json:"grace,omitempty" yaml:"grace,omitempty" toml:"grace,omitempty"`
+}
+
+// ManifestReload describes how a process reloads. The strategy defaults to exec when a command
+// is given and to signal otherwise.
+type ManifestReload struct {
+	Strategy ReloadStrategy `json:"strategy,omitempty" yaml:"strategy,omitempty" toml:"strategy,omitempty"`
+	Signal   string         `json:"signal,omitempty" yaml:"signal,omitempty" toml:"signal,omitempty"`
+	Command  []string       `json:"command,omitempty" yaml:"command,omitempty" toml:"command,omitempty"`
+	Timeout  Duration       `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+	Watch    []string       `json:"watch,omitempty" yaml:"watch,omitempty" toml:"watch,omitempty"`
+	Debounce Duration       `json:"debounce,omitempty" yaml:"debounce,omitempty" toml:"debounce,omitempty"`
+}
+
+func (mr *ManifestReload) strategy() ReloadStrategy {
+	if mr.Strategy != "" {
+		return mr.Strategy
+	}
+	if len(mr.Command) > 0 {
+		return ReloadExec
+	}
+	return ReloadSignal
+}
+
+// ManifestLimits describes the resource limits of a process.
+type ManifestLimits struct {
+	Memory    ByteSize `json:"memory,omitempty" yaml:"memory,omitempty" toml:"memory,omitempty"`
+	CPUs      float64  `json:"cpus,omitempty" yaml:"cpus,omitempty" toml:"cpus,omitempty"`
+	CPUWeight int      `json:"cpuWeight,omitempty" yaml:"cpuWeight,omitempty" toml:"cpuWeight,omitempty"`
+	Pids      int      `json:"pids,omitempty" yaml:"pids,omitempty" toml:"pids,omitempty"`
+	OpenFiles uint64   `json:"openFiles,omitempty" yaml:"openFiles,omitempty" toml:"openFiles,omitempty"`
+}
+
+func (ml *ManifestLimits) validate() error {
+	if ml.Memory < 0 || ml.CPUs < 0 || ml.Pids < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	if ml.CPUWeight != 0 && (ml.CPUWeight < 1 || ml.CPUWeight > 10000) {
+		return fmt.Errorf("cpuWeight must be between 1 and 10000")
+	}
+	return nil
+}
+
+// ManifestStage describes a stage, its transitions and its event handlers.
+type ManifestStage struct {
+	Name        string           `json:"name" yaml:"name" toml:"name"`
+	Description string           `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
+	Type        string           `json:"type,omitempty" yaml:"type,omitempty" toml:"type,omitempty"`
+	Next        []string         `json:"next,omitempty" yaml:"next,omitempty" toml:"next,omitempty"`
+	Prev        []string         `json:"prev,omitempty" yaml:"prev,omitempty" toml:"prev,omitempty"`
+	Workers     int              `json:"workers,omitempty" yaml:"workers,omitempty" toml:"workers,omitempty"`
+	MinWorkers  int              `json:"minWorkers,omitempty" yaml:"minWorkers,omitempty" toml:"minWorkers,omitempty"`
+	Mailbox     *ManifestMailbox `json:"mailbox,omitempty" yaml:"mailbox,omitempty" toml:"mailbox,omitempty"`
+	OnEnter     *ManifestAction  `json:"onEnter,omitempty" yaml:"onEnter,omitempty" toml:"onEnter,omitempty"`
+	OnExit      *ManifestAction  `json:"onExit,omitempty" yaml:"onExit,omitempty" toml:"onExit,omitempty"`
+	Events      []ManifestEvent  `json:"events,omitempty" yaml:"events,omitempty" toml:"events,omitempty"`
+}
+
+// ManifestMailbox describes the mailbox of a stage.
+type ManifestMailbox struct {
+	Capacity int            `json:"capacity,omitempty" yaml:"capacity,omitempty" toml:"capacity,omitempty"`
+	Overflow OverflowPolicy `json:"overflow,omitempty" yaml:"overflow,omitempty" toml:"overflow,omitempty"`
+}
+
+// ManifestEvent binds an action to an event of a stage.
+type ManifestEvent struct {
+	Name           string   `json:"name" yaml:"name" toml:"name"`
+	Timeout        Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"` // Fails the action when it takes longer
+	Retries        int      `json:"retries,omitempty" yaml:"retries,omitempty" toml:"retries,omitempty"` // Extra attempts of a failed action
+	Backoff        Duration `json:"backoff,omitempty" yaml:"backoff,omitempty" toml:"backoff,omitempty"` // Delay before the first retry, doubled on each one
+	ManifestAction `yaml:",inline"`
+}
+
+// Default delays between the retries of a manifest event action.
+const (
+	DefaultEventBackoff    = 500 * time.Millisecond
+	DefaultEventMaxBackoff = 30 * time.Second
+)
+
+// middleware returns the middleware configured for the event.
+func (me *ManifestEvent) middleware() []EventMiddleware {
+	mw := []EventMiddleware{WithRecover()}
+	if me.Retries > 0 {
+		backoff := time.Duration(me.Backoff)
+		if backoff == 0 {
+			backoff = DefaultEventBackoff
+		}
+		mw = append(mw, WithRetry(me.Retries+1, backoff, DefaultEventMaxBackoff))
+	}
+	if me.Timeout > 0 {
+		mw = append(mw, WithTimeout(time.Duration(me.Timeout)))
+	}
+	return mw
+}
+
+// ManifestAction is what a declarative handler does when it fires.
+type ManifestAction struct {
+	Action  string   `json:"action" yaml:"action" toml:"action"`                                  // start | stop | restart | exec | trigger | log
+	Process string   `json:"process,omitempty" yaml:"process,omitempty" toml:"process,omitempty"` // Target of start, stop and restart
+	Command string   `json:"command,omitempty" yaml:"command,omitempty" toml:"command,omitempty"` // Command run by exec
+	Args    []string `json:"args,omitempty" yaml:"args,omitempty" toml:"args,omitempty"`          // Arguments of exec
+	Stage   string   `json:"stage,omitempty" yaml:"stage,omitempty" toml:"stage,omitempty"`       // Target stage of trigger
+	Event   string   `json:"event,omitempty" yaml:"event,omitempty" toml:"event,omitempty"`       // Target event of trigger
+	Message string   `json:"message,omitempty" yaml:"message,omitempty" toml:"message,omitempty"` // Message written by log
+}
+
+// Duration is a time.Duration that reads and writes as a Go duration string ("10s", "1m30s").
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) { return []byte(time.Duration(d).String()), nil }
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ByteSize is a size in bytes that reads as a plain number or with a unit ("512KB", "10MB", "1GiB").
+type ByteSize int64
+
+var byteUnits = map[string]int64{
+	"": 1, "B": 1,
+	"K": 1 << 10, "KB": 1 << 10, "KIB": 1 << 10,
+	"M": 1 << 20, "MB": 1 << 20, "MIB": 1 << 20,
+	"G": 1 << 30, "GB": 1 << 30, "GIB": 1 << 30,
+}
+
+func (b ByteSize) MarshalText() ([]byte, error) { return []byte(strconv.FormatInt(int64(b), 10)), nil }
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	number, unit := s, ""
+	if i >= 0 {
+		number, unit = s[:i], strings.ToUpper(strings.TrimSpace(s[i:]))
+	}
+	multiplier, ok := byteUnits[unit]
+	if !ok {
+		return fmt.Errorf("invalid size %q", s)
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %q", s)
+	}
+	*b = ByteSize(v * float64(multiplier))
+	return nil
+}
+
+// UnmarshalJSON reads a size written as a JSON number of bytes or as a string.
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return b.UnmarshalText([]byte(text))
+	}
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("invalid size %s", data)
+	}
+	*b = ByteSize(v)
+	return nil
+}
+
+// LoadManifest reads a manifest file, picking the format from its extension.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseManifest(data, strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// ParseManifest decodes a manifest in the given format (yaml, yml, toml or json) and validates it.
+func ParseManifest(data []byte, format string) (*Manifest, error) {
+	var m Manifest
+	var err error
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		err = yaml.Unmarshal(data, &m)
+	case "toml":
+		err = toml.Unmarshal(data, &m)
+	case "json":
+		err = json.Unmarshal(data, &m)
+	default:
+		return nil, fmt.Errorf("unsupported manifest format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s manifest: %w", format, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks the version, that names are unique and that every reference points to a
+// declared process or stage.
+func (m *Manifest) Validate() error {
+	if m.Version != 0 && m.Version != ManifestVersion {
+		return fmt.Errorf("manifest: unsupported version %d (this release reads version %d)", m.Version, ManifestVersion)
+	}
+	processes := make(map[string]bool)
+	for _, p := range m.Processes {
+		if p.Name == "" {
+			return fmt.Errorf("manifest: process without a name")
+		}
+		if processes[p.Name] {
+			return fmt.Errorf("manifest: duplicated process %s", p.Name)
+		}
+		processes[p.Name] = true
+		if p.Command == "" {
+			return fmt.Errorf("manifest: process %s has no command", p.Name)
+		}
+		if p.Restart != nil {
+			switch p.Restart.Policy {
+			case RestartAlways, RestartOnFailure, RestartNever:
+			default:
+				return fmt.Errorf("manifest: process %s has an invalid restart policy %q", p.Name, p.Restart.Policy)
+			}
+		}
+		if p.Liveness != nil {
+			if err := p.Liveness.validate(); err != nil {
+				return fmt.Errorf("manifest: process %s liveness: %w", p.Name, err)
+			}
+		}
+		if p.Readiness != nil {
+			if err := p.Readiness.validate(); err != nil {
+				return fmt.Errorf("manifest: process %s readiness: %w", p.Name, err)
+			}
+		}
+		if p.Stop != nil && p.Stop.Signal != "" {
+			if _, err := ParseSignal(p.Stop.Signal); err != nil {
+				return fmt.Errorf("manifest: process %s: %w", p.Name, err)
+			}
+		}
+		if p.Reload != nil {
+			switch p.Reload.strategy() {
+			case ReloadSignal:
+				if p.Reload.Signal != "" {
+					if _, err := ParseSignal(p.Reload.Signal); err != nil {
+						return fmt.Errorf("manifest: process %s reload: %w", p.Name, err)
+					}
+				}
+			case ReloadExec:
+				if len(p.Reload.Command) == 0 {
+					return fmt.Errorf("manifest: process %s reload has no command", p.Name)
+				}
+			default:
+				return fmt.Errorf("manifest: process %s has an invalid reload strategy %q", p.Name, p.Reload.Strategy)
+			}
+		}
+		if p.Metrics != nil && (p.Metrics.Interval < 0 || p.Metrics.Window < 0) {
+			return fmt.Errorf("manifest: process %s metrics must not be negative", p.Name)
+		}
+		if p.Limits != nil {
+			if err := p.Limits.validate(); err != nil {
+				return fmt.Errorf("manifest: process %s limits: %w", p.Name, err)
+			}
+		}
+	}
+
+	for _, p := range m.Processes {
+		for _, dep := range p.DependsOn {
+			if !processes[dep] {
+				return fmt.Errorf("manifest: process %s depends on unknown process %q", p.Name, dep)
+			}
+		}
+	}
+
+	stages := make(map[string]bool)
+	for _, s := range m.Stages {
+		if s.Name == "" {
+			return fmt.Errorf("manifest: stage without a name")
+		}
+		if stages[s.Name] {
+			return fmt.Errorf("manifest: duplicated stage %s", s.Name)
+		}
+		stages[s.Name] = true
+	}
+
+	checkAction := func(where string, a *ManifestAction) error {
+		switch a.Action {
+		case "start", "stop", "restart":
+			if !processes[a.Process] {
+				return fmt.Errorf("manifest: %s refers to unknown process %q", where, a.Process)
+			}
+		case "exec":
+			if a.Command == "" {
+				return fmt.Errorf("manifest: %s has no command to exec", where)
+			}
+		case "trigger":
+			if !stages[a.Stage] || a.Event == "" {
+				return fmt.Errorf("manifest: %s triggers unknown stage %q or an empty event", where, a.Stage)
+			}
+		case "log":
+		default:
+			return fmt.Errorf("manifest: %s has an unknown action %q", where, a.Action)
+		}
+		return nil
+	}
+	for _, s := range m.Stages {
+		for _, ref := range append(append([]string{}, s.Next...), s.Prev...) {
+			if !stages[ref] {
+				return fmt.Errorf("manifest: stage %s refers to unknown stage %q", s.Name, ref)
+			}
+		}
+		if s.Workers < 0 || s.MinWorkers < 0 {
+			return fmt.Errorf("manifest: stage %s has a negative worker count", s.Name)
+		}
+		if s.MinWorkers > s.Workers {
+			return fmt.Errorf("manifest: stage %s has more minWorkers than workers", s.Name)
+		}
+		if s.Mailbox != nil {
+			if s.Mailbox.Capacity < 0 {
+				return fmt.Errorf("manifest: stage %s has a negative mailbox capacity", s.Name)
+			}
+			switch s.Mailbox.Overflow {
+			case "", OverflowBlock, OverflowDropOldest, OverflowError:
+			default:
+				return fmt.Errorf("manifest: stage %s has an invalid mailbox overflow %q", s.Name, s.Mailbox.Overflow)
+			}
+		}
+		if s.OnEnter != nil {
+			if err := checkAction(fmt.Sprintf("stage %s onEnter", s.Name), s.OnEnter); err != nil {
+				return err
+			}
+		}
+		if s.OnExit != nil {
+			if err := checkAction(fmt.Sprintf("stage %s onExit", s.Name), s.OnExit); err != nil {
+				return err
+			}
+		}
+		for _, e := range s.Events {
+			if e.Name == "" {
+				return fmt.Errorf("manifest: stage %s has an event without a name", s.Name)
+			}
+			if IsEventPattern(e.Name) {
+				if err := ValidateEventPattern(e.Name); err != nil {
+					return fmt.Errorf("manifest: stage %s: %w", s.Name, err)
+				}
+			}
+			if e.Timeout < 0 || e.Retries < 0 || e.Backoff < 0 {
+				return fmt.Errorf("manifest: event %s of stage %s has a negative timeout, retries or backoff", e.Name, s.Name)
+			}
+			action := e.ManifestAction
+			if err := checkAction(fmt.Sprintf("event %s of stage %s", e.Name, s.Name), &action); err != nil {
+				return err
+			}
+		}
+	}
+	if m.InitialStage != "" && !stages[m.InitialStage] {
+		return fmt.Errorf("manifest: initial stage %q is not declared", m.InitialStage)
+	}
+	if m.Journal != nil {
+		return m.Journal.validate()
+	}
+	return nil
+}
+
+// NewLifecycleFromManifest builds a fully populated LifeCycleManager from a manifest.
+func NewLifecycleFromManifest(m *Manifest) (LifeCycleManager, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	lm := NewLifecycleManager(nil, nil, nil, nil, nil, nil)
+	lm.SetParallelStart(m.Parallel)
+	if m.ReadyTimeout > 0 {
+		lm.SetReadyTimeout(time.Duration(m.ReadyTimeout))
+	}
+
+	for _, mp := range m.Processes {
+		proc, err := mp.build()
+		if err != nil {
+			return nil, err
+		}
+		if err := lm.AddProcess(proc); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ms := range m.Stages {
+		stageType := ms.Type
+		if stageType == "" {
+			stageType = "stage"
+		}
+		stage := NewStage(ms.Name, ms.Description, stageType).
+			AllowNext(ms.Next...).
+			AllowPrev(ms.Prev...)
+		if ms.Workers > 0 {
+			minWorkers := ms.MinWorkers
+			if minWorkers == 0 {
+				minWorkers = DefaultPoolMinWorkers
+			}
+			stage.AutoScaleRange(minWorkers, ms.Workers)
+		}
+		if ms.Mailbox != nil {
+			stage.SetMailbox(ms.Mailbox.Capacity, ms.Mailbox.Overflow)
+		}
+		if ms.OnEnter != nil {
+			fn := ms.OnEnter.handler(lm, ms.Name, "enter")
+			stage.OnEnter(func() { _ = fn(context.Background(), EventCall{Stage: ms.Name, Event: "enter"}) })
+		}
+		if ms.OnExit != nil {
+			fn := ms.OnExit.handler(lm, ms.Name, "exit")
+			stage.OnExit(func() { _ = fn(context.Background(), EventCall{Stage: ms.Name, Event: "exit"}) })
+		}
+		for _, me := range ms.Events {
+			handler := ChainEvent(me.ManifestAction.handler(lm, ms.Name, me.Name), me.middleware()...)
+			if IsEventPattern(me.Name) {
+				if _, err := stage.Subscribe(me.Name, handler); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			stage.Handle(me.Name, handler)
+		}
+		if err := lm.RegisterStage(stage); err != nil {
+			return nil, err
+		}
+	}
+
+	// The journal restores the stage the lifecycle was in, which wins over the initial stage.
+	if m.Journal != nil {
+		j, err := OpenJournal(m.Journal.config())
+		if err != nil {
+			return nil, fmt.Errorf("opening journal: %w", err)
+		}
+		lm.SetJournal(j)
+		if _, err := lm.ReplayFrom(j.FirstOffset()); err != nil {
+			_ = j.Close()
+			return nil, fmt.Errorf("replaying journal: %w", err)
+		}
+	}
+	if m.InitialStage != "" && lm.GetCurrentStage() == nil {
+		if err := lm.DefineStage(m.InitialStage); err != nil {
+			return nil, err
+		}
+	}
+	return lm, nil
+}
+
+// build creates the managed process described by the manifest entry.
+func (mp ManifestProcess) build() (IManagedProcess, error) {
+	proc := NewManagedProcess(mp.Name, mp.Command, mp.Args, mp.Wait, nil)
+	proc.SetDir(mp.Dir)
+	proc.SetDependsOn(mp.DependsOn)
+	if mp.Liveness != nil {
+		proc.SetLivenessProbe(mp.Liveness.probe())
+	}
+	if mp.Readiness != nil {
+		proc.SetReadinessProbe(mp.Readiness.probe())
+	}
+	if mp.Logs != nil {
+		proc.SetLogConfig(LogConfig{
+			Dir:       mp.Logs.Dir,
+			MaxSize:   int64(mp.Logs.MaxSize),
+			MaxAge:    time.Duration(mp.Logs.MaxAge),
+			MaxFiles:  mp.Logs.MaxFiles,
+			TailLines: mp.Logs.TailLines,
+			Forward:   mp.Logs.Forward,
+		})
+	}
+	if mp.Metrics != nil {
+		proc.Monit().SetInterval(time.Duration(mp.Metrics.Interval))
+		proc.Monit().SetWindow(mp.Metrics.Window)
+	}
+	if len(mp.Env) > 0 {
+		keys := make([]string, 0, len(mp.Env))
+		for k := range mp.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		env := make([]string, 0, len(keys))
+		for _, k := range keys {
+			env = append(env, k+"="+mp.Env[k])
+		}
+		proc.SetEnv(env)
+	}
+
+	if mp.Restart != nil {
+		policy := DefaultSupervisorPolicy(mp.Restart.Policy)
+		if mp.Restart.Backoff > 0 {
+			policy.BackoffInitial = time.Duration(mp.Restart.Backoff)
+		}
+		if mp.Restart.MaxBackoff > 0 {
+			policy.BackoffMax = time.Duration(mp.Restart.MaxBackoff)
+		}
+		if mp.Restart.MaxRestarts != nil {
+			policy.MaxRestarts = *mp.Restart.MaxRestarts
+		}
+		if mp.Restart.Window > 0 {
+			policy.Window = time.Duration(mp.Restart.Window)
+		}
+		proc.SetRestartPolicy(policy)
+	}
+
+	if mp.Stop != nil {
+		policy := DefaultStopPolicy()
+		if mp.Stop.Signal != "" {
+			sig, err := ParseSignal(mp.Stop.Signal)
+			if err != nil {
+				return nil, err
+			}
+			policy.Signal = sig
+		}
+		if mp.Stop.Grace > 0 {
+			policy.Grace = time.Duration(mp.Stop.Grace)
+		}
+		proc.SetStopPolicy(policy)
+	}
+
+	if mp.Reload != nil {
+		policy := ReloadPolicy{
+			Strategy: mp.Reload.strategy(),
+			Command:  mp.Reload.Command,
+			Timeout:  time.Duration(mp.Reload.Timeout),
+			Watch:    mp.Reload.Watch,
+			Debounce: time.Duration(mp.Reload.Debounce),
+		}
+		if mp.Reload.Signal != "" {
+			sig, err := ParseSignal(mp.Reload.Signal)
+			if err != nil {
+				return nil, err
+			}
+			policy.Signal = sig
+		}
+		proc.SetReloadPolicy(policy)
+	}
+
+	if mp.Limits != nil {
+		proc.SetResourceLimits(ResourceLimits{
+			MemoryMax: int64(mp.Limits.Memory),
+			CPUQuota:  mp.Limits.CPUs,
+			CPUWeight: mp.Limits.CPUWeight,
+			PidsMax:   mp.Limits.Pids,
+			OpenFiles: mp.Limits.OpenFiles,
+		})
+	}
+	return proc, nil
+}
+
+// handler turns a declarative action into an event handler bound to the manager. Failures are
+// logged and returned.
+func (a ManifestAction) handler(lm LifeCycleManager, stage, event string) EventHandler {
+	fields := map[string]interface{}{"context": "GoLife", "stage": stage, "event": event, "action": a.Action, "showData": false}
+	return func(ctx context.Context, call EventCall) (err error) {
+		if IsReplay(ctx) {
+			return nil // Actions are side effects, done when the event first fired
+		}
+		data := call.Data
+		switch a.Action {
+		case "start", "stop", "restart":
+			proc := lm.GetProcess(a.Process)
+			if proc == nil {
+				err = fmt.Errorf("process %s not found", a.Process)
+				break
+			}
+			switch a.Action {
+			case "start":
+				err = lm.StartProcess(proc)
+			case "stop":
+				err = proc.Stop()
+			default:
+				err = proc.Restart()
+			}
+		case "exec":
+			cmd := exec.CommandContext(ctx, a.Command, a.Args...)
+			cmd.Env = append(os.Environ(), "GOLIFE_STAGE="+stage, "GOLIFE_EVENT="+call.Event, fmt.Sprintf("GOLIFE_EVENT_DATA=%v", data))
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			err = cmd.Run()
+		case "trigger":
+			err = lm.TriggerContext(ctx, a.Stage, a.Event, data)
+		case "log":
+			l.Info(a.Message, fields)
+		}
+		if err != nil {
+			l.Error(fmt.Sprintf("Action %s of %s/%s failed: %v", a.Action, stage, event, err), fields)
+		}
+		return err
+	}
+}
+
+// signalNames maps the accepted signal names to their values.
+var signalNames = map[string]syscall.Signal{
+	"HUP":   syscall.SIGHUP,
+	"INT":   syscall.SIGINT,
+	"QUIT":  syscall.SIGQUIT,
+	"KILL":  syscall.SIGKILL,
+	"USR1":  syscall.SIGUSR1,
+	"USR2":  syscall.SIGUSR2,
+	"TERM":  syscall.SIGTERM,
+	"CONT":  syscall.SIGCONT,
+	"STOP":  syscall.SIGSTOP,
+	"TSTP":  syscall.SIGTSTP,
+	"WINCH": syscall.SIGWINCH,
+}
+
+// ParseSignal parses a signal given as a name ("SIGTERM", "term") or a number ("15").
+func ParseSignal(name string) (syscall.Signal, error) {
+	if n, err := strconv.Atoi(name); err == nil && n > 0 {
+		return syscall.Signal(n), nil
+	}
+	if sig, ok := signalNames[strings.TrimPrefix(strings.ToUpper(name), "SIG")]; ok {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("unknown signal %q", name)
+}
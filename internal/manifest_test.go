@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestByteSizeUnmarshal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+		err  bool
+	}{
+		{in: "1048576", want: 1 << 20},
+		{in: "512", want: 512},
+		{in: "512B", want: 512},
+		{in: "4K", want: 4 << 10},
+		{in: "10MB", want: 10 << 20},
+		{in: "1GiB", want: 1 << 30},
+		{in: "1.5 mb", want: 3 << 19},
+		{in: "10XB", err: true},
+		{in: "MB", err: true},
+	}
+	for _, tt := range tests {
+		var got ByteSize
+		err := got.UnmarshalText([]byte(tt.in))
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("UnmarshalText(%q) = %d, %v; want %d (error %t)", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseManifestSizes(t *testing.T) {
+	tests := []struct {
+		format   string
+		manifest string
+	}{
+		{"json", `{"version": 1, "processes": [{"name": "api", "command": "./api", "limits": {"memory": 1048576}}]}`},
+		{"json", `{"version": 1, "processes": [{"name": "api", "command": "./api", "limits": {"memory": "1MB"}}]}`},
+		{"yaml", "version: 1\nprocesses:\n  - name: api\n    command: ./api\n    limits:\n      memory: 1048576\n"},
+		{"yaml", "version: 1\nprocesses:\n  - name: api\n    command: ./api\n    limits:\n      memory: 1MB\n"},
+		{"toml", "version = 1\n[[processes]]\nname = \"api\"\ncommand = \"./api\"\n[processes.limits]\nmemory = 1048576\n"},
+		{"toml", "version = 1\n[[processes]]\nname = \"api\"\ncommand = \"./api\"\n[processes.limits]\nmemory = \"1MB\"\n"},
+	}
+	for _, tt := range tests {
+		m, err := ParseManifest([]byte(tt.manifest), tt.format)
+		if err != nil {
+			t.Errorf("%s manifest %s: %v", tt.format, tt.manifest, err)
+			continue
+		}
+		if got := m.Processes[0].Limits.Memory; got != 1<<20 {
+			t.Errorf("%s manifest %s: memory = %d, want %d", tt.format, tt.manifest, got, 1<<20)
+		}
+	}
+}
+
+func TestManifestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		err      string // Part of the expected error, empty when the manifest is valid
+	}{
+		{
+			name: "valid",
+			manifest: `
+version: 1
+initialStage: boot
+processes:
+  - name: db
+    command: ./db
+  - name: api
+    command: ./api
+    dependsOn: [db]
+    restart: {policy: on-failure}
+    stop: {signal: SIGINT}
+stages:
+  - name: boot
+    next: [running]
+    onEnter: {action: start, process: api}
+  - name: running
+    events:
+      - name: process.*.exited
+        action: trigger
+        stage: boot
+        event: recover
+`,
+		},
+		{name: "version omitted", manifest: "processes: [{name: api, command: ./api}]"},
+		{name: "unknown version", manifest: "version: 2", err: "unsupported version 2"},
+		{name: "unnamed process", manifest: "processes: [{command: ./api}]", err: "process without a name"},
+		{name: "duplicated process", manifest: "processes: [{name: api, command: ./a}, {name: api, command: ./b}]", err: "duplicated process api"},
+		{name: "no command", manifest: "processes: [{name: api}]", err: "has no command"},
+		{name: "unknown dependency", manifest: "processes: [{name: api, command: ./api, dependsOn: [db]}]", err: `unknown process "db"`},
+		{name: "restart policy", manifest: "processes: [{name: api, command: ./api, restart: {policy: sometimes}}]", err: "invalid restart policy"},
+		{name: "stop signal", manifest: "processes: [{name: api, command: ./api, stop: {signal: SIGNOPE}}]", err: "SIGNOPE"},
+		{name: "probe without address", manifest: "processes: [{name: api, command: ./api, liveness: {type: tcp}}]", err: "tcp probe without address"},
+		{name: "cpu weight", manifest: "processes: [{name: api, command: ./api, limits: {cpuWeight: 20000}}]", err: "cpuWeight"},
+		{name: "unknown next stage", manifest: "stages: [{name: boot, next: [running]}]", err: `unknown stage "running"`},
+		{name: "min workers", manifest: "stages: [{name: boot, workers: 1, minWorkers: 2}]", err: "more minWorkers than workers"},
+		{name: "mailbox overflow", manifest: "stages: [{name: boot, mailbox: {overflow: spill}}]", err: "invalid mailbox overflow"},
+		{name: "unknown action", manifest: "stages: [{name: boot, events: [{name: go, action: dance}]}]", err: `unknown action "dance"`},
+		{name: "action on unknown process", manifest: "stages: [{name: boot, onEnter: {action: start, process: api}}]", err: `unknown process "api"`},
+		{name: "bad event pattern", manifest: "stages: [{name: boot, events: [{name: order.a#, action: log}]}]", err: "mixes #"},
+		{name: "initial stage", manifest: "initialStage: boot", err: `initial stage "boot"`},
+		{name: "journal without dir", manifest: "journal: {fsync: always}", err: "journal has no dir"},
+		{name: "journal fsync", manifest: "journal: {dir: /tmp/j, fsync: sometimes}", err: "invalid fsync policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(tt.manifest), "yaml")
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("err = %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults applied to the resource sampling of a ManagedMonit.
+const (
+	DefaultMetricsInterval = 5 * time.Second
+	DefaultMetricsWindow   = 60 // Samples kept per process
+)
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat. It is 100 on every
+// architecture Linux exposes to user space.
+const clockTicks = 100
+
+// ResourceSample is one reading of what a process and its descendants use.
+type ResourceSample struct {
+	Process       string    `json:"process" yaml:"process"`
+	Pid           int       `json:"pid" yaml:"pid"`
+	Time          time.Time `json:"time" yaml:"time"`
+	ResourceUsage `yaml:",inline"`
+}
+
+// resourceSampler reads /proc for the process tree of a running process on an interval and keeps
+// the last samples.
+type resourceSampler struct {
+	mu       sync.Mutex
+	samples  []ResourceSample
+	cancel   context.CancelFunc
+	onSample func(ResourceSample)
+}
+
+func newResourceSampler() *resourceSampler {
+	return &resourceSampler{}
+}
+
+// start drops the samples of the previous run and samples the tree of pid until stop.
+func (s *resourceSampler) start(name string, pid int, interval time.Duration, window int) {
+	if interval <= 0 {
+		interval = DefaultMetricsInterval
+	}
+	if window <= 0 {
+		window = DefaultMetricsWindow
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.samples = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go s.run(ctx, name, pid, interval, window)
+}
+
+// stop halts the sampling; the samples are kept until the next start.
+func (s *resourceSampler) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// run takes a sample every interval. CPU usage is the CPU time the tree spent since the previous
+// reading, so the first reading is only a baseline.
+func (s *resourceSampler) run(ctx context.Context, name string, pid int, interval time.Duration, window int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	_, lastTicks := readTreeUsage(pid)
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		usage, ticks := readTreeUsage(pid)
+		now := time.Now()
+		if usage.Processes == 0 {
+			continue
+		}
+		// Descendants that exited take their CPU time with them; the tree may look idle once.
+		if ticks > lastTicks {
+			usage.CPUPercent = float64(ticks-lastTicks) / clockTicks / now.Sub(last).Seconds() * 100
+		}
+		lastTicks, last = ticks, now
+		sample := ResourceSample{Process: name, Pid: pid, Time: now, ResourceUsage: usage}
+
+		s.mu.Lock()
+		if ctx.Err() != nil {
+			s.mu.Unlock()
+			return
+		}
+		s.samples = append(s.samples, sample)
+		if len(s.samples) > window {
+			s.samples = append([]ResourceSample(nil), s.samples[len(s.samples)-window:]...)
+		}
+		onSample := s.onSample
+		s.mu.Unlock()
+
+		if onSample != nil {
+			onSample(sample)
+		}
+	}
+}
+
+// latest returns the last sample, if any.
+func (s *resourceSampler) latest() (ResourceSample, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) == 0 {
+		return ResourceSample{}, false
+	}
+	return s.samples[len(s.samples)-1], true
+}
+
+// history returns the samples in the window, oldest first.
+func (s *resourceSampler) history() []ResourceSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]ResourceSample(nil), s.samples...)
+}
+
+// readTreeUsage reads what pid and its descendants use and the CPU time they spent, in clock
+// ticks. CPUPercent is left at zero: it needs two readings.
+func readTreeUsage(pid int) (ResourceUsage, uint64) {
+	var usage ResourceUsage
+	var ticks uint64
+	if pid <= 0 {
+		return usage, 0
+	}
+	for _, p := range processTree(pid) {
+		stat, ok := readProcStat(p)
+		if !ok {
+			continue
+		}
+		ticks += stat.cpuTicks
+		usage.Processes++
+		addProcUsage(&usage, p)
+	}
+	return usage, ticks
+}
+
+// procStat holds the fields of /proc/<pid>/stat the sampler needs.
+type procStat struct {
+	ppid     int
+	cpuTicks uint64 // utime + stime
+}
+
+func readProcStat(pid int) (procStat, bool) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return procStat{}, false
+	}
+	// The command name is in parentheses and may contain spaces; the fields follow the last one.
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return procStat{}, false
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 13 {
+		return procStat{}, false
+	}
+	var stat procStat
+	stat.ppid, _ = strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	stat.cpuTicks = utime + stime
+	return stat, true
+}
+
+// processTree returns pid followed by its descendants.
+func processTree(pid int) []int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return []int{pid}
+	}
+	children := make(map[int][]int)
+	for _, entry := range entries {
+		child, err := strconv.Atoi(entry.Name())
+		if err != nil || child == pid {
+			continue
+		}
+		if stat, ok := readProcStat(child); ok {
+			children[stat.ppid] = append(children[stat.ppid], child)
+		}
+	}
+	tree := []int{pid}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i]]...)
+	}
+	return tree
+}
+
+// addProcUsage adds the memory, threads, descriptors and IO of pid to usage.
+func addProcUsage(usage *ResourceUsage, pid int) {
+	if f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid)); err == nil {
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			fields := strings.Fields(sc.Text())
+			if len(fields) < 2 {
+				continue
+			}
+			switch fields[0] {
+			case "VmRSS:":
+				kb, _ := strconv.ParseUint(fields[1], 10, 64)
+				usage.RSSBytes += kb * 1024
+			case "Threads:":
+				n, _ := strconv.Atoi(fields[1])
+				usage.Threads += n
+			}
+		}
+		_ = f.Close()
+	}
+	if entries, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid)); err == nil {
+		usage.FDs += len(entries)
+	}
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/io", pid)); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				continue
+			}
+			n, _ := strconv.ParseUint(fields[1], 10, 64)
+			switch fields[0] {
+			case "read_bytes:":
+				usage.ReadBytes += n
+			case "write_bytes:":
+				usage.WriteBytes += n
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	l "github.com/rafa-mori/logz"
+	"runtime/debug"
+	"time"
+)
+
+// ErrHandlerTimeout is returned by handlers cut short by WithTimeout.
+var ErrHandlerTimeout = errors.New("event handler timed out")
+
+// EventCall is one call of an event handler.
+type EventCall struct {
+	Stage string
+	Event string
+	Data  interface{}
+}
+
+// EventHandler handles an event of a stage. The error it returns is the error of Trigger.
+type EventHandler func(ctx context.Context, call EventCall) error
+
+// EventMiddleware wraps an event handler, e.g. to recover, time or retry it.
+type EventMiddleware func(next EventHandler) EventHandler
+
+// ChainEvent wraps h with mw. The first middleware is the outermost one.
+func ChainEvent(h EventHandler, mw ...EventMiddleware) EventHandler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		if mw[i] != nil {
+			h = mw[i](h)
+		}
+	}
+	return h
+}
+
+// handlerOf adapts a plain event callback to an EventHandler.
+func handlerOf(fn func(interface{})) EventHandler {
+	return func(ctx context.Context, call EventCall) error {
+		fn(call.Data)
+		return nil
+	}
+}
+
+// WithRecover turns a panic of the handler into a *PanicError.
+func WithRecover() EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, call EventCall) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = &PanicError{Value: r, Stack: debug.Stack()}
+				}
+			}()
+			return next(ctx, call)
+		}
+	}
+}
+
+// WithTimeout cancels the context of the handler after d and returns ErrHandlerTimeout without
+// waiting for it any longer. Handlers should watch ctx, since a handler that ignores it keeps
+// running in the background. The error or panic of an abandoned handler, once it returns, is
+// logged. A panic in time is returned as a *PanicError.
+func WithTimeout(d time.Duration) EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, call EventCall) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- &PanicError{Value: r, Stack: debug.Stack()}
+					}
+				}()
+				done <- next(ctx, call)
+			}()
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				go logLateResult(call, done)
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return fmt.Errorf("%s/%s after %s: %w", call.Stage, call.Event, d, ErrHandlerTimeout)
+				}
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// logLateResult waits for a handler abandoned by WithTimeout and logs its failure. A handler
+// that gave up because its context was done did what it was asked and is not logged.
+func logLateResult(call EventCall, done <-chan error) {
+	err := <-done
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	fields := map[string]interface{}{"context": "GoLife", "stage": call.Stage, "event": call.Event, "showData": false}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		fields["stack"] = string(panicErr.Stack)
+	}
+	l.Error(fmt.Sprintf("Event %s in %s failed after it timed out: %v", call.Event, call.Stage, err), fields)
+}
+
+// WithRetry calls the handler up to attempts times while it fails, waiting initial between the
+// first attempts and doubling the delay up to max. It gives up when ctx is done.
+func WithRetry(attempts int, initial, max time.Duration) EventMiddleware {
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := SupervisorPolicy{BackoffInitial: initial, BackoffMax: max, BackoffFactor: 2}
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, call EventCall) error {
+			var err error
+			for attempt := 0; attempt < attempts; attempt++ {
+				if attempt > 0 {
+					timer := time.NewTimer(backoff.Backoff(attempt - 1))
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+					case <-timer.C:
+					}
+				}
+				if err = next(ctx, call); err == nil {
+					return nil
+				}
+				l.Warn(fmt.Sprintf("Event %s in %s failed (attempt %d of %d): %v", call.Event, call.Stage, attempt+1, attempts, err), map[string]interface{}{"context": "GoLife", "stage": call.Stage, "event": call.Event, "showData": false})
+			}
+			return err
+		}
+	}
+}
+
+// WithLogging logs every call of the handler with its duration and error.
+func WithLogging() EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, call EventCall) error {
+			fields := map[string]interface{}{"context": "GoLife", "stage": call.Stage, "event": call.Event, "showData": false}
+			started := time.Now()
+			err := next(ctx, call)
+			if err != nil {
+				l.Error(fmt.Sprintf("Event %s in %s failed after %s: %v", call.Event, call.Stage, time.Since(started), err), fields)
+			} else {
+				l.Info(fmt.Sprintf("Event %s in %s handled in %s", call.Event, call.Stage, time.Since(started)), fields)
+			}
+			return err
+		}
+	}
+}
+
+// WithMetrics records the duration and the errors of the handler in DefaultMetrics.
+func WithMetrics() EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, call EventCall) error {
+			started := time.Now()
+			err := next(ctx, call)
+			eventHandlerSeconds.ObserveDuration(started, call.Stage, call.Event)
+			if err != nil {
+				eventHandlerErrorsTotal.Inc(call.Stage, call.Event)
+			}
+			return err
+		}
+	}
+}
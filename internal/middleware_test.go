@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	l "github.com/rafa-mori/logz"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	// logz creates its logger on first use without a lock; create it here, before the abandoned
+	// handlers log from goroutines of their own.
+	l.Debug("Testing WithTimeout", nil)
+
+	boom := errors.New("boom")
+	tests := []struct {
+		name    string
+		handler EventHandler
+		cancel  bool // Cancel the caller's context right away
+		wantErr error
+		panics  bool
+	}{
+		{name: "returns in time", handler: func(ctx context.Context, call EventCall) error { return nil }},
+		{name: "fails in time", handler: func(ctx context.Context, call EventCall) error { return boom }, wantErr: boom},
+		{name: "panics in time", handler: func(ctx context.Context, call EventCall) error { panic("oops") }, panics: true},
+		{
+			name:    "watches its context",
+			handler: func(ctx context.Context, call EventCall) error { <-ctx.Done(); return ctx.Err() },
+			wantErr: ErrHandlerTimeout,
+		},
+		{
+			name:    "ignores its context",
+			handler: func(ctx context.Context, call EventCall) error { time.Sleep(200 * time.Millisecond); return boom },
+			wantErr: ErrHandlerTimeout,
+		},
+		{
+			name:    "panics after the timeout",
+			handler: func(ctx context.Context, call EventCall) error { time.Sleep(100 * time.Millisecond); panic("late") },
+			wantErr: ErrHandlerTimeout,
+		},
+		{
+			name:    "caller gives up",
+			handler: func(ctx context.Context, call EventCall) error { <-ctx.Done(); return nil },
+			cancel:  true,
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			started := time.Now()
+			err := ChainEvent(tt.handler, WithTimeout(30*time.Millisecond))(ctx, EventCall{Stage: "boot", Event: "go"})
+			if elapsed := time.Since(started); elapsed > 150*time.Millisecond {
+				t.Errorf("returned after %s", elapsed)
+			}
+			var panicErr *PanicError
+			switch {
+			case tt.panics && !errors.As(err, &panicErr):
+				t.Fatalf("err = %v, want a *PanicError", err)
+			case !tt.panics && !errors.Is(err, tt.wantErr):
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrHandlerTimeout) && !strings.Contains(err.Error(), "boot/go") {
+				t.Errorf("timeout error %q does not name the event", err)
+			}
+		})
+	}
+	// Let the abandoned handlers return before the package tests go on.
+	time.Sleep(250 * time.Millisecond)
+}
+
+func TestWithRetry(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name      string
+		attempts  int
+		failures  int // Calls failing before the handler succeeds
+		timeout   time.Duration
+		wantCalls int32
+		wantErr   error
+	}{
+		{name: "succeeds at once", attempts: 3, wantCalls: 1},
+		{name: "succeeds on the last attempt", attempts: 3, failures: 2, wantCalls: 3},
+		{name: "runs out of attempts", attempts: 3, failures: 5, wantCalls: 3, wantErr: boom},
+		{name: "at least one attempt", attempts: 0, failures: 5, wantCalls: 1, wantErr: boom},
+		{name: "gives up when the context is done", attempts: 10, failures: 10, timeout: 50 * time.Millisecond, wantCalls: 3, wantErr: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			handler := func(ctx context.Context, call EventCall) error {
+				if int(calls.Add(1)) <= tt.failures {
+					return boom
+				}
+				return nil
+			}
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			err := ChainEvent(handler, WithRetry(tt.attempts, 10*time.Millisecond, 100*time.Millisecond))(ctx, EventCall{Stage: "boot", Event: "go"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Fatalf("%d calls, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestChainEventOrder(t *testing.T) {
+	var trace []string
+	mark := func(name string) EventMiddleware {
+		return func(next EventHandler) EventHandler {
+			return func(ctx context.Context, call EventCall) error {
+				trace = append(trace, "in "+name)
+				err := next(ctx, call)
+				trace = append(trace, "out "+name)
+				return err
+			}
+		}
+	}
+	h := ChainEvent(func(ctx context.Context, call EventCall) error {
+		trace = append(trace, "handler")
+		panic("oops")
+	}, mark("outer"), nil, WithRecover(), mark("inner"))
+
+	var panicErr *PanicError
+	if err := h(context.Background(), EventCall{}); !errors.As(err, &panicErr) || panicErr.Value != "oops" {
+		t.Fatalf("err = %v, want the recovered panic", err)
+	}
+	// The panic unwinds inner without running the rest of it, and outer sees the error.
+	if got, want := strings.Join(trace, ","), "in outer,in inner,handler,out outer"; got != want {
+		t.Fatalf("trace = %s, want %s", got, want)
+	}
+}
@@ -1,19 +1,26 @@
 package internal
 
 import (
-	"time"
+	"fmt"
 	"github.com/rafa-mori/logz"
+	"strings"
+	"time"
 )
 
 type IManagedMonit interface {
 	Stdout() string
 	Stderr() string
+	Tail(stream string, lines int) []LogLine
+	LogsSince(seq uint64) ([]LogLine, uint64)
+	Logs(stream string, since uint64, lines int) ([]LogLine, uint64)
 	Properties() map[string]interface{}
+	Samples() []ResourceSample
 	Monitor() error
 
 	// Monitoramento
 	SetMonitoring(monitoring bool)
 	SetInterval(interval time.Duration)
+	SetWindow(window int)
 	SetTimeout(timeout time.Duration)
 	SetDelay(delay time.Duration)
 	SetTimeouts(timeouts []time.Duration)
@@ -39,7 +46,8 @@ type IManagedMonit interface {
 type ManagedMonit struct {
 	// Monitoramento
 	Monitoring bool
-	Interval   time.Duration
+	Interval   time.Duration // Resource sampling interval (DefaultMetricsInterval when zero)
+	Window     int           // Resource samples kept (DefaultMetricsWindow when zero)
 	Timeout    time.Duration
 	Delay      time.Duration
 	Timeouts   []time.Duration
@@ -50,6 +58,11 @@ type ManagedMonit struct {
 	Stopped bool
 	Failed  bool
 	Success bool
+
+	// Captured output
+	output  *outputCapture
+	reload  func() error // Reload of the monitored process
+	sampler *resourceSampler
 }
 
 func (m *ManagedMonit) SetMonitoring(monitoring bool) {
@@ -60,6 +73,10 @@ func (m *ManagedMonit) SetInterval(interval time.Duration) {
 	m.Interval = interval
 	logz.Info("Interval set", map[string]interface{}{"interval": interval})
 }
+func (m *ManagedMonit) SetWindow(window int) {
+	m.Window = window
+	logz.Info("Window set", map[string]interface{}{"window": window})
+}
 func (m *ManagedMonit) SetTimeout(timeout time.Duration) {
 	m.Timeout = timeout
 	logz.Info("Timeout set", map[string]interface{}{"timeout": timeout})
@@ -105,8 +122,10 @@ func (m *ManagedMonit) Restart() error {
 	return nil
 }
 func (m *ManagedMonit) Reload() error {
-	logz.Info("Reloading monitor", nil)
-	return nil
+	if m.reload == nil {
+		return fmt.Errorf("nothing to reload")
+	}
+	return m.reload()
 }
 func (m *ManagedMonit) IsRunning() bool {
 	logz.Info("Checking if monitor is running", nil)
@@ -129,16 +148,71 @@ func (m *ManagedMonit) String() string {
 	return ""
 }
 func (m *ManagedMonit) Stdout() string {
-	logz.Info("Getting monitor stdout", nil)
-	return ""
+	return joinLines(m.Tail("stdout", 0))
 }
 func (m *ManagedMonit) Stderr() string {
-	logz.Info("Getting monitor stderr", nil)
-	return ""
+	return joinLines(m.Tail("stderr", 0))
+}
+func (m *ManagedMonit) Tail(stream string, lines int) []LogLine {
+	if m.output == nil {
+		return []LogLine{}
+	}
+	return m.output.buffer().Tail(stream, lines)
+}
+func (m *ManagedMonit) LogsSince(seq uint64) ([]LogLine, uint64) {
+	if m.output == nil {
+		return []LogLine{}, seq
+	}
+	return m.output.buffer().Since(seq)
+}
+
+// Logs returns the last lines (every one when lines <= 0) of stream with a sequence number
+// greater than since, and the since of the next call, from a single snapshot of the buffer.
+func (m *ManagedMonit) Logs(stream string, since uint64, lines int) ([]LogLine, uint64) {
+	if m.output == nil {
+		return []LogLine{}, since
+	}
+	return m.output.buffer().Lines(stream, since, lines)
 }
+
+// Properties returns the last resource sample of the process and the averages and peaks of the
+// window, or nil before the first sample.
 func (m *ManagedMonit) Properties() map[string]interface{} {
-	logz.Info("Getting monitor properties", nil)
-	return nil
+	samples := m.Samples()
+	if len(samples) == 0 {
+		return nil
+	}
+	last := samples[len(samples)-1]
+	var cpuSum, cpuMax float64
+	var rssMax uint64
+	for _, s := range samples {
+		cpuSum += s.CPUPercent
+		cpuMax = max(cpuMax, s.CPUPercent)
+		rssMax = max(rssMax, s.RSSBytes)
+	}
+	return map[string]interface{}{
+		"pid":           last.Pid,
+		"time":          last.Time,
+		"cpuPercent":    last.CPUPercent,
+		"rssBytes":      last.RSSBytes,
+		"threads":       last.Threads,
+		"fds":           last.FDs,
+		"readBytes":     last.ReadBytes,
+		"writeBytes":    last.WriteBytes,
+		"processes":     last.Processes,
+		"avgCpuPercent": cpuSum / float64(len(samples)),
+		"maxCpuPercent": cpuMax,
+		"maxRssBytes":   rssMax,
+		"samples":       len(samples),
+	}
+}
+
+// Samples returns the resource samples of the current or last run, oldest first.
+func (m *ManagedMonit) Samples() []ResourceSample {
+	if m.sampler == nil {
+		return []ResourceSample{}
+	}
+	return m.sampler.history()
 }
 func (m *ManagedMonit) Monitor() error {
 	logz.Info("Monitoring", nil)
@@ -146,7 +220,35 @@ func (m *ManagedMonit) Monitor() error {
 }
 
 func NewManagedMonit() IManagedMonit {
-	monit := ManagedMonit{}
+	monit := ManagedMonit{sampler: newResourceSampler()}
 	logz.Info("Creating new ManagedMonit", nil)
 	return &monit
 }
+
+// startSampling samples the process tree of pid until stopSampling.
+func (m *ManagedMonit) startSampling(name string, pid int) {
+	m.sampler.start(name, pid, m.Interval, m.Window)
+}
+func (m *ManagedMonit) stopSampling() {
+	m.sampler.stop()
+}
+
+// usage returns the last sample of pid, or a single reading with no CPU usage when there is none.
+func (m *ManagedMonit) usage(pid int) ResourceUsage {
+	if pid <= 0 {
+		return ResourceUsage{}
+	}
+	if last, ok := m.sampler.latest(); ok && last.Pid == pid {
+		return last.ResourceUsage
+	}
+	usage, _ := readTreeUsage(pid)
+	return usage
+}
+
+func joinLines(lines []LogLine) string {
+	texts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		texts = append(texts, line.Text)
+	}
+	return strings.Join(texts, "\n")
+}
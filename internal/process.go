@@ -1,11 +1,15 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	lg "github.com/rafa-mori/logz"
 	"os"
 	"os/exec"
 	"sync"
+	"syscall"
+	"time"
 )
 
 type IManagedProcess interface {
@@ -17,16 +21,33 @@ type IManagedProcess interface {
 	GetProcPid() int
 	GetProcHandle() uintptr
 	GetCmd() *exec.Cmd
+	GetEnv() []string
+	GetDir() string
+	GetDependsOn() []string
+	GetRestartPolicy() SupervisorPolicy
+	GetStopPolicy() StopPolicy
 	WillRestart() bool
 
 	Start() error
 	Stop() error
+	Shutdown() (StopOutcome, error)
 	Restart() error
+	Pause() error
+	Resume() error
+	Reload() error
 	IsRunning() bool
+	IsPaused() bool
 
 	Pid() int
 	Wait() error
 	String() string
+	State() ProcessState
+	ExitCode() int
+	ExitSignal() string
+	Restarts() int
+	Health() HealthState
+	Snapshot() ProcessStatus
+	WaitReady(ctx context.Context) error
 
 	SetArgs(args []string)
 	SetCommand(command string)
@@ -36,6 +57,25 @@ type IManagedProcess interface {
 	SetProcPid(pid int)
 	SetProcHandle(handle uintptr)
 	SetCmd(cmd *exec.Cmd)
+	SetEnv(env []string)
+	SetDir(dir string)
+	SetDependsOn(deps []string)
+	SetRestartPolicy(policy SupervisorPolicy)
+	SetStopPolicy(policy StopPolicy)
+	SetReloadPolicy(policy ReloadPolicy)
+	GetReloadPolicy() ReloadPolicy
+	OnReload(fn func(ReloadEvent))
+	OnMetrics(fn func(ResourceSample))
+	SetResourceLimits(limits ResourceLimits)
+	GetResourceLimits() ResourceLimits
+	ExitReason() ExitReason
+	SetLivenessProbe(probe *Probe)
+	SetReadinessProbe(probe *Probe)
+	OnHealthChange(fn func(HealthChange))
+	OnStateChange(fn func(ProcessStateChange))
+	SetLogConfig(config LogConfig)
+	GetLogConfig() LogConfig
+	Monit() IManagedMonit
 }
 
 type ManagedProcess struct {
@@ -43,11 +83,37 @@ type ManagedProcess struct {
 	Command    string
 	CustomFunc func() error
 	Cmd        *exec.Cmd
+	Env        []string
+	Dir        string
+	DependsOn  []string
 	Name       string
 	WaitFor    bool
 	ProcPid    int
 	ProcHandle uintptr
 	mu         sync.Mutex
+
+	// Supervision
+	sup          supervisor
+	stopPolicy   StopPolicy
+	state        ProcessState
+	startedAt    time.Time
+	exitCode     int
+	exitSignal   string
+	exitReason   ExitReason
+	lastErr      error
+	unhealthy    bool   // The current run was killed by its liveness probe
+	frozen       string // Cgroup frozen by Pause, empty when the group got SIGSTOP
+	health       *healthMonitor
+	onState      func(ProcessStateChange)
+	onReload     func(ReloadEvent)
+	reloadPolicy ReloadPolicy
+	limits       ResourceLimits
+	cgroup       *processCgroup // Group of the current run, nil when the limits are rlimits only
+	oomBase      uint64         // OOM kills the group had recorded when the run started
+	output       *outputCapture
+	monit        *ManagedMonit
+	stopCh       chan struct{} // Closed by Stop to interrupt a pending restart
+	doneCh       chan struct{} // Closed when supervision of the current run ends
 }
 
 func (p *ManagedProcess) GetArgs() []string           { return p.Args }
@@ -58,85 +124,342 @@ func (p *ManagedProcess) GetWaitFor() bool            { return p.WaitFor }
 func (p *ManagedProcess) GetProcPid() int             { return p.ProcPid }
 func (p *ManagedProcess) GetProcHandle() uintptr      { return p.ProcHandle }
 func (p *ManagedProcess) GetCmd() *exec.Cmd           { return p.Cmd }
-func (p *ManagedProcess) WillRestart() bool           { return p.Cmd != nil }
+func (p *ManagedProcess) GetEnv() []string            { return p.Env }
+func (p *ManagedProcess) GetDir() string              { return p.Dir }
+func (p *ManagedProcess) GetDependsOn() []string      { return p.DependsOn }
+func (p *ManagedProcess) GetRestartPolicy() SupervisorPolicy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.sup.policy
+}
+func (p *ManagedProcess) GetStopPolicy() StopPolicy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.stopPolicy
+}
+func (p *ManagedProcess) WillRestart() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.sup.policy.Restart == RestartAlways || p.sup.policy.Restart == RestartOnFailure
+}
 func (p *ManagedProcess) Start() error {
 	if p == nil {
 		return nil
 	}
 	p.mu.Lock()
-	defer p.mu.Unlock()
 
-	if p.IsRunning() {
+	if p.isRunning() {
+		p.mu.Unlock()
 		return fmt.Errorf("process %s is already running", p.Name)
 	}
-
-	if p.CustomFunc != nil {
-		go func() {
-			if err := p.CustomFunc(); err != nil {
-				lg.Error(fmt.Sprintf("Error in custom execution of process %s: %v", p.Name, err), nil)
-			}
-		}()
-		return nil
-	} else if p.Command != "" {
-		p.Cmd = exec.Command(p.Command, p.Args...)
-		if p.WaitFor {
-			return p.Cmd.Run()
-		} else {
-			if err := p.Cmd.Start(); err != nil {
-				return err
-			}
-			p.ProcPid = p.Cmd.Process.Pid
-			p.ProcHandle = uintptr(p.Cmd.Process.Pid)
-			return p.Cmd.Process.Release()
-		}
-	} else {
+	if p.CustomFunc == nil && p.Command == "" {
+		p.mu.Unlock()
 		lg.Warn(fmt.Sprintf("No command defined for process %s", p.Name), nil)
 		return nil
 	}
+
+	wait, err := p.spawn()
+	if err != nil {
+		p.exitCode, p.exitSignal = exitStatus(err)
+		p.exitReason = ExitFailed
+		p.lastErr = err
+		p.setState(StateExited)
+		p.mu.Unlock()
+		return err
+	}
+	p.stopCh = make(chan struct{})
+	p.doneCh = make(chan struct{})
+	go p.supervise(wait, p.stopCh, p.doneCh)
+	p.watchReload(p.doneCh)
+	waitFor := p.WaitFor
+	p.mu.Unlock()
+
+	if waitFor {
+		return p.Wait()
+	}
+	return nil
 }
 func (p *ManagedProcess) Stop() error {
+	_, err := p.Shutdown()
+	return err
+}
+func (p *ManagedProcess) Shutdown() (StopOutcome, error) {
 	if p == nil {
-		return nil
+		return StopNotRunning, nil
 	}
 	p.mu.Lock()
-	defer p.mu.Unlock()
 
-	if !p.IsRunning() {
-		return nil
+	if p.stopCh == nil || p.state == StateStopped || p.state == StateExited || p.state == StateFatal {
+		p.mu.Unlock()
+		return StopNotRunning, nil
 	}
+	close(p.stopCh)
+	p.stopCh = nil
+	doneCh := p.doneCh
+	policy := p.stopPolicy
 
-	if err := p.Cmd.Process.Kill(); err != nil {
-		return err
+	pid := -1
+	if p.CustomFunc == nil && (p.state == StateRunning || p.state == StatePaused) && p.Cmd != nil && p.Cmd.Process != nil {
+		pid = p.Cmd.Process.Pid
 	}
-	return nil
+	paused, frozen := p.state == StatePaused, p.frozen
+	p.mu.Unlock()
+
+	if pid > 0 {
+		if err := signalGroup(pid, policy.Signal); err != nil {
+			return StopFailed, err
+		}
+		// A suspended tree only handles the signal once it runs again.
+		if paused {
+			if err := thawTree(pid, frozen); err != nil {
+				lg.Warn(fmt.Sprintf("Error resuming paused process %s: %v", p.Name, err), map[string]interface{}{"context": "GoLife", "process": p.Name, "showData": false})
+			}
+		}
+	}
+
+	grace := time.NewTimer(policy.Grace)
+	defer grace.Stop()
+	select {
+	case <-doneCh:
+		return StopGraceful, nil
+	case <-grace.C:
+	}
+
+	if pid <= 0 {
+		return p.detach(doneCh, policy.Grace)
+	}
+
+	lg.Warn(fmt.Sprintf("Process %s did not exit within %s, sending SIGKILL", p.Name, policy.Grace), map[string]interface{}{"context": "GoLife", "process": p.Name, "showData": false})
+	if err := signalGroup(pid, syscall.SIGKILL); err != nil {
+		return StopFailed, err
+	}
+	<-doneCh
+	return StopKilled, nil
+}
+
+// detach gives up on the custom function of the run supervised until doneCh, which cannot be
+// killed: the process is stopped so it can be started again, and the supervisor of the run exits
+// quietly whenever the function returns.
+func (p *ManagedProcess) detach(doneCh chan struct{}, grace time.Duration) (StopOutcome, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-doneCh:
+		return StopGraceful, nil
+	default:
+	}
+	if p.doneCh == doneCh {
+		p.doneCh = nil
+		p.exitReason = ExitStopped
+		p.setState(StateStopped)
+	}
+	lg.Warn(fmt.Sprintf("Process %s did not return within %s, leaving it behind", p.Name, grace), map[string]interface{}{"context": "GoLife", "process": p.Name, "showData": false})
+	return StopDetached, fmt.Errorf("process %s %w", p.Name, ErrStopDetached)
 }
 func (p *ManagedProcess) Restart() error {
-	if err := p.Stop(); err != nil {
+	if err := p.Stop(); err != nil && !errors.Is(err, ErrStopDetached) {
 		return err
 	}
 	return p.Start()
 }
+
+// Pause suspends the whole process tree: through the cgroup v2 freezer when the process runs in
+// a cgroup of its own, with SIGSTOP to its process group otherwise. The probes stop meanwhile.
+func (p *ManagedProcess) Pause() error {
+	p.mu.Lock()
+	switch p.state {
+	case StatePaused:
+		p.mu.Unlock()
+		return nil
+	case StateRunning:
+	default:
+		p.mu.Unlock()
+		return fmt.Errorf("process %s is not running", p.Name)
+	}
+	if p.CustomFunc != nil || p.Cmd == nil || p.Cmd.Process == nil {
+		p.mu.Unlock()
+		return fmt.Errorf("process %s runs a function and cannot be paused", p.Name)
+	}
+	pid, cgroupDir := p.Cmd.Process.Pid, ""
+	if p.cgroup != nil {
+		cgroupDir = p.cgroup.dir
+	}
+	p.mu.Unlock()
+
+	// Freezing may wait for the cgroup, so it runs without the lock.
+	frozen, err := freezeTree(pid, cgroupDir)
+	if err != nil {
+		return fmt.Errorf("pausing %s: %w", p.Name, err)
+	}
+
+	p.mu.Lock()
+	if p.Cmd == nil || p.Cmd.Process == nil || p.Cmd.Process.Pid != pid || (p.state != StateRunning && p.state != StatePaused) {
+		p.mu.Unlock()
+		_ = thawTree(pid, frozen)
+		return fmt.Errorf("process %s stopped while it was being paused", p.Name)
+	}
+	if p.state == StatePaused {
+		// A concurrent Pause got there first.
+		p.mu.Unlock()
+		return nil
+	}
+	p.frozen = frozen
+	p.setState(StatePaused)
+	p.mu.Unlock()
+
+	p.health.stop()
+	lg.Info(fmt.Sprintf("Process %s paused", p.Name), map[string]interface{}{"context": "GoLife", "process": p.Name, "cgroup": frozen, "showData": false})
+	return nil
+}
+
+// Resume lets a paused process tree run again and restarts its probes.
+func (p *ManagedProcess) Resume() error {
+	p.mu.Lock()
+	switch p.state {
+	case StateRunning:
+		p.mu.Unlock()
+		return nil
+	case StatePaused:
+	default:
+		p.mu.Unlock()
+		return fmt.Errorf("process %s is not paused", p.Name)
+	}
+	pid, frozen := p.Cmd.Process.Pid, p.frozen
+	p.mu.Unlock()
+
+	if err := thawTree(pid, frozen); err != nil {
+		return fmt.Errorf("resuming %s: %w", p.Name, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.state != StatePaused || p.Cmd == nil || p.Cmd.Process == nil || p.Cmd.Process.Pid != pid {
+		// A concurrent Resume got there first, or the process stopped meanwhile.
+		return nil
+	}
+	p.frozen = ""
+	p.setState(StateRunning)
+	p.health.start()
+	lg.Info(fmt.Sprintf("Process %s resumed", p.Name), map[string]interface{}{"context": "GoLife", "process": p.Name, "showData": false})
+	return nil
+}
+
+// Reload applies the reload policy of the running process.
+func (p *ManagedProcess) Reload() error {
+	return p.reload("manual")
+}
+func (p *ManagedProcess) IsPaused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.state == StatePaused
+}
 func (p *ManagedProcess) IsRunning() bool {
-	if p == nil || p.Cmd == nil || p.Cmd.Process == nil {
+	if p == nil {
 		return false
 	}
-	return p.Cmd.ProcessState == nil
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.isRunning()
 }
 func (p *ManagedProcess) Pid() int {
-	if p == nil || p.Cmd == nil || p.Cmd.Process == nil {
+	if p == nil {
+		return -1
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if (p.state != StateRunning && p.state != StatePaused) || p.Cmd == nil || p.Cmd.Process == nil {
 		return -1
 	}
 	return p.Cmd.Process.Pid
 }
 func (p *ManagedProcess) Wait() error {
-	if p == nil || p.Cmd == nil {
+	if p == nil {
 		return nil
 	}
-	return p.Cmd.Wait()
+	p.mu.Lock()
+	doneCh := p.doneCh
+	p.mu.Unlock()
+
+	if doneCh == nil {
+		return nil
+	}
+	<-doneCh
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastErr
 }
 func (p *ManagedProcess) String() string {
 	return fmt.Sprintf("Process %s (PID %d) is running: %t", p.Name, p.Pid(), p.IsRunning())
 }
+func (p *ManagedProcess) State() ProcessState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.state
+}
+func (p *ManagedProcess) ExitCode() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.exitCode
+}
+func (p *ManagedProcess) ExitSignal() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.exitSignal
+}
+func (p *ManagedProcess) ExitReason() ExitReason {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.exitReason
+}
+func (p *ManagedProcess) Restarts() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.sup.total
+}
+func (p *ManagedProcess) Snapshot() ProcessStatus {
+	p.mu.Lock()
+	status := ProcessStatus{
+		Name:       p.Name,
+		Command:    p.Command,
+		Args:       append([]string(nil), p.Args...),
+		Pid:        -1,
+		State:      p.state,
+		Restarts:   p.sup.total,
+		ExitCode:   p.exitCode,
+		ExitSignal: p.exitSignal,
+		ExitReason: p.exitReason,
+		DependsOn:  append([]string(nil), p.DependsOn...),
+	}
+	if !p.startedAt.IsZero() {
+		status.StartedAt = p.startedAt
+	}
+	if p.state == StateRunning || p.state == StatePaused {
+		status.Uptime = Duration(time.Since(p.startedAt))
+		if p.CustomFunc == nil && p.Cmd != nil && p.Cmd.Process != nil {
+			status.Pid = p.Cmd.Process.Pid
+		}
+	}
+	p.mu.Unlock()
+
+	status.Health = p.Health()
+	status.Resources = p.monit.usage(status.Pid)
+	return status
+}
 func (p *ManagedProcess) SetArgs(args []string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -179,12 +502,384 @@ func (p *ManagedProcess) SetCmd(cmd *exec.Cmd) {
 
 	p.Cmd = cmd
 }
+func (p *ManagedProcess) SetEnv(env []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Env = env
+}
+func (p *ManagedProcess) SetDir(dir string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Dir = dir
+}
+func (p *ManagedProcess) SetDependsOn(deps []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.DependsOn = deps
+}
 func (p *ManagedProcess) SetCustomFunc(customFunc func() error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.CustomFunc = customFunc
 }
 
+func (p *ManagedProcess) Health() HealthState {
+	return p.health.snapshot()
+}
+
+// WaitReady waits until the process reports ready or ctx is done. A process waiting to be
+// restarted is not ready yet; one that exited for good, went fatal or was stopped never will be.
+func (p *ManagedProcess) WaitReady(ctx context.Context) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if p.health.snapshot().Ready {
+			return nil
+		}
+		if state := p.State(); state != StateRunning && state != StateStarting && state != StateBackoff {
+			return fmt.Errorf("process %s is %s", p.Name, state)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("process %s not ready: %w", p.Name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+func (p *ManagedProcess) SetLivenessProbe(probe *Probe) {
+	p.health.mu.Lock()
+	defer p.health.mu.Unlock()
+
+	p.health.liveness = probe
+}
+func (p *ManagedProcess) SetReadinessProbe(probe *Probe) {
+	p.health.mu.Lock()
+	defer p.health.mu.Unlock()
+
+	p.health.readiness = probe
+}
+func (p *ManagedProcess) OnHealthChange(fn func(HealthChange)) {
+	p.health.mu.Lock()
+	defer p.health.mu.Unlock()
+
+	if fn == nil {
+		p.health.onChange = nil
+		return
+	}
+	p.health.onChange = func(previous, current HealthState) {
+		fn(HealthChange{Process: p.Name, Previous: previous, Current: current})
+	}
+}
+func (p *ManagedProcess) OnStateChange(fn func(ProcessStateChange)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onState = fn
+}
+func (p *ManagedProcess) SetRestartPolicy(policy SupervisorPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sup.policy = policy
+}
+func (p *ManagedProcess) SetStopPolicy(policy StopPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if policy.Signal == 0 {
+		policy.Signal = syscall.SIGTERM
+	}
+	p.stopPolicy = policy
+}
+
+// SetReloadPolicy sets how the process reloads. Watched files are followed from the next start.
+func (p *ManagedProcess) SetReloadPolicy(policy ReloadPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reloadPolicy = policy
+}
+func (p *ManagedProcess) GetReloadPolicy() ReloadPolicy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.reloadPolicy
+}
+
+// OnMetrics registers fn to be called with every resource sample of the process.
+func (p *ManagedProcess) OnMetrics(fn func(ResourceSample)) {
+	p.monit.sampler.mu.Lock()
+	defer p.monit.sampler.mu.Unlock()
+
+	p.monit.sampler.onSample = fn
+}
+
+// SetResourceLimits sets the limits of the process, applied from its next start.
+func (p *ManagedProcess) SetResourceLimits(limits ResourceLimits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.limits = limits
+}
+func (p *ManagedProcess) GetResourceLimits() ResourceLimits {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.limits
+}
+
+// OnReload registers fn to be called after every reload attempt.
+func (p *ManagedProcess) OnReload(fn func(ReloadEvent)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onReload = fn
+}
+
+// watchReload reloads the process when one of its watched files changes, until doneCh is
+// closed. Callers must hold p.mu.
+func (p *ManagedProcess) watchReload(doneCh chan struct{}) {
+	if len(p.reloadPolicy.Watch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	err := watchFiles(ctx, p.reloadPolicy.Watch, p.reloadPolicy.Debounce, func(path string) {
+		_ = p.reload(path)
+	})
+	if err != nil {
+		cancel()
+		lg.Warn(fmt.Sprintf("Cannot watch the files of %s: %v", p.Name, err), map[string]interface{}{"context": "GoLife", "process": p.Name, "showData": false})
+		return
+	}
+	go func() {
+		<-doneCh
+		cancel()
+	}()
+}
+func (p *ManagedProcess) SetLogConfig(config LogConfig) {
+	p.output.configure(config)
+}
+func (p *ManagedProcess) GetLogConfig() LogConfig {
+	p.output.mu.Lock()
+	defer p.output.mu.Unlock()
+
+	return p.output.config
+}
+func (p *ManagedProcess) Monit() IManagedMonit { return p.monit }
+
+// setState moves the process to state and reports the change. The callback runs with p.mu held,
+// so it must not call back into the process. Callers must hold p.mu.
+func (p *ManagedProcess) setState(state ProcessState) {
+	previous := p.state
+	p.state = state
+	if p.onState == nil || previous == state {
+		return
+	}
+	change := ProcessStateChange{Process: p.Name, Previous: previous, Current: state, Pid: -1, ExitCode: p.exitCode, ExitSignal: p.exitSignal, ExitReason: p.exitReason, Restarts: p.sup.total}
+	if (state == StateRunning || state == StatePaused) && p.CustomFunc == nil && p.Cmd != nil && p.Cmd.Process != nil {
+		change.Pid = p.Cmd.Process.Pid
+	}
+	p.onState(change)
+}
+
+// isRunning reports whether the process is alive or about to be. Callers must hold p.mu.
+func (p *ManagedProcess) isRunning() bool {
+	return p.state == StateStarting || p.state == StateRunning || p.state == StatePaused || p.state == StateBackoff
+}
+
+// spawn launches a new instance of the process and returns the function that reaps it.
+// Callers must hold p.mu.
+func (p *ManagedProcess) spawn() (func() error, error) {
+	p.setState(StateStarting)
+	p.startedAt = time.Now()
+
+	if p.CustomFunc != nil {
+		p.setState(StateRunning)
+		p.health.start()
+		done := make(chan error, 1)
+		go func(fn func() error) { done <- fn() }(p.CustomFunc)
+		return func() error { return <-done }, nil
+	}
+
+	cmd, err := p.startCommand()
+	if err != nil {
+		return nil, err
+	}
+	p.limits.applyRlimits(p.Name, cmd.Process.Pid, p.cgroup != nil)
+	p.Cmd = cmd
+	p.ProcPid = cmd.Process.Pid
+	p.ProcHandle = uintptr(cmd.Process.Pid)
+	p.setState(StateRunning)
+	p.health.start()
+	p.monit.startSampling(p.Name, cmd.Process.Pid)
+	return cmd.Wait, nil
+}
+
+// startCommand starts the command, inside a cgroup of its own when its limits need one. When
+// the group cannot be created or used the command starts without it. Callers must hold p.mu.
+func (p *ManagedProcess) startCommand() (*exec.Cmd, error) {
+	p.cgroup = nil
+	if p.limits.needsCgroup() {
+		cg, err := createCgroup(p.Name, p.limits)
+		if err != nil {
+			lg.Warn(fmt.Sprintf("Cannot create the cgroup of %s, falling back to rlimits: %v", p.Name, err), map[string]interface{}{"context": "GoLife", "process": p.Name, "showData": false})
+		} else {
+			cmd, err := p.commandIn(cg)
+			if err == nil {
+				return cmd, nil
+			}
+			cg.remove()
+			lg.Warn(fmt.Sprintf("Cannot start %s in its cgroup, falling back to rlimits: %v", p.Name, err), map[string]interface{}{"context": "GoLife", "process": p.Name, "showData": false})
+		}
+	}
+	return p.commandIn(nil)
+}
+
+// commandIn builds and starts the command, inside cg unless it is nil. Callers must hold p.mu.
+func (p *ManagedProcess) commandIn(cg *processCgroup) (*exec.Cmd, error) {
+	cmd := exec.Command(p.Command, p.Args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Dir = p.Dir
+	if len(p.Env) > 0 {
+		cmd.Env = append(os.Environ(), p.Env...)
+	}
+	if cg != nil {
+		f, err := cg.open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = int(f.Fd())
+		p.oomBase = cg.oomKills()
+	}
+	cmd.Stdout, cmd.Stderr = p.output.writers(p.Name)
+	cmd.WaitDelay = 2 * time.Second
+	if err := cmd.Start(); err != nil {
+		p.output.close()
+		return nil, err
+	}
+	p.cgroup = cg
+	return cmd, nil
+}
+
+// supervise reaps the child, records its exit status and restarts it according to the policy
+// until the process is stopped, exits for good or goes fatal.
+func (p *ManagedProcess) supervise(wait func() error, stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	for {
+		err := wait()
+		p.mu.Lock()
+		detached := p.doneCh != doneCh
+		p.mu.Unlock()
+		if detached {
+			lg.Info(fmt.Sprintf("Process %s returned after it was left behind", p.Name), map[string]interface{}{"context": "GoLife", "process": p.Name, "showData": false})
+			return
+		}
+		p.health.stop()
+		p.monit.stopSampling()
+		p.output.close()
+
+		p.mu.Lock()
+		p.exitCode, p.exitSignal = exitStatus(err)
+		p.lastErr = err
+		uptime := time.Since(p.startedAt)
+		oomKilled := false
+		if p.cgroup != nil {
+			oomKilled = p.cgroup.oomKills() > p.oomBase
+			p.cgroup.remove()
+			p.cgroup = nil
+		}
+		p.exitReason = exitReasonOf(p.exitCode, p.exitSignal, oomKilled)
+		if oomKilled {
+			lg.Warn(fmt.Sprintf("Process %s was killed for exceeding its memory limit", p.Name), map[string]interface{}{"context": "GoLife", "process": p.Name, "memoryMax": p.limits.MemoryMax, "showData": false})
+		}
+
+		select {
+		case <-stopCh:
+			if !oomKilled {
+				p.exitReason = ExitStopped
+			}
+			p.setState(StateStopped)
+			p.mu.Unlock()
+			lg.Info(fmt.Sprintf("Process %s stopped", p.Name), map[string]interface{}{"context": "GoLife", "process": p.Name, "exitCode": p.exitCode, "showData": false})
+			return
+		default:
+		}
+
+		// A run killed by its liveness probe is restarted whatever the restart policy.
+		unhealthy := p.unhealthy
+		p.unhealthy = false
+		delay, state := p.sup.next(p.exitCode, uptime, time.Now(), unhealthy)
+		p.setState(state)
+		name, exitCode, restarts := p.Name, p.exitCode, p.sup.total
+		p.mu.Unlock()
+
+		switch state {
+		case StateExited:
+			lg.Info(fmt.Sprintf("Process %s exited with code %d", name, exitCode), map[string]interface{}{"context": "GoLife", "process": name, "exitCode": exitCode, "showData": false})
+			return
+		case StateFatal:
+			lg.Error(fmt.Sprintf("Process %s exceeded its restart limit, giving up", name), map[string]interface{}{"context": "GoLife", "process": name, "exitCode": exitCode, "restarts": restarts, "showData": true})
+			return
+		}
+
+		lg.Warn(fmt.Sprintf("Process %s exited with code %d, restarting in %s", name, exitCode, delay), map[string]interface{}{"context": "GoLife", "process": name, "exitCode": exitCode, "restarts": restarts, "showData": false})
+		timer := time.NewTimer(delay)
+		select {
+		case <-stopCh:
+			timer.Stop()
+			p.mu.Lock()
+			p.setState(StateStopped)
+			p.mu.Unlock()
+			return
+		case <-timer.C:
+		}
+
+		p.mu.Lock()
+		select {
+		case <-stopCh:
+			p.setState(StateStopped)
+			p.mu.Unlock()
+			return
+		default:
+		}
+		next, spawnErr := p.spawn()
+		if spawnErr != nil {
+			lg.Error(fmt.Sprintf("Error restarting process %s: %v", p.Name, spawnErr), map[string]interface{}{"context": "GoLife", "process": p.Name, "showData": true})
+			next = func() error { return spawnErr }
+		}
+		p.mu.Unlock()
+		wait = next
+	}
+}
+
+// livenessFailed kills the current child and has the supervisor restart it, within the
+// restart limit of the policy, even when the policy would not restart a failed run.
+func (p *ManagedProcess) livenessFailed() {
+	p.mu.Lock()
+	pid := -1
+	if p.CustomFunc == nil && p.state == StateRunning && p.Cmd != nil && p.Cmd.Process != nil {
+		pid = p.Cmd.Process.Pid
+		p.unhealthy = true
+	}
+	name := p.Name
+	p.mu.Unlock()
+
+	if pid <= 0 {
+		lg.Warn(fmt.Sprintf("Liveness probe of %s failed but it cannot be killed", name), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
+		return
+	}
+	lg.Warn(fmt.Sprintf("Liveness probe of %s failed, killing it", name), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
+	if err := signalGroup(pid, syscall.SIGKILL); err != nil {
+		lg.Error(fmt.Sprintf("Error killing unhealthy process %s: %v", name, err), map[string]interface{}{"context": "GoLife", "process": name, "showData": true})
+	}
+}
+
 func NewManagedProcess(name string, command string, args []string, wait bool, customFunc func() error) IManagedProcess {
 	envs := os.Environ()
 	envPath := os.Getenv("PATH")
@@ -210,6 +905,13 @@ func NewManagedProcess(name string, command string, args []string, wait bool, cu
 		WaitFor:    wait,
 		CustomFunc: customFunc,
 		mu:         sync.Mutex{},
+		sup:        supervisor{policy: DefaultSupervisorPolicy(RestartNever)},
+		stopPolicy: DefaultStopPolicy(),
+		state:      StateStopped,
+		health:     newHealthMonitor(),
+		output:     newOutputCapture(name, LogConfig{TailLines: DefaultTailLines}),
 	}
+	mgrProc.monit = &ManagedMonit{output: mgrProc.output, reload: mgrProc.Reload, sampler: newResourceSampler()}
+	mgrProc.health.onFailure = mgrProc.livenessFailed
 	return &mgrProc
 }
@@ -0,0 +1,196 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	lg "github.com/rafa-mori/logz"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ReloadStrategy tells how a process reloads its configuration without a restart.
+type ReloadStrategy string
+
+const (
+	// ReloadSignal sends a signal, SIGHUP by default, to the process group.
+	ReloadSignal ReloadStrategy = "signal"
+	// ReloadExec runs a command, such as "nginx -s reload".
+	ReloadExec ReloadStrategy = "exec"
+	// ReloadCallback calls a Go function.
+	ReloadCallback ReloadStrategy = "callback"
+)
+
+// Defaults applied to a ReloadPolicy.
+const (
+	DefaultReloadTimeout  = 30 * time.Second
+	DefaultReloadDebounce = 500 * time.Millisecond
+)
+
+// ReloadPolicy defines how a process reloads and which files trigger a reload when they change.
+type ReloadPolicy struct {
+	Strategy ReloadStrategy
+	Signal   syscall.Signal              // Sent by ReloadSignal (default SIGHUP)
+	Command  []string                    // Run by ReloadExec, with $GOLIFE_PROCESS and $GOLIFE_PID set
+	Callback func(IManagedProcess) error // Called by ReloadCallback
+	Timeout  time.Duration               // Bounds the command of ReloadExec
+	Watch    []string                    // Files reloaded on change while the process runs
+	Debounce time.Duration               // A burst of changes closer than this triggers a single reload
+}
+
+// ReloadEvent reports a reload attempt. Trigger is "manual" or the changed file.
+type ReloadEvent struct {
+	Process  string         `json:"process"`
+	Strategy ReloadStrategy `json:"strategy"`
+	Trigger  string         `json:"trigger"`
+	Duration time.Duration  `json:"duration"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// reload applies the reload policy of the process and reports the attempt.
+func (p *ManagedProcess) reload(trigger string) error {
+	p.mu.Lock()
+	policy, onReload := p.reloadPolicy, p.onReload
+	name, dir, env := p.Name, p.Dir, p.Env
+	running := p.state == StateRunning
+	pid := -1
+	if running && p.CustomFunc == nil && p.Cmd != nil && p.Cmd.Process != nil {
+		pid = p.Cmd.Process.Pid
+	}
+	p.mu.Unlock()
+
+	if policy.Strategy == "" {
+		return fmt.Errorf("process %s has no reload strategy", name)
+	}
+	if !running {
+		return fmt.Errorf("process %s is not running", name)
+	}
+
+	started := time.Now()
+	var err error
+	switch policy.Strategy {
+	case ReloadSignal:
+		sig := policy.Signal
+		if sig == 0 {
+			sig = syscall.SIGHUP
+		}
+		if pid <= 0 {
+			err = fmt.Errorf("process %s runs a function and cannot be signalled", name)
+		} else {
+			err = signalGroup(pid, sig)
+		}
+	case ReloadExec:
+		err = runReloadCommand(policy, name, pid, dir, env)
+	case ReloadCallback:
+		if policy.Callback == nil {
+			err = fmt.Errorf("process %s has no reload callback", name)
+		} else {
+			err = policy.Callback(p)
+		}
+	default:
+		err = fmt.Errorf("unknown reload strategy %q", policy.Strategy)
+	}
+
+	event := ReloadEvent{Process: name, Strategy: policy.Strategy, Trigger: trigger, Duration: time.Since(started)}
+	if err != nil {
+		err = fmt.Errorf("reloading %s: %w", name, err)
+		event.Error = err.Error()
+		lg.Error(err.Error(), map[string]interface{}{"context": "GoLife", "process": name, "trigger": trigger, "showData": true})
+	} else {
+		lg.Info(fmt.Sprintf("Process %s reloaded (%s)", name, policy.Strategy), map[string]interface{}{"context": "GoLife", "process": name, "trigger": trigger, "showData": false})
+	}
+	if onReload != nil {
+		onReload(event)
+	}
+	return err
+}
+
+// runReloadCommand runs the command of an exec reload and returns its output on failure.
+func runReloadCommand(policy ReloadPolicy, name string, pid int, dir string, env []string) error {
+	if len(policy.Command) == 0 {
+		return fmt.Errorf("no reload command")
+	}
+	timeout := policy.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReloadTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, policy.Command[0], policy.Command[1:]...)
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), env...), "GOLIFE_PROCESS="+name, fmt.Sprintf("GOLIFE_PID=%d", pid))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// watchFiles calls fn with the last changed file once per burst of changes to files, until ctx
+// is done. The parent directories are watched so that files replaced by a rename are followed.
+func watchFiles(ctx context.Context, files []string, debounce time.Duration, fn func(path string)) error {
+	if debounce <= 0 {
+		debounce = DefaultReloadDebounce
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	targets := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			_ = watcher.Close()
+			return err
+		}
+		targets[abs] = true
+		dirs[filepath.Dir(abs)] = true
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("watching %s: %w", dir, err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+		defer timer.Stop()
+		var changed string
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !targets[filepath.Clean(ev.Name)] || !ev.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+					continue
+				}
+				changed = ev.Name
+				timer.Reset(debounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				lg.Warn(fmt.Sprintf("File watcher error: %v", err), map[string]interface{}{"context": "GoLife", "showData": false})
+			case <-timer.C:
+				fn(changed)
+			}
+		}
+	}()
+	return nil
+}
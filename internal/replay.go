@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	l "github.com/rafa-mori/logz"
+)
+
+type replayKey struct{}
+
+// IsReplay reports whether a handler runs with ctx because ReplayFrom replays a journaled
+// trigger. Handlers rebuild their state on replay but should not repeat side effects.
+func IsReplay(ctx context.Context) bool {
+	replay, _ := ctx.Value(replayKey{}).(bool)
+	return replay
+}
+
+// SetJournal makes the lifecycle append the events it publishes to j (see JournalConfig.Topics).
+// The events are queued in publishing order and written by the journal on its own goroutine, so
+// publishing only waits for the disk when the queue is full. Under FsyncAlways the events are
+// appended as they are published instead, so that they are on the disk once Publish returns.
+func (lm *LifeCycle) SetJournal(j *Journal) {
+	lm.journal.Store(j)
+	lm.journalOnce.Do(func() {
+		lm.bus.OnPublish(func(ev LifecycleEvent) {
+			j := lm.journal.Load()
+			if j == nil || !j.Accepts(ev) {
+				return
+			}
+			if j.cfg.Fsync != FsyncAlways {
+				_ = j.AppendAsync(ev) // Fails only once the journal is closed
+				return
+			}
+			if _, err := j.Append(ev); err != nil && !errors.Is(err, ErrJournalClosed) {
+				l.Error(fmt.Sprintf("Fail to journal event %s: %v", ev.Type, err), map[string]interface{}{"context": "GoLife", "showData": false})
+			}
+		})
+	})
+}
+
+// Journal returns the journal set by SetJournal, if any.
+func (lm *LifeCycle) Journal() *Journal { return lm.journal.Load() }
+
+// ReplayFrom rebuilds the lifecycle state from the journal records from offset on. The current
+// and last stages and the transition history are restored without running the enter and exit
+// hooks, and every journaled trigger is handed again to the handlers of its stage, or to the
+// subscriptions of the manager, with a context for which IsReplay reports true. Replayed events
+// are not published again. It returns the offset following the last record replayed.
+func (lm *LifeCycle) ReplayFrom(offset uint64) (uint64, error) {
+	j := lm.Journal()
+	if j == nil {
+		return offset, errors.New("no journal to replay")
+	}
+	ctx := context.WithValue(context.Background(), replayKey{}, true)
+	next := offset
+	replayed := 0
+	err := j.Read(offset, func(rec JournalRecord) error {
+		next = rec.Offset + 1
+		replayed++
+		ev := rec.Event
+		switch ev.Type {
+		case EventStageEntered:
+			lm.replayStage(ev)
+		case EventTriggered:
+			// The subscriptions of the manager have records of their own, without a stage.
+			if lm.Handles(ev.Stage, ev.Event) {
+				_ = lm.trigger(ctx, ev.Stage, ev.Event, ev.Data, false) // Failures are logged by trigger
+			}
+		}
+		return nil
+	})
+	l.Info(fmt.Sprintf("Replayed %d journal records from offset %d", replayed, offset), map[string]interface{}{"context": "GoLife", "showData": false})
+	return next, err
+}
+
+// replayStage restores the stage state recorded by a stage.entered event.
+func (lm *LifeCycle) replayStage(ev LifecycleEvent) {
+	to := lm.GetStage(ev.Stage)
+	if to == nil {
+		return
+	}
+	var transition StageTransition
+	if raw, err := json.Marshal(ev.Data); err == nil {
+		_ = json.Unmarshal(raw, &transition)
+	}
+
+	lm.stagesMu.Lock()
+	lm.currentStage = to.ID()
+	entered := transition.To == ev.Stage
+	if id := lm.stageIDLocked(transition.From); entered && id != "" {
+		lm.lastStage = id
+	}
+	lm.stagesMu.Unlock()
+	if !entered {
+		return // Entered by DefineStage
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.history = append(lm.history, transition)
+	if limit := lm.historyLimit; limit > 0 && len(lm.history) > limit {
+		lm.history = append([]StageTransition(nil), lm.history[len(lm.history)-limit:]...)
+	}
+}
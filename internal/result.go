@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"sync"
+)
+
+// EventResult is the future of an event triggered by TriggerAsync or TriggerAll.
+type EventResult struct {
+	Stage string
+	Event string
+
+	mu     sync.Mutex
+	done   chan struct{}
+	value  interface{}
+	err    error
+	cancel context.CancelFunc
+}
+
+func newEventResult(stage, event string, cancel context.CancelFunc) *EventResult {
+	return &EventResult{Stage: stage, Event: event, done: make(chan struct{}), cancel: cancel}
+}
+
+// Done is closed once the handler returned.
+func (r *EventResult) Done() <-chan struct{} { return r.done }
+
+// Wait blocks until the handler returned or ctx is done, and returns the error of the handler
+// or of ctx.
+func (r *EventResult) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Err blocks until the handler returned and returns its error.
+func (r *EventResult) Err() error {
+	<-r.done
+	return r.err
+}
+
+// Result blocks until the handler returned and returns the value it set with SetEventResult.
+func (r *EventResult) Result() interface{} {
+	<-r.done
+	return r.value
+}
+
+// Cancel cancels the context of the handler.
+func (r *EventResult) Cancel() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+// complete records the outcome of the handler and releases the waiters.
+func (r *EventResult) complete(err error) {
+	r.mu.Lock()
+	r.err = err
+	r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	close(r.done)
+}
+
+func (r *EventResult) set(value interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.value = value
+}
+
+type eventResultKey struct{}
+
+func eventResultFrom(ctx context.Context) *EventResult {
+	r, _ := ctx.Value(eventResultKey{}).(*EventResult)
+	return r
+}
+
+// SetEventResult sets the value returned by the Result of the EventResult of the handler running
+// with ctx. It reports false for handlers run by Trigger, which have no EventResult.
+func SetEventResult(ctx context.Context, value interface{}) bool {
+	r := eventResultFrom(ctx)
+	if r == nil {
+		return false
+	}
+	r.set(value)
+	return true
+}
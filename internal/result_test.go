@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newFutureLifecycle registers the stages with handler as the handler of "work"; pooled stages get
+// a worker pool.
+func newFutureLifecycle(t *testing.T, handler EventHandler, pooled bool, stages ...string) LifeCycleManager {
+	t.Helper()
+	lm := NewLifecycleManager(nil, nil, nil, nil, nil, nil)
+	for _, name := range stages {
+		stage := NewStage(name, "", "").Handle("work", handler)
+		if pooled {
+			stage.AutoScale(2)
+		}
+		if err := lm.RegisterStage(stage); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return lm
+}
+
+func TestTriggerAsync(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name    string
+		handler EventHandler
+		stage   string
+		cancel  bool // Cancel the future once the handler runs
+		value   interface{}
+		wantErr error
+		errText string // Part of the expected error when wantErr cannot be matched
+	}{
+		{
+			name:    "value",
+			handler: func(ctx context.Context, call EventCall) error { SetEventResult(ctx, call.Data); return nil },
+			value:   "payload",
+		},
+		{name: "error", handler: func(ctx context.Context, call EventCall) error { return boom }, wantErr: boom},
+		{name: "panic", handler: func(ctx context.Context, call EventCall) error { panic("oops") }, errText: "panic: oops"},
+		{
+			name:    "cancelled",
+			handler: func(ctx context.Context, call EventCall) error { <-ctx.Done(); return ctx.Err() },
+			cancel:  true,
+			wantErr: context.Canceled,
+		},
+		{name: "unknown stage", stage: "nowhere", errText: "stage nowhere not found"},
+	}
+	for _, pooled := range []bool{false, true} {
+		for _, tt := range tests {
+			name := tt.name
+			if pooled {
+				name += " on a pool"
+			}
+			t.Run(name, func(t *testing.T) {
+				started := make(chan struct{})
+				var once sync.Once
+				handler := func(ctx context.Context, call EventCall) error {
+					once.Do(func() { close(started) })
+					return tt.handler(ctx, call)
+				}
+				lm := newFutureLifecycle(t, handler, pooled, "boot")
+				stage := tt.stage
+				if stage == "" {
+					stage = "boot"
+				}
+
+				result := lm.TriggerAsync(stage, "work", "payload")
+				if result.Stage != stage || result.Event != "work" {
+					t.Fatalf("result of %s/%s", result.Stage, result.Event)
+				}
+				if tt.cancel {
+					<-started
+					result.Cancel()
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				err := result.Wait(ctx)
+				switch {
+				case tt.errText != "" && (err == nil || !strings.Contains(err.Error(), tt.errText)):
+					t.Fatalf("err = %v, want one containing %q", err, tt.errText)
+				case tt.errText == "" && !errors.Is(err, tt.wantErr):
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				select {
+				case <-result.Done():
+				default:
+					t.Fatal("Done is still open after Wait returned")
+				}
+				if got := result.Err(); got != err {
+					t.Fatalf("Err = %v, Wait returned %v", got, err)
+				}
+				if got := result.Result(); got != tt.value {
+					t.Fatalf("Result = %v, want %v", got, tt.value)
+				}
+			})
+		}
+	}
+}
+
+func TestEventResultWaitTimeout(t *testing.T) {
+	lm := newFutureLifecycle(t, func(ctx context.Context, call EventCall) error { <-ctx.Done(); return ctx.Err() }, false, "boot")
+	result := lm.TriggerAsync("boot", "work", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := result.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, want %v", err, context.DeadlineExceeded)
+	}
+	// The handler keeps running until it is cancelled.
+	result.Cancel()
+	if err := result.Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Err after Cancel = %v, want %v", err, context.Canceled)
+	}
+	if SetEventResult(context.Background(), "value") {
+		t.Fatal("SetEventResult reported a future outside of a handler")
+	}
+}
+
+func TestTriggerAll(t *testing.T) {
+	boom := errors.New("boom")
+	var mu sync.Mutex
+	var order []string
+	staged := 3 // Stage handlers expected before the subscription
+	handler := func(ctx context.Context, call EventCall) error {
+		mu.Lock()
+		order = append(order, call.Stage)
+		mu.Unlock()
+		if call.Stage == "db" {
+			return boom
+		}
+		return nil
+	}
+	lm := newFutureLifecycle(t, handler, false, "web", "api", "db")
+	if err := lm.RegisterStage(NewStage("idle", "", "")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lm.Subscribe("work", func(ctx context.Context, call EventCall) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(order) != staged {
+			t.Errorf("subscription ran after %v, want every stage first", order)
+		}
+		order = append(order, "subscription")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := lm.TriggerAll(context.Background(), "work", nil)
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "stage db: boom") {
+		t.Fatalf("err = %v, want the error of db", err)
+	}
+	stages := make([]string, 0, len(results))
+	for _, result := range results {
+		stages = append(stages, result.Stage)
+	}
+	if got, want := strings.Join(stages, ","), "api,db,web,"; got != want {
+		t.Fatalf("results of %q, want %q", got, want)
+	}
+	if len(order) != 4 || order[3] != "subscription" {
+		t.Fatalf("handlers ran as %v", order)
+	}
+
+	// Only the stages given, in their order.
+	order, staged = nil, 2
+	results, err = lm.TriggerAll(context.Background(), "work", nil, "web", "api")
+	if err != nil || len(results) != 3 || results[0].Stage != "web" || results[1].Stage != "api" || results[2].Stage != "" {
+		t.Fatalf("TriggerAll on web and api = %v, %v", results, err)
+	}
+}
+
+func TestTriggerAllCancelled(t *testing.T) {
+	lm := newFutureLifecycle(t, func(ctx context.Context, call EventCall) error { <-ctx.Done(); return ctx.Err() }, true, "web", "api")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	results, err := lm.TriggerAll(ctx, "work", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	for _, result := range results {
+		select {
+		case <-result.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("handler of %s still running after its context was done", result.Stage)
+		}
+	}
+}
@@ -2,598 +2,511 @@ package internal
 
 import (
 	"context"
-	// s "github.com/rafa-mori/gdbase/services"
-	// "github.com/rafa-mori/logz"
-	// "os"
-	// "path/filepath"
+	"errors"
+	"fmt"
+	lg "github.com/rafa-mori/logz"
+	"runtime/debug"
 	"sync"
 	"time"
 )
 
+// GoroutineFunc is the body of a managed goroutine. It must return once ctx is done and should
+// call Checkpoint(ctx) at the points where it is safe to pause.
+type GoroutineFunc func(ctx context.Context) error
+
+// DefaultMailboxSize is the capacity of the inbox and outbox of a managed goroutine and of the
+// mailbox of a stage.
+const DefaultMailboxSize = 64
+
+// ErrMailboxFull is returned when a message does not fit in a full mailbox.
+var ErrMailboxFull = errors.New("mailbox is full")
+
 type IManagedGoroutine interface {
+	GetName() string
+	GetFunc() GoroutineFunc
+	GetTimeout() time.Duration
+	GetDeadline() time.Time
+	GetStopGrace() time.Duration
+
 	Start() error
 	Stop() error
+	Shutdown() (StopOutcome, error)
+	Restart() error
 	Pause() error
 	Resume() error
 	IsRunning() bool
+	IsPaused() bool
 	Wait() error
 	String() string
+	State() ProcessState
+	Err() error
+	Snapshot() ProcessStatus
 
-	Send(msg interface{})
+	Send(msg interface{}) error
 	Receive() interface{}
 
-	SetArgs(args []string)
-	SetCommand(command string)
 	SetName(name string)
-	SetWaitFor(wait bool)
-
-	SetGoroutineFn(fn func())
-	SetGoroutineCh(ch chan struct{})
-	SetGoroutineErr(err error)
-	SetGoroutineDone(done bool)
-	SetGoroutineWG(wg sync.WaitGroup)
-	SetGoroutineMu(mu sync.Mutex)
-	SetGoroutineOnce(once sync.Once)
-	SetGoroutineCond(cond *sync.Cond)
-	SetGoroutineLock(lock sync.Mutex)
-	SetGoroutineDoneCh(ch chan struct{})
-	SetGoroutineErrCh(ch chan error)
-	SetGoroutineCancel(cancel func())
-	SetGoroutineCtx(ctx context.Context)
-	SetGoroutineCancelFn(cancelFn context.CancelFunc)
-	SetGoroutineTimeout(timeout time.Duration)
-	SetGoroutineDeadline(deadline time.Time)
-	SetGoroutineDeadlineSet(set bool)
-
-	GetGoroutineFn() func()
-	GetGoroutineCh() chan struct{}
-	GetGoroutineErr() error
-	GetGoroutineDone() bool
-	GetGoroutineWG() sync.WaitGroup
-	GetGoroutineMu() sync.Mutex
-	GetGoroutineOnce() sync.Once
-	GetGoroutineCond() *sync.Cond
-	GetGoroutineLock() sync.Mutex
-	GetGoroutineDoneCh() chan struct{}
-	GetGoroutineErrCh() chan error
-	GetGoroutineCancel() func()
-	GetGoroutineCtx() context.Context
-	GetGoroutineCancelFn() context.CancelFunc
-	GetGoroutineTimeout() time.Duration
-	GetGoroutineDeadline() time.Time
-	GetGoroutineDeadlineSet() bool
-
-	Copy() IManagedGoroutine
+	SetFunc(fn GoroutineFunc)
+	SetTimeout(timeout time.Duration)
+	SetDeadline(deadline time.Time)
+	SetStopGrace(grace time.Duration)
+	OnStateChange(fn func(ProcessStateChange))
 }
 
+// ManagedGoroutine runs a GoroutineFunc under the same lifecycle as an OS process: it can be
+// started, paused at its checkpoints, resumed and stopped by cancelling its context. A panic in
+// the function is recovered and reported as a *PanicError.
 type ManagedGoroutine struct {
-	// Processo gerenciado (terceira opção - goroutine)
-	goroutineFn          func()
-	goroutineCh          chan struct{}
-	goroutineErr         error
-	goroutineDone        bool
-	goroutineWG          sync.WaitGroup
-	goroutineMu          sync.Mutex
-	goroutineOnce        sync.Once
-	goroutineCond        *sync.Cond
-	goroutineLock        sync.Mutex
-	goroutineDoneCh      chan struct{}
-	goroutineErrCh       chan error
-	goroutineCancel      func()
-	goroutineCtx         context.Context
-	goroutineCancelFn    context.CancelFunc
-	goroutineTimeout     time.Duration
-	goroutineDeadline    time.Time
-	goroutineDeadlineSet bool
-}
-
-func (m *ManagedGoroutine) Start() error {
-	if m == nil {
-		return nil
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+	name      string
+	fn        GoroutineFunc
+	timeout   time.Duration // Bounds every run when > 0
+	deadline  time.Time     // Ends every run at this time when set
+	stopGrace time.Duration // Time Stop waits for the function to return
+	mu        sync.Mutex
 
-	if m.IsRunning() {
-		return nil
-	}
-
-	m.goroutineWG.Add(1)
-	go func() {
-		defer m.goroutineWG.Done()
-		m.goroutineFn()
-		logActivity("Goroutine started")
-	}()
-
-	return nil
+	state     ProcessState
+	startedAt time.Time
+	runs      int
+	err       error
+	control   *goroutineControl
+	cancel    context.CancelFunc
+	doneCh    chan struct{} // Closed when the current run returns
+	onState   func(ProcessStateChange)
+	inbox     chan interface{}
+	outbox    chan interface{}
 }
-func (m *ManagedGoroutine) Stop() error {
-	if m == nil {
-		return nil
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	if !m.IsRunning() {
-		return nil
-	}
+// PanicError is the error of a run that panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
 
-	m.goroutineCancelFn()
-	m.goroutineWG.Wait()
-	logActivity("Goroutine stopped")
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
 
-	return nil
+// goroutineControl is carried by the context of a run and backs the checkpoint API.
+type goroutineControl struct {
+	mu       sync.Mutex
+	paused   bool
+	resumeCh chan struct{} // Closed by resume
+	inbox    chan interface{}
+	outbox   chan interface{}
 }
-func (m *ManagedGoroutine) Pause() error {
-	if m == nil {
-		return nil
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	if !m.IsRunning() {
-		return nil
-	}
+type goroutineControlKey struct{}
 
-	m.goroutineCancelFn()
-	logActivity("Goroutine paused")
+func controlFrom(ctx context.Context) *goroutineControl {
+	c, _ := ctx.Value(goroutineControlKey{}).(*goroutineControl)
+	return c
+}
 
-	return nil
+func (c *goroutineControl) pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.paused {
+		c.paused = true
+		c.resumeCh = make(chan struct{})
+	}
 }
-func (m *ManagedGoroutine) Resume() error {
-	if m == nil {
-		return nil
+func (c *goroutineControl) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.paused {
+		c.paused = false
+		close(c.resumeCh)
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+}
 
-	if m.IsRunning() {
-		return nil
+// Checkpoint blocks while the goroutine running with ctx is paused. It returns ctx.Err() once
+// the context is done, so a loop can stop on its result. Outside a managed goroutine it only
+// reports ctx.Err().
+func Checkpoint(ctx context.Context) error {
+	c := controlFrom(ctx)
+	if c == nil {
+		return ctx.Err()
 	}
+	c.mu.Lock()
+	paused, resumeCh := c.paused, c.resumeCh
+	c.mu.Unlock()
 
-	m.goroutineWG.Add(1)
-	go func() {
-		defer m.goroutineWG.Done()
-		m.goroutineFn()
-		logActivity("Goroutine resumed")
-	}()
+	if paused {
+		select {
+		case <-resumeCh:
+		case <-ctx.Done():
+		}
+	}
+	return ctx.Err()
+}
 
+// Inbox returns the channel of the messages sent to the goroutine running with ctx, or nil
+// outside a managed goroutine.
+func Inbox(ctx context.Context) <-chan interface{} {
+	if c := controlFrom(ctx); c != nil {
+		return c.inbox
+	}
 	return nil
 }
-func (m *ManagedGoroutine) IsRunning() bool {
-	if m == nil {
-		return false
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	return m.goroutineDone
-}
-func (m *ManagedGoroutine) Wait() error {
-	if m == nil {
+// Emit hands msg to the Receive side of the goroutine running with ctx. It blocks while the
+// outbox is full and fails when ctx is done or outside a managed goroutine.
+func Emit(ctx context.Context, msg interface{}) error {
+	c := controlFrom(ctx)
+	if c == nil {
+		return fmt.Errorf("not running in a managed goroutine")
+	}
+	select {
+	case c.outbox <- msg:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+}
 
-	m.goroutineWG.Wait()
+func (g *ManagedGoroutine) GetName() string { return g.name }
+func (g *ManagedGoroutine) GetFunc() GoroutineFunc {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return nil
+	return g.fn
 }
-func (m *ManagedGoroutine) String() string {
-	if m == nil {
-		return ""
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+func (g *ManagedGoroutine) GetTimeout() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return ""
+	return g.timeout
 }
-func (m *ManagedGoroutine) Send(msg interface{}) {
-	if m == nil {
-		return
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+func (g *ManagedGoroutine) GetDeadline() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.deadline
+}
+func (g *ManagedGoroutine) GetStopGrace() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
+	return g.stopGrace
 }
-func (m *ManagedGoroutine) Receive() interface{} {
-	if m == nil {
+func (g *ManagedGoroutine) Start() error {
+	if g == nil {
 		return nil
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return nil
-}
-func (m *ManagedGoroutine) SetArgs(args []string) {
-	if m == nil {
-		return
+	if g.isRunning() {
+		return fmt.Errorf("goroutine %s is already running", g.name)
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-}
-func (m *ManagedGoroutine) SetCommand(command string) {
-	if m == nil {
-		return
+	if g.fn == nil {
+		return fmt.Errorf("goroutine %s has no function", g.name)
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-}
-func (m *ManagedGoroutine) SetName(name string) {
-	if m == nil {
-		return
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if g.timeout > 0 {
+		ctx, cancel = withCancelChain(ctx, cancel, func(parent context.Context) (context.Context, context.CancelFunc) {
+			return context.WithTimeout(parent, g.timeout)
+		})
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-}
-func (m *ManagedGoroutine) SetWaitFor(wait bool) {
-	if m == nil {
-		return
+	if !g.deadline.IsZero() {
+		ctx, cancel = withCancelChain(ctx, cancel, func(parent context.Context) (context.Context, context.CancelFunc) {
+			return context.WithDeadline(parent, g.deadline)
+		})
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-}
-func (m *ManagedGoroutine) SetGoroutineFn(fn func()) {
-	if m == nil {
-		return
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+	g.control = &goroutineControl{inbox: g.inbox, outbox: g.outbox}
+	ctx = context.WithValue(ctx, goroutineControlKey{}, g.control)
 
-	m.goroutineFn = fn
-}
-func (m *ManagedGoroutine) SetGoroutineCh(ch chan struct{}) {
-	if m == nil {
-		return
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+	g.cancel = cancel
+	g.doneCh = make(chan struct{})
+	g.err = nil
+	g.runs++
+	g.startedAt = time.Now()
+	g.setState(StateRunning)
+	go g.run(ctx, g.fn, g.doneCh)
 
-	m.goroutineCh = ch
+	lg.Info(fmt.Sprintf("Goroutine %s started", g.name), map[string]interface{}{"context": "GoLife", "process": g.name, "showData": false})
+	return nil
 }
-func (m *ManagedGoroutine) SetGoroutineErr(err error) {
-	if m == nil {
-		return
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	m.goroutineErr = err
-}
-func (m *ManagedGoroutine) SetGoroutineDone(done bool) {
-	if m == nil {
-		return
+// withCancelChain derives a context with wrap and returns a cancel func releasing both levels.
+func withCancelChain(ctx context.Context, cancel context.CancelFunc, wrap func(context.Context) (context.Context, context.CancelFunc)) (context.Context, context.CancelFunc) {
+	child, childCancel := wrap(ctx)
+	return child, func() {
+		childCancel()
+		cancel()
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	m.goroutineDone = done
 }
-func (m *ManagedGoroutine) SetGoroutineWG(wg sync.WaitGroup) {
-	if m == nil {
-		return
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	m.goroutineWG = wg
-}
-func (m *ManagedGoroutine) SetGoroutineMu(mu sync.Mutex) {
-	if m == nil {
-		return
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+// run executes fn and records how it ended. A run ended by Stop leaves the goroutine stopped,
+// any other return leaves it exited with the returned error.
+func (g *ManagedGoroutine) run(ctx context.Context, fn GoroutineFunc, doneCh chan struct{}) {
+	defer close(doneCh)
 
-	m.goroutineMu = mu
-}
-func (m *ManagedGoroutine) SetGoroutineOnce(once sync.Once) {
-	if m == nil {
-		return
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+		return fn(ctx)
+	}()
+	if err == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = ctx.Err()
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	m.goroutineOnce = once
-}
-func (m *ManagedGoroutine) SetGoroutineCond(cond *sync.Cond) {
-	if m == nil {
-		return
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	m.goroutineCond = cond
-}
-func (m *ManagedGoroutine) SetGoroutineLock(lock sync.Mutex) {
-	if m == nil {
+	if g.doneCh != doneCh {
 		return
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	m.goroutineLock = lock
-}
-func (m *ManagedGoroutine) SetGoroutineDoneCh(ch chan struct{}) {
-	if m == nil {
+	g.cancel()
+	if g.state == StateStopped {
+		lg.Info(fmt.Sprintf("Goroutine %s stopped", g.name), map[string]interface{}{"context": "GoLife", "process": g.name, "showData": false})
 		return
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	m.goroutineDoneCh = ch
-}
-func (m *ManagedGoroutine) SetGoroutineErrCh(ch chan error) {
-	if m == nil {
-		return
+	g.err = err
+	g.setState(StateExited)
+	if err != nil {
+		lg.Error(fmt.Sprintf("Goroutine %s failed: %v", g.name, err), map[string]interface{}{"context": "GoLife", "process": g.name, "showData": true})
+	} else {
+		lg.Info(fmt.Sprintf("Goroutine %s finished", g.name), map[string]interface{}{"context": "GoLife", "process": g.name, "showData": false})
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	m.goroutineErrCh = ch
 }
-func (m *ManagedGoroutine) SetGoroutineCancel(cancel func()) {
-	if m == nil {
-		return
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	m.goroutineCancel = cancel
+func (g *ManagedGoroutine) Stop() error {
+	_, err := g.Shutdown()
+	return err
 }
-func (m *ManagedGoroutine) SetGoroutineCtx(ctx context.Context) {
-	if m == nil {
-		return
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	m.goroutineCtx = ctx
-}
-func (m *ManagedGoroutine) SetGoroutineCancelFn(cancelFn context.CancelFunc) {
-	if m == nil {
-		return
+// Shutdown cancels the context of the run and waits up to the stop grace for the function to
+// return. A function that ignores its context is left behind.
+func (g *ManagedGoroutine) Shutdown() (StopOutcome, error) {
+	if g == nil {
+		return StopNotRunning, nil
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	m.goroutineCancelFn = cancelFn
-}
-func (m *ManagedGoroutine) SetGoroutineTimeout(timeout time.Duration) {
-	if m == nil {
-		return
+	g.mu.Lock()
+	if !g.isRunning() {
+		g.mu.Unlock()
+		return StopNotRunning, nil
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+	g.setState(StateStopped)
+	g.cancel()
+	doneCh, grace, name := g.doneCh, g.stopGrace, g.name
+	g.mu.Unlock()
 
-	m.goroutineTimeout = timeout
-}
-func (m *ManagedGoroutine) SetGoroutineDeadline(deadline time.Time) {
-	if m == nil {
-		return
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-doneCh:
+		return StopGraceful, nil
+	case <-timer.C:
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	m.goroutineDeadline = deadline
+	lg.Warn(fmt.Sprintf("Goroutine %s did not return within %s, leaving it behind", name, grace), map[string]interface{}{"context": "GoLife", "process": name, "showData": false})
+	return StopDetached, fmt.Errorf("goroutine %s %w", name, ErrStopDetached)
 }
-func (m *ManagedGoroutine) SetGoroutineDeadlineSet(set bool) {
-	if m == nil {
-		return
+func (g *ManagedGoroutine) Restart() error {
+	if err := g.Stop(); err != nil && !errors.Is(err, ErrStopDetached) {
+		return err
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	m.goroutineDeadlineSet = set
+	return g.Start()
 }
-func (m *ManagedGoroutine) GetGoroutineFn() func() {
-	if m == nil {
-		return nil
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	return m.goroutineFn
-}
-func (m *ManagedGoroutine) GetGoroutineCh() chan struct{} {
-	if m == nil {
+// Pause makes the goroutine block at its next checkpoint. The timeout and deadline keep running
+// while it is paused.
+func (g *ManagedGoroutine) Pause() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch g.state {
+	case StatePaused:
 		return nil
+	case StateRunning:
+	default:
+		return fmt.Errorf("goroutine %s is not running", g.name)
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	return m.goroutineCh
+	g.control.pause()
+	g.setState(StatePaused)
+	lg.Info(fmt.Sprintf("Goroutine %s paused", g.name), map[string]interface{}{"context": "GoLife", "process": g.name, "showData": false})
+	return nil
 }
-func (m *ManagedGoroutine) GetGoroutineErr() error {
-	if m == nil {
+func (g *ManagedGoroutine) Resume() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch g.state {
+	case StateRunning:
 		return nil
+	case StatePaused:
+	default:
+		return fmt.Errorf("goroutine %s is not paused", g.name)
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	return m.goroutineErr
+	g.control.resume()
+	g.setState(StateRunning)
+	lg.Info(fmt.Sprintf("Goroutine %s resumed", g.name), map[string]interface{}{"context": "GoLife", "process": g.name, "showData": false})
+	return nil
 }
-func (m *ManagedGoroutine) GetGoroutineDone() bool {
-	if m == nil {
+func (g *ManagedGoroutine) IsRunning() bool {
+	if g == nil {
 		return false
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return m.goroutineDone
+	return g.isRunning()
 }
-func (m *ManagedGoroutine) GetGoroutineWG() sync.WaitGroup {
-	if m == nil {
-		return sync.WaitGroup{}
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+func (g *ManagedGoroutine) IsPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return m.goroutineWG
+	return g.state == StatePaused
 }
-func (m *ManagedGoroutine) GetGoroutineMu() sync.Mutex {
-	if m == nil {
-		return sync.Mutex{}
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	return m.goroutineMu
-}
-func (m *ManagedGoroutine) GetGoroutineOnce() sync.Once {
-	if m == nil {
-		return sync.Once{}
+// Wait blocks until the current run returns and reports its error.
+func (g *ManagedGoroutine) Wait() error {
+	if g == nil {
+		return nil
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+	g.mu.Lock()
+	doneCh := g.doneCh
+	g.mu.Unlock()
 
-	return m.goroutineOnce
-}
-func (m *ManagedGoroutine) GetGoroutineCond() *sync.Cond {
-	if m == nil {
+	if doneCh == nil {
 		return nil
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+	<-doneCh
 
-	return m.goroutineCond
+	return g.Err()
 }
-func (m *ManagedGoroutine) GetGoroutineLock() sync.Mutex {
-	if m == nil {
-		return sync.Mutex{}
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+func (g *ManagedGoroutine) String() string {
+	return fmt.Sprintf("Goroutine %s is %s", g.name, g.State())
+}
+func (g *ManagedGoroutine) State() ProcessState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return m.goroutineLock
+	return g.state
 }
-func (m *ManagedGoroutine) GetGoroutineDoneCh() chan struct{} {
-	if m == nil {
-		return nil
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	return m.goroutineDoneCh
+// Err returns the error of the last run: what the function returned, a *PanicError, or
+// context.DeadlineExceeded when the timeout or deadline ended it.
+func (g *ManagedGoroutine) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.err
 }
-func (m *ManagedGoroutine) GetGoroutineErrCh() chan error {
-	if m == nil {
-		return nil
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+func (g *ManagedGoroutine) Snapshot() ProcessStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return m.goroutineErrCh
+	status := ProcessStatus{
+		Name:      g.name,
+		Pid:       -1,
+		State:     g.state,
+		StartedAt: g.startedAt,
+		Restarts:  max(g.runs-1, 0),
+		Health:    HealthState{Status: HealthUnknown, Ready: g.isRunning()},
+	}
+	if g.isRunning() {
+		status.Uptime = Duration(time.Since(g.startedAt))
+	}
+	if g.err != nil {
+		status.ExitCode = 1
+	}
+	return status
 }
-func (m *ManagedGoroutine) GetGoroutineCancel() func() {
-	if m == nil {
+
+// Send queues msg in the inbox of the goroutine, read by the function through Inbox(ctx).
+func (g *ManagedGoroutine) Send(msg interface{}) error {
+	select {
+	case g.inbox <- msg:
 		return nil
+	default:
+		return fmt.Errorf("goroutine %s: %w", g.name, ErrMailboxFull)
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
-
-	return m.goroutineCancel
 }
-func (m *ManagedGoroutine) GetGoroutineCtx() context.Context {
-	if m == nil {
+
+// Receive returns the next message emitted by the function through Emit, or nil when there is none.
+func (g *ManagedGoroutine) Receive() interface{} {
+	select {
+	case msg := <-g.outbox:
+		return msg
+	default:
 		return nil
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+}
+func (g *ManagedGoroutine) SetName(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return m.goroutineCtx
+	g.name = name
 }
-func (m *ManagedGoroutine) GetGoroutineCancelFn() context.CancelFunc {
-	if m == nil {
-		return nil
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+func (g *ManagedGoroutine) SetFunc(fn GoroutineFunc) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return m.goroutineCancelFn
+	g.fn = fn
 }
-func (m *ManagedGoroutine) GetGoroutineTimeout() time.Duration {
-	if m == nil {
-		return 0
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	return m.goroutineTimeout
+// SetTimeout bounds every run; the run's context is cancelled once it elapses.
+func (g *ManagedGoroutine) SetTimeout(timeout time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.timeout = timeout
 }
-func (m *ManagedGoroutine) GetGoroutineDeadline() time.Time {
-	if m == nil {
-		return time.Time{}
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
 
-	return m.goroutineDeadline
+// SetDeadline cancels the context of any run still going at deadline. The zero time clears it.
+func (g *ManagedGoroutine) SetDeadline(deadline time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.deadline = deadline
 }
-func (m *ManagedGoroutine) GetGoroutineDeadlineSet() bool {
-	if m == nil {
-		return false
-	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+func (g *ManagedGoroutine) SetStopGrace(grace time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	return m.goroutineDeadlineSet
+	g.stopGrace = grace
 }
-func (m *ManagedGoroutine) Copy() IManagedGoroutine {
-	if m == nil {
-		return nil
+
+// OnStateChange registers fn to be called on every state change. It runs with the goroutine
+// locked, so it must not call back into it.
+func (g *ManagedGoroutine) OnStateChange(fn func(ProcessStateChange)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.onState = fn
+}
+
+// setState moves the goroutine to state and reports the change. Callers must hold g.mu.
+func (g *ManagedGoroutine) setState(state ProcessState) {
+	previous := g.state
+	g.state = state
+	if g.onState == nil || previous == state {
+		return
 	}
-	m.goroutineMu.Lock()
-	defer m.goroutineMu.Unlock()
+	change := ProcessStateChange{Process: g.name, Previous: previous, Current: state, Pid: -1, Restarts: max(g.runs-1, 0)}
+	if g.err != nil {
+		change.ExitCode = 1
+	}
+	g.onState(change)
+}
 
+// isRunning reports whether a run is in progress, paused or not. Callers must hold g.mu.
+func (g *ManagedGoroutine) isRunning() bool {
+	return g.state == StateRunning || g.state == StatePaused
+}
+
+// NewManagedGoroutine creates a stopped goroutine running fn once started.
+func NewManagedGoroutine(name string, fn GoroutineFunc) *ManagedGoroutine {
 	return &ManagedGoroutine{
-		goroutineFn:          m.goroutineFn,
-		goroutineCh:          m.goroutineCh,
-		goroutineErr:         m.goroutineErr,
-		goroutineDone:        m.goroutineDone,
-		goroutineWG:          m.goroutineWG,
-		goroutineMu:          m.goroutineMu,
-		goroutineOnce:        m.goroutineOnce,
-		goroutineCond:        m.goroutineCond,
-		goroutineLock:        m.goroutineLock,
-		goroutineDoneCh:      m.goroutineDoneCh,
-		goroutineErrCh:       m.goroutineErrCh,
-		goroutineCancel:      m.goroutineCancel,
-		goroutineCtx:         m.goroutineCtx,
-		goroutineCancelFn:    m.goroutineCancelFn,
-		goroutineTimeout:     m.goroutineTimeout,
-		goroutineDeadline:    m.goroutineDeadline,
-		goroutineDeadlineSet: m.goroutineDeadlineSet,
-	}
-}
-
-func NewManagedGoroutine(fn func()) *ManagedGoroutine {
-	return &ManagedGoroutine{
-		goroutineFn: fn,
-	}
-}
-
-func logActivity(activity string) {
-	// f := s.NewFileSystemService("")
-	// if f == nil {
-	// 	return
-	// }
-	// fs := *f
-	// cfgDir := filepath.Dir(fs.GetConfigFilePath())
-	// logFilePath := filepath.Join(cfgDir, "goroutine.log")
-
-	// logFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	// if err != nil {
-	// 	logz.Error("Erro ao abrir arquivo de log", map[string]interface{}{"error": err})
-	// 	return
-	// }
-	// defer func(logFile *os.File) {
-	// 	_ = logFile.Close()
-	// }(logFile)
-
-	// logEntry := time.Now().Format(time.RFC3339) + ": " + activity + "\n"
-	// if _, err := logFile.WriteString(logEntry); err != nil {
-	// 	logz.Error("Erro ao escrever no arquivo de log", map[string]interface{}{"error": err})
-	// }
+		name:      name,
+		fn:        fn,
+		stopGrace: DefaultStopPolicy().Grace,
+		state:     StateStopped,
+		inbox:     make(chan interface{}, DefaultMailboxSize),
+		outbox:    make(chan interface{}, DefaultMailboxSize),
+	}
 }
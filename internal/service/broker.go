@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"github.com/goccy/go-json"
 	"github.com/pebbe/zmq4"
+	"github.com/rafa-mori/golife/internal"
 	"time"
 )
 
+// brokerRetriesTotal counts the retried requests of the broker clients, by endpoint.
+var brokerRetriesTotal = internal.DefaultMetrics.Counter("golife_broker_client_retries_total", "Requests retried by the broker clients, by endpoint.", "endpoint")
+
 var (
 	quitCh    = make(chan bool, 1)
 	sendCh    = make(chan interface{}, 100)
@@ -243,6 +247,10 @@ func (c *BrokerClient) trySendReceive(req interface{}) error {
 		}()
 	}
 
+	// Attempts made before the last reply was received are retries.
+	if c.retries > 0 {
+		brokerRetriesTotal.Inc(c.endpoint)
+	}
 	c.retries++
 
 	if reply, recvErr := c.client.RecvMessage(0); recvErr != nil {
@@ -1,9 +1,11 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"github.com/rafa-mori/logz"
 	"github.com/google/uuid"
+	"sync"
 )
 
 // IStage represents the interface for a stage in the lifecycle.
@@ -13,11 +15,27 @@ type IStage interface {
 	GetEventFns() map[string]func(interface{})
 	GetData() interface{}
 	CanTransitionTo(stageID string) bool
+	CanTransitionFrom(stageID string) bool
+	Enter()
+	Exit()
 	OnEnter(fn func()) IStage
 	OnExit(fn func()) IStage
 	OnEvent(event string, fn func(interface{})) IStage
+	Handle(event string, h EventHandler) IStage
+	Handler(event string) EventHandler
+	Use(mw ...EventMiddleware) IStage
+	Subscribe(pattern string, h EventHandler) (*EventSubscription, error)
+	RemoveEvent(event string) bool
+	AllowNext(stages ...string) IStage
+	AllowPrev(stages ...string) IStage
 	AutoScale(size int) IStage
+	AutoScaleRange(minWorkers, maxWorkers int) IStage
+	PoolStats() (WorkerPoolStats, bool)
+	Mailbox() *Mailbox
+	SetMailbox(capacity int, policy OverflowPolicy) IStage
 	Dispatch(task func()) error
+	DispatchContext(ctx context.Context, task func()) error
+	ShutdownPool(ctx context.Context) error
 	Description() string
 	Name() string
 	ID() string
@@ -37,7 +55,14 @@ type Stage struct {
 	OnEnterFn    func()                       // Function to execute on entering the stage
 	OnExitFn     func()                       // Function to execute on exiting the stage
 	EventFns     map[string]func(interface{}) // Event functions
+	Handlers     map[string]EventHandler      // Event handlers, set by OnEvent and Handle
+	Middleware   []EventMiddleware            // Middleware wrapping every event handler of the stage
 	WorkerPool   *WorkerPool                  // Worker pool for the stage
+
+	eventsMu      sync.RWMutex // Guards EventFns, Handlers and Middleware
+	mailboxMu     sync.Mutex
+	mailbox       *Mailbox
+	subscriptions subscriptionSet
 }
 
 // ID returns the stage identifier.
@@ -63,28 +88,179 @@ func (s *Stage) OnExit(fn func()) IStage {
 
 // OnEvent sets the function to execute on a specific event.
 func (s *Stage) OnEvent(event string, fn func(interface{})) IStage {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	s.initEvents()
 	s.EventFns[event] = fn
+	s.Handlers[event] = handlerOf(fn)
+	return s
+}
+
+// Handle sets the handler of a specific event. Its error is returned by Trigger.
+func (s *Stage) Handle(event string, h EventHandler) IStage {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	s.initEvents()
+	s.Handlers[event] = h
+	s.EventFns[event] = func(data interface{}) {
+		if err := s.Handler(event)(context.Background(), EventCall{Stage: s.Name(), Event: event, Data: data}); err != nil {
+			logz.Error(fmt.Sprintf("Event %s in %s failed: %v", event, s.Name(), err), nil)
+		}
+	}
+	return s
+}
+
+// Subscribe adds a handler for every event matching pattern (see MatchEvent). The handlers of
+// an event run in order: the one set by OnEvent or Handle, then the subscriptions in the order
+// they were made.
+func (s *Stage) Subscribe(pattern string, h EventHandler) (*EventSubscription, error) {
+	return s.subscriptions.add(pattern, h)
+}
+
+// RemoveEvent removes the handler set by OnEvent or Handle for a specific event and reports
+// whether there was one. Subscriptions are left untouched.
+func (s *Stage) RemoveEvent(event string) bool {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	_, ok := s.EventFns[event]
+	if _, handled := s.Handlers[event]; handled {
+		ok = true
+	}
+	delete(s.EventFns, event)
+	delete(s.Handlers, event)
+	return ok
+}
+
+// Handler returns the handlers of a specific event wrapped by the middleware of the stage, or
+// nil when the stage does not handle the event.
+func (s *Stage) Handler(event string) EventHandler {
+	var handlers []EventHandler
+	s.eventsMu.RLock()
+	if h, ok := s.Handlers[event]; ok {
+		handlers = append(handlers, h)
+	} else if fn, ok := s.EventFns[event]; ok {
+		handlers = append(handlers, handlerOf(fn))
+	}
+	middleware := append([]EventMiddleware(nil), s.Middleware...)
+	s.eventsMu.RUnlock()
+
+	handlers = append(handlers, s.subscriptions.matching(event)...)
+	if len(handlers) == 0 {
+		return nil
+	}
+	return ChainEvent(sequenceHandlers(handlers), middleware...)
+}
+
+// initEvents creates the event maps of stages not built by NewStage. Callers must hold s.eventsMu.
+func (s *Stage) initEvents() {
+	if s.EventFns == nil {
+		s.EventFns = make(map[string]func(interface{}))
+	}
+	if s.Handlers == nil {
+		s.Handlers = make(map[string]EventHandler)
+	}
+}
+
+// Use adds middleware wrapping every event handler of the stage. The first middleware is the
+// outermost one.
+func (s *Stage) Use(mw ...EventMiddleware) IStage {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	s.Middleware = append(s.Middleware, mw...)
 	return s
 }
 
-// AutoScale sets the worker pool size for the stage.
+// AllowNext adds stages this stage may transition to.
+func (s *Stage) AllowNext(stages ...string) IStage {
+	s.PossibleNext = append(s.PossibleNext, stages...)
+	return s
+}
+
+// AllowPrev adds stages this stage may be entered from.
+func (s *Stage) AllowPrev(stages ...string) IStage {
+	s.PossiblePrev = append(s.PossiblePrev, stages...)
+	return s
+}
+
+// AutoScale gives the stage a worker pool scaling between one and size workers.
 func (s *Stage) AutoScale(size int) IStage {
-	s.WorkerPool = NewWorkerPool(size).(*WorkerPool)
-	s.WorkerPool.Wg.Add(size)
+	return s.AutoScaleRange(DefaultPoolMinWorkers, size)
+}
+
+// AutoScaleRange gives the stage a worker pool scaling between minWorkers and maxWorkers. A
+// pool set before is resized.
+func (s *Stage) AutoScaleRange(minWorkers, maxWorkers int) IStage {
+	if s.WorkerPool != nil {
+		s.WorkerPool.Resize(minWorkers, maxWorkers)
+		return s
+	}
+	s.WorkerPool = NewElasticWorkerPool(WorkerPoolConfig{Name: s.Name(), MinWorkers: minWorkers, MaxWorkers: maxWorkers}).(*WorkerPool)
+	return s
+}
+
+// PoolStats returns the figures of the worker pool of the stage, if it has one.
+func (s *Stage) PoolStats() (WorkerPoolStats, bool) {
+	if s.WorkerPool == nil {
+		return WorkerPoolStats{}, false
+	}
+	return s.WorkerPool.Stats(), true
+}
+
+// Mailbox returns the mailbox of the stage, created with DefaultMailboxSize and OverflowBlock
+// when none was set.
+func (s *Stage) Mailbox() *Mailbox {
+	s.mailboxMu.Lock()
+	defer s.mailboxMu.Unlock()
+
+	if s.mailbox == nil {
+		s.mailbox = NewMailbox(DefaultMailboxSize, OverflowBlock)
+	}
+	return s.mailbox
+}
+
+// SetMailbox gives the stage a new mailbox. The previous one is closed, so it should be set
+// before messages are exchanged.
+func (s *Stage) SetMailbox(capacity int, policy OverflowPolicy) IStage {
+	s.mailboxMu.Lock()
+	defer s.mailboxMu.Unlock()
+
+	if s.mailbox != nil {
+		s.mailbox.Close()
+	}
+	s.mailbox = NewMailbox(capacity, policy)
 	return s
 }
 
 // Dispatch sends a task to the worker pool.
 func (s *Stage) Dispatch(task func()) error {
+	return s.DispatchContext(context.Background(), task)
+}
+
+// DispatchContext sends a task to the worker pool, waiting for room in its queue until ctx is done.
+func (s *Stage) DispatchContext(ctx context.Context, task func()) error {
 	if s.WorkerPool == nil {
 		logz.Error(fmt.Sprintf("WorkerPool not initialized for stage %s", s.Name()), nil)
 		return fmt.Errorf("WorkerPool not initialized for stage %s", s.Name())
 	}
-	s.WorkerPool.Tasks <- task
+	if err := s.WorkerPool.SubmitContext(ctx, task); err != nil {
+		return fmt.Errorf("dispatching to stage %s: %w", s.Name(), err)
+	}
 	logz.Info(fmt.Sprintf("Task dispatched to stage %s", s.Name()), nil)
 	return nil
 }
 
+// ShutdownPool shuts the worker pool of the stage down, if it has one, once its queue is drained.
+func (s *Stage) ShutdownPool(ctx context.Context) error {
+	if s.WorkerPool == nil {
+		return nil
+	}
+	return s.WorkerPool.Shutdown(ctx)
+}
+
 // CanTransitionTo checks if the stage can transition to another stage.
 func (s *Stage) CanTransitionTo(stageID string) bool {
 	for _, next := range s.PossibleNext {
@@ -95,26 +271,69 @@ func (s *Stage) CanTransitionTo(stageID string) bool {
 	return false
 }
 
+// CanTransitionFrom checks if the stage can be entered from another stage.
+// A stage without PossiblePrev accepts any previous stage.
+func (s *Stage) CanTransitionFrom(stageID string) bool {
+	if len(s.PossiblePrev) == 0 {
+		return true
+	}
+	for _, prev := range s.PossiblePrev {
+		if prev == stageID {
+			return true
+		}
+	}
+	return false
+}
+
+// Enter runs the function set by OnEnter, if any.
+func (s *Stage) Enter() {
+	if s.OnEnterFn != nil {
+		s.OnEnterFn()
+	}
+}
+
+// Exit runs the function set by OnExit, if any.
+func (s *Stage) Exit() {
+	if s.OnExitFn != nil {
+		s.OnExitFn()
+	}
+}
+
 // GetEvent returns the function for a specific event.
 func (s *Stage) GetEvent(event string) func(interface{}) {
+	s.eventsMu.RLock()
+	defer s.eventsMu.RUnlock()
+
 	if fn, ok := s.EventFns[event]; ok {
 		return fn
 	}
 	return nil
 }
 
-// GetEventFns returns all event functions.
-func (s *Stage) GetEventFns() map[string]func(interface{}) { return s.EventFns }
+// GetEventFns returns a copy of the event functions.
+func (s *Stage) GetEventFns() map[string]func(interface{}) {
+	s.eventsMu.RLock()
+	defer s.eventsMu.RUnlock()
+
+	fns := make(map[string]func(interface{}), len(s.EventFns))
+	for event, fn := range s.EventFns {
+		fns[event] = fn
+	}
+	return fns
+}
 
 // GetData returns the stage data.
 func (s *Stage) GetData() interface{} { return s.Data }
 
 // EventExists checks if an event exists in the stage.
 func (s *Stage) EventExists(event string) bool {
-	if _, ok := s.EventFns[event]; ok {
+	s.eventsMu.RLock()
+	_, ok := s.EventFns[event]
+	s.eventsMu.RUnlock()
+	if ok {
 		return true
 	}
-	return false
+	return len(s.subscriptions.matching(event)) > 0
 }
 
 // NewStage creates a new stage with the given name, description, and type.
@@ -125,6 +344,7 @@ func NewStage(name, desc, stageType string) IStage {
 		Type:       stageType,
 		Desc:       desc,
 		EventFns:   make(map[string]func(interface{})),
+		Handlers:   make(map[string]EventHandler),
 		WorkerPool: nil,
 	}
 	logz.Info(fmt.Sprintf("New stage created: %s", name), nil)
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProcessStatus is a point-in-time view of a managed process.
+type ProcessStatus struct {
+	Name       string        `json:"name" yaml:"name"`
+	Kind       UnitKind      `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Command    string        `json:"command,omitempty" yaml:"command,omitempty"`
+	Args       []string      `json:"args,omitempty" yaml:"args,omitempty"`
+	Pid        int           `json:"pid" yaml:"pid"`
+	State      ProcessState  `json:"state" yaml:"state"`
+	StartedAt  time.Time     `json:"startedAt,omitempty" yaml:"startedAt,omitempty"`
+	Uptime     Duration      `json:"uptime" yaml:"uptime"`
+	Restarts   int           `json:"restarts" yaml:"restarts"`
+	ExitCode   int           `json:"exitCode" yaml:"exitCode"`
+	ExitSignal string        `json:"exitSignal,omitempty" yaml:"exitSignal,omitempty"`
+	ExitReason ExitReason    `json:"exitReason,omitempty" yaml:"exitReason,omitempty"`
+	Health     HealthState   `json:"health" yaml:"health"`
+	Resources  ResourceUsage `json:"resources" yaml:"resources"`
+	DependsOn  []string      `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+}
+
+// ResourceUsage is what a process and its descendants consume. It is empty when the process is not running or
+// /proc is not available.
+type ResourceUsage struct {
+	CPUPercent float64 `json:"cpuPercent" yaml:"cpuPercent"`
+	RSSBytes   uint64  `json:"rssBytes" yaml:"rssBytes"`
+	Threads    int     `json:"threads" yaml:"threads"`
+	FDs        int     `json:"fds" yaml:"fds"`
+	ReadBytes  uint64  `json:"readBytes" yaml:"readBytes"`
+	WriteBytes uint64  `json:"writeBytes" yaml:"writeBytes"`
+	Processes  int     `json:"processes" yaml:"processes"` // The process and its descendants
+}
+
+// String renders the status the way Status() always did.
+func (s ProcessStatus) String() string {
+	running := s.State == StateStarting || s.State == StateRunning || s.State == StatePaused || s.State == StateBackoff
+	out := fmt.Sprintf("Process %s (PID %d) is running: %t", s.Name, s.Pid, running)
+	if running && (s.Health.Status != HealthUnknown || !s.Health.Ready) {
+		out += fmt.Sprintf(", health: %s, ready: %t", s.Health.Status, s.Health.Ready)
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// ErrStopDetached is returned, wrapped, when a custom function ignored a stop and was left
+// behind. The process is stopped all the same and can be started again.
+var ErrStopDetached = errors.New("did not return within the stop grace and was left behind")
+
+// StopPolicy defines how a managed process is asked to terminate.
+type StopPolicy struct {
+	Signal syscall.Signal // Signal sent to the process group first (default SIGTERM)
+	Grace  time.Duration  // Time to wait after Signal before escalating to SIGKILL
+}
+
+// DefaultStopPolicy returns the default stop sequence: SIGTERM, 10s grace, then SIGKILL.
+func DefaultStopPolicy() StopPolicy {
+	return StopPolicy{
+		Signal: syscall.SIGTERM,
+		Grace:  10 * time.Second,
+	}
+}
+
+// StopOutcome describes how a process ended when it was stopped.
+type StopOutcome string
+
+const (
+	StopNotRunning StopOutcome = "not-running" // Nothing to stop
+	StopGraceful   StopOutcome = "graceful"    // Exited within the grace period
+	StopKilled     StopOutcome = "killed"      // Escalated to SIGKILL
+	StopDetached   StopOutcome = "detached"    // Custom function did not return in time and was left behind (ErrStopDetached)
+	StopFailed     StopOutcome = "failed"      // The stop sequence itself failed
+)
+
+// StopResult holds the outcome of stopping a single process.
+type StopResult struct {
+	Name     string        `json:"name"`
+	Outcome  StopOutcome   `json:"outcome"`
+	Duration time.Duration `json:"duration"`
+	Err      error         `json:"-"`
+}
+
+// stopResultJSON is the wire form of StopResult, with the error flattened to a string.
+type stopResultJSON struct {
+	Name     string        `json:"name"`
+	Outcome  StopOutcome   `json:"outcome"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func (r StopResult) MarshalJSON() ([]byte, error) {
+	out := stopResultJSON{Name: r.Name, Outcome: r.Outcome, Duration: r.Duration}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+	}
+	return json.Marshal(out)
+}
+func (r *StopResult) UnmarshalJSON(data []byte) error {
+	var in stopResultJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*r = StopResult{Name: in.Name, Outcome: in.Outcome, Duration: in.Duration}
+	if in.Error != "" {
+		r.Err = errors.New(in.Error)
+	}
+	return nil
+}
+
+// StopReport collects the per-process results of a stop operation.
+type StopReport []StopResult
+
+// Err joins the errors of every process that failed to stop, or returns nil.
+func (r StopReport) Err() error {
+	var errs []error
+	for _, res := range r {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Name, res.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// signalGroup sends sig to the whole process group led by pid.
+func signalGroup(pid int, sig syscall.Signal) error {
+	if err := syscall.Kill(-pid, sig); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
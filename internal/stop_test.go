@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownEscalation(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := []struct {
+		name        string
+		command     string
+		args        []string
+		customFunc  func() error
+		start       bool
+		wantOutcome StopOutcome
+		wantErr     error
+		wantState   ProcessState
+	}{
+		{
+			name:        "never started",
+			command:     "sleep",
+			args:        []string{"30"},
+			wantOutcome: StopNotRunning,
+			wantState:   StateStopped,
+		},
+		{
+			name:        "exits on the stop signal",
+			command:     "sleep",
+			args:        []string{"30"},
+			start:       true,
+			wantOutcome: StopGraceful,
+			wantState:   StateStopped,
+		},
+		{
+			name:        "ignores the stop signal and is killed",
+			command:     "sh",
+			args:        []string{"-c", `trap "" TERM; sleep 30`},
+			start:       true,
+			wantOutcome: StopKilled,
+			wantState:   StateStopped,
+		},
+		{
+			name:        "custom function returning in time",
+			customFunc:  func() error { time.Sleep(250 * time.Millisecond); return nil },
+			start:       true,
+			wantOutcome: StopGraceful,
+			wantState:   StateStopped,
+		},
+		{
+			name:        "custom function left behind",
+			customFunc:  func() error { <-release; return nil },
+			start:       true,
+			wantOutcome: StopDetached,
+			wantErr:     ErrStopDetached,
+			wantState:   StateStopped,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewManagedProcess("test", tt.command, tt.args, false, tt.customFunc).(*ManagedProcess)
+			p.SetStopPolicy(StopPolicy{Signal: syscall.SIGTERM, Grace: 300 * time.Millisecond})
+			if tt.start {
+				if err := p.Start(); err != nil {
+					t.Fatalf("Start: %v", err)
+				}
+				// Let the shell install its trap before it is signalled.
+				time.Sleep(100 * time.Millisecond)
+			}
+
+			outcome, err := p.Shutdown()
+			if outcome != tt.wantOutcome {
+				t.Errorf("outcome = %s, want %s", outcome, tt.wantOutcome)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantState != "" && p.State() != tt.wantState {
+				t.Errorf("state = %s, want %s", p.State(), tt.wantState)
+			}
+			if tt.start && p.IsRunning() {
+				t.Errorf("still running after Shutdown")
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// MatchEvent reports whether the event name matches pattern. Names are made of segments
+// separated by dots: "#" matches any number of segments, including none, and any other
+// segment is a glob matching a single segment ("*", "order-*"). "process.*.exited" matches
+// "process.api.exited" and "order.#" matches "order", "order.created" and "order.line.added".
+func MatchEvent(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "."), strings.Split(name, "."))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "#" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ValidateEventPattern checks the syntax of an event pattern.
+func ValidateEventPattern(pattern string) error {
+	if pattern == "" {
+		return errors.New("empty event pattern")
+	}
+	for _, segment := range strings.Split(pattern, ".") {
+		if segment == "" {
+			return fmt.Errorf("event pattern %q has an empty segment", pattern)
+		}
+		if segment != "#" && strings.Contains(segment, "#") {
+			return fmt.Errorf("event pattern %q mixes # with other characters in a segment", pattern)
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("event pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// IsEventPattern reports whether name holds wildcards.
+func IsEventPattern(name string) bool { return strings.ContainsAny(name, "*?[#") }
+
+// EventSubscription is a handler subscribed to the events matching a pattern, returned by
+// Subscribe. Close unsubscribes it.
+type EventSubscription struct {
+	Pattern string
+
+	handler EventHandler
+	set     *subscriptionSet
+}
+
+// Matches reports whether the subscription handles the event.
+func (s *EventSubscription) Matches(event string) bool { return MatchEvent(s.Pattern, event) }
+
+// Close unsubscribes the handler. Triggers already running may still call it.
+func (s *EventSubscription) Close() { s.set.remove(s) }
+
+// subscriptionSet keeps subscriptions in the order they were made.
+type subscriptionSet struct {
+	mu   sync.Mutex
+	subs []*EventSubscription
+}
+
+func (ss *subscriptionSet) add(pattern string, h EventHandler) (*EventSubscription, error) {
+	if err := ValidateEventPattern(pattern); err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, fmt.Errorf("no handler subscribed to %s", pattern)
+	}
+	sub := &EventSubscription{Pattern: pattern, handler: h, set: ss}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.subs = append(ss.subs, sub)
+	return sub, nil
+}
+
+func (ss *subscriptionSet) remove(sub *EventSubscription) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	for i, s := range ss.subs {
+		if s == sub {
+			ss.subs = append(ss.subs[:i], ss.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// matching returns the handlers subscribed to event, in subscription order.
+func (ss *subscriptionSet) matching(event string) []EventHandler {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	var handlers []EventHandler
+	for _, sub := range ss.subs {
+		if sub.Matches(event) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	return handlers
+}
+
+// sequenceHandlers runs handlers one after the other, even when some fail, and joins their
+// errors. It returns nil when there is no handler.
+func sequenceHandlers(handlers []EventHandler) EventHandler {
+	switch len(handlers) {
+	case 0:
+		return nil
+	case 1:
+		return handlers[0]
+	}
+	return func(ctx context.Context, call EventCall) error {
+		var errs []error
+		for _, h := range handlers {
+			if err := h(ctx, call); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,38 @@
+package internal
+
+import "testing"
+
+func TestMatchEvent(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"request", "request", true},
+		{"request", "requests", false},
+		{"request", "order.request", false},
+		{"process.*.exited", "process.api.exited", true},
+		{"process.*.exited", "process.api.started", false},
+		{"process.*.exited", "process.exited", false},
+		{"process.*.exited", "process.api.worker.exited", false},
+		{"order.#", "order", true},
+		{"order.#", "order.created", true},
+		{"order.#", "order.line.added", true},
+		{"order.#", "orders.created", false},
+		{"#.exited", "exited", true},
+		{"#.exited", "process.api.exited", true},
+		{"#.exited", "process.api.exited.late", false},
+		{"process.#.exited", "process.exited", true},
+		{"process.#.exited", "process.api.exited", true},
+		{"#", "anything.at.all", true},
+		{"order-*", "order-created", true},
+		{"order-*", "order.created", false},
+		{"process.api-?.started", "process.api-1.started", true},
+		{"process.api-?.started", "process.api-10.started", false},
+	}
+	for _, tt := range tests {
+		if got := MatchEvent(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchEvent(%q, %q) = %t, want %t", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// RestartPolicy defines when a managed process is restarted after it exits.
+type RestartPolicy string
+
+const (
+	// RestartAlways restarts the process whenever it exits, whatever the exit code.
+	RestartAlways RestartPolicy = "always"
+	// RestartOnFailure restarts the process only when it exits with a non-zero code or by a signal.
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartNever leaves the process down once it exits.
+	RestartNever RestartPolicy = "never"
+)
+
+// ProcessState represents the supervision state of a managed process.
+type ProcessState string
+
+const (
+	StateStopped  ProcessState = "stopped"  // Never started or stopped on request
+	StateStarting ProcessState = "starting" // Being spawned
+	StateRunning  ProcessState = "running"  // Child is alive
+	StatePaused   ProcessState = "paused"   // Alive but suspended
+	StateBackoff  ProcessState = "backoff"  // Waiting to be restarted
+	StateExited   ProcessState = "exited"   // Exited and the policy does not restart it
+	StateFatal    ProcessState = "fatal"    // Restart limit exceeded, supervision gave up
+)
+
+// SupervisorPolicy holds the restart rules applied by the supervisor to a process.
+type SupervisorPolicy struct {
+	Restart        RestartPolicy // When to restart the process
+	BackoffInitial time.Duration // Delay before the first restart
+	BackoffMax     time.Duration // Upper bound for the restart delay
+	BackoffFactor  float64       // Multiplier applied to the delay on each consecutive restart
+	MaxRestarts    int           // Restarts allowed inside Window before going fatal (0 = unlimited)
+	Window         time.Duration // Sliding window used to count restarts
+}
+
+// DefaultSupervisorPolicy returns a policy with sane backoff and limit values for the given restart mode.
+func DefaultSupervisorPolicy(restart RestartPolicy) SupervisorPolicy {
+	return SupervisorPolicy{
+		Restart:        restart,
+		BackoffInitial: 1 * time.Second,
+		BackoffMax:     30 * time.Second,
+		BackoffFactor:  2,
+		MaxRestarts:    5,
+		Window:         60 * time.Second,
+	}
+}
+
+// ShouldRestart reports whether an exit with the given code must trigger a restart.
+func (sp SupervisorPolicy) ShouldRestart(exitCode int) bool {
+	switch sp.Restart {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return exitCode != 0
+	default:
+		return false
+	}
+}
+
+// Backoff returns the delay to wait before the given restart attempt (starting at 0).
+func (sp SupervisorPolicy) Backoff(attempt int) time.Duration {
+	delay := sp.BackoffInitial
+	factor := sp.BackoffFactor
+	if factor < 1 {
+		factor = 1
+	}
+	for i := 0; i < attempt; i++ {
+		delay = time.Duration(float64(delay) * factor)
+		if sp.BackoffMax > 0 && delay >= sp.BackoffMax {
+			return sp.BackoffMax
+		}
+	}
+	return delay
+}
+
+// supervisor keeps the restart bookkeeping of a single managed process.
+type supervisor struct {
+	policy   SupervisorPolicy
+	attempt  int         // Consecutive restart attempt, reset after a stable run
+	restarts []time.Time // Restart timestamps inside the policy window
+	total    int         // Total restarts since registration
+}
+
+// next decides what happens after the child exited with exitCode after running for uptime.
+// force restarts it whatever the policy, still within its restart limit. It returns the delay
+// before restarting and the state the process must move to.
+func (s *supervisor) next(exitCode int, uptime time.Duration, now time.Time, force bool) (time.Duration, ProcessState) {
+	if !force && !s.policy.ShouldRestart(exitCode) {
+		return 0, StateExited
+	}
+
+	if s.policy.BackoffMax > 0 && uptime >= s.policy.BackoffMax {
+		s.attempt = 0
+	}
+
+	if s.policy.Window > 0 {
+		kept := s.restarts[:0]
+		for _, t := range s.restarts {
+			if now.Sub(t) < s.policy.Window {
+				kept = append(kept, t)
+			}
+		}
+		s.restarts = kept
+	}
+	if s.policy.MaxRestarts > 0 && len(s.restarts) >= s.policy.MaxRestarts {
+		return 0, StateFatal
+	}
+
+	delay := s.policy.Backoff(s.attempt)
+	s.attempt++
+	s.total++
+	s.restarts = append(s.restarts, now)
+	return delay, StateBackoff
+}
+
+// ExitReason tells why the last run of a process ended.
+type ExitReason string
+
+const (
+	ExitCompleted ExitReason = "completed"  // Exited with code 0
+	ExitFailed    ExitReason = "failed"     // Exited with a non-zero code or could not start
+	ExitSignaled  ExitReason = "signaled"   // Killed by a signal
+	ExitOOMKilled ExitReason = "oom-killed" // Killed by the OOM killer of its cgroup
+	ExitStopped   ExitReason = "stopped"    // Stopped on request
+)
+
+// exitReasonOf classifies an exit status; oomKilled tells the cgroup recorded an OOM kill.
+func exitReasonOf(exitCode int, exitSignal string, oomKilled bool) ExitReason {
+	switch {
+	case oomKilled:
+		return ExitOOMKilled
+	case exitSignal != "":
+		return ExitSignaled
+	case exitCode != 0:
+		return ExitFailed
+	}
+	return ExitCompleted
+}
+
+// exitStatus extracts the exit code and terminating signal (if any) from a Wait error.
+func exitStatus(err error) (int, string) {
+	if err == nil {
+		return 0, ""
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			return -1, ws.Signal().String()
+		}
+		return exitErr.ExitCode(), ""
+	}
+	return 1, ""
+}
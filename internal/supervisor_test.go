@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSupervisorNext(t *testing.T) {
+	type exit struct {
+		code      int
+		uptime    time.Duration
+		at        time.Duration // Since the first exit
+		force     bool
+		wantDelay time.Duration
+		wantState ProcessState
+	}
+	policy := func(restart RestartPolicy, maxRestarts int) SupervisorPolicy {
+		return SupervisorPolicy{
+			Restart:        restart,
+			BackoffInitial: time.Second,
+			BackoffMax:     8 * time.Second,
+			BackoffFactor:  2,
+			MaxRestarts:    maxRestarts,
+			Window:         time.Minute,
+		}
+	}
+	tests := []struct {
+		name   string
+		policy SupervisorPolicy
+		exits  []exit
+	}{
+		{
+			name:   "on-failure leaves a clean exit down",
+			policy: policy(RestartOnFailure, 0),
+			exits:  []exit{{code: 0, wantState: StateExited}},
+		},
+		{
+			name:   "always restarts a clean exit",
+			policy: policy(RestartAlways, 0),
+			exits:  []exit{{code: 0, wantDelay: time.Second, wantState: StateBackoff}},
+		},
+		{
+			name:   "never leaves a failure down",
+			policy: policy(RestartNever, 0),
+			exits:  []exit{{code: 1, wantState: StateExited}},
+		},
+		{
+			name:   "backoff doubles up to its maximum",
+			policy: policy(RestartOnFailure, 0),
+			exits: []exit{
+				{code: 1, at: 0, wantDelay: 1 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 1 * time.Second, wantDelay: 2 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 3 * time.Second, wantDelay: 4 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 7 * time.Second, wantDelay: 8 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 15 * time.Second, wantDelay: 8 * time.Second, wantState: StateBackoff},
+			},
+		},
+		{
+			name:   "a stable run resets the backoff",
+			policy: policy(RestartOnFailure, 0),
+			exits: []exit{
+				{code: 1, at: 0, wantDelay: 1 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 1 * time.Second, wantDelay: 2 * time.Second, wantState: StateBackoff},
+				{code: 1, uptime: 8 * time.Second, at: 11 * time.Second, wantDelay: 1 * time.Second, wantState: StateBackoff},
+			},
+		},
+		{
+			name:   "too many restarts inside the window go fatal",
+			policy: policy(RestartOnFailure, 3),
+			exits: []exit{
+				{code: 1, at: 0, wantDelay: 1 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 1 * time.Second, wantDelay: 2 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 3 * time.Second, wantDelay: 4 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 7 * time.Second, wantState: StateFatal},
+			},
+		},
+		{
+			name:   "restarts leave the window as it slides",
+			policy: policy(RestartOnFailure, 3),
+			exits: []exit{
+				{code: 1, at: 0, wantDelay: 1 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 1 * time.Second, wantDelay: 2 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 3 * time.Second, wantDelay: 4 * time.Second, wantState: StateBackoff},
+				{code: 1, at: 61 * time.Second, wantDelay: 8 * time.Second, wantState: StateBackoff},
+			},
+		},
+		{
+			name:   "force restarts whatever the policy",
+			policy: policy(RestartNever, 0),
+			exits: []exit{
+				{code: 0, force: true, wantDelay: time.Second, wantState: StateBackoff},
+				{code: 0, at: time.Second, wantState: StateExited},
+			},
+		},
+		{
+			name:   "force keeps the restart limit",
+			policy: policy(RestartNever, 1),
+			exits: []exit{
+				{code: 1, force: true, wantDelay: time.Second, wantState: StateBackoff},
+				{code: 1, at: time.Second, force: true, wantState: StateFatal},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sup := &supervisor{policy: tt.policy}
+			start := time.Now()
+			for i, e := range tt.exits {
+				delay, state := sup.next(e.code, e.uptime, start.Add(e.at), e.force)
+				if delay != e.wantDelay || state != e.wantState {
+					t.Fatalf("exit %d: got (%s, %s), want (%s, %s)", i, delay, state, e.wantDelay, e.wantState)
+				}
+			}
+		})
+	}
+}
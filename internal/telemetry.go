@@ -0,0 +1,360 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the duration histograms.
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// MetricKind is the Prometheus type of a metric family.
+type MetricKind string
+
+const (
+	MetricCounter   MetricKind = "counter"
+	MetricGauge     MetricKind = "gauge"
+	MetricHistogram MetricKind = "histogram"
+)
+
+// MetricsRegistry holds metric families and writes them in the Prometheus text format.
+type MetricsRegistry struct {
+	mu       sync.Mutex
+	families map[string]*metricFamily
+}
+
+// DefaultMetrics is the registry the lifecycle instruments and the /metrics endpoint serves.
+var DefaultMetrics = NewMetricsRegistry()
+
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{families: make(map[string]*metricFamily)}
+}
+
+// metricFamily is a metric and its series, one per combination of label values.
+type metricFamily struct {
+	mu      sync.Mutex
+	name    string
+	help    string
+	kind    MetricKind
+	labels  []string
+	buckets []float64
+	series  map[string]*metricSeries
+}
+
+type metricSeries struct {
+	labelValues []string
+	value       float64  // Counters and gauges
+	counts      []uint64 // Histogram observations per bucket, not cumulative
+	sum         float64
+	count       uint64
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct{ f *metricFamily }
+
+// GaugeVec is a gauge partitioned by labels.
+type GaugeVec struct{ f *metricFamily }
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct{ f *metricFamily }
+
+// Counter registers a counter, or returns the one already registered under name.
+func (r *MetricsRegistry) Counter(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{r.family(name, help, MetricCounter, labels, nil)}
+}
+
+// Gauge registers a gauge, or returns the one already registered under name.
+func (r *MetricsRegistry) Gauge(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{r.family(name, help, MetricGauge, labels, nil)}
+}
+
+// Histogram registers a histogram, or returns the one already registered under name. Buckets
+// are upper bounds in increasing order; DefaultDurationBuckets is used when none are given.
+func (r *MetricsRegistry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+	return &HistogramVec{r.family(name, help, MetricHistogram, labels, slices.Clone(buckets))}
+}
+
+func (r *MetricsRegistry) family(name, help string, kind MetricKind, labels []string, buckets []float64) *metricFamily {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		return f
+	}
+	f := newMetricFamily(name, help, kind, labels, buckets)
+	r.families[name] = f
+	return f
+}
+
+func newMetricFamily(name, help string, kind MetricKind, labels []string, buckets []float64) *metricFamily {
+	return &metricFamily{name: name, help: help, kind: kind, labels: labels, buckets: buckets, series: make(map[string]*metricSeries)}
+}
+
+// Inc adds one to the series of labelValues.
+func (c *CounterVec) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+// Add adds v, which must not be negative, to the series of labelValues.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.f.update(labelValues, func(s *metricSeries) { s.value += v })
+}
+
+// Set sets the series of labelValues to v.
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.f.update(labelValues, func(s *metricSeries) { s.value = v })
+}
+
+// Add adds v, possibly negative, to the series of labelValues.
+func (g *GaugeVec) Add(v float64, labelValues ...string) {
+	g.f.update(labelValues, func(s *metricSeries) { s.value += v })
+}
+
+// Delete drops the series of labelValues, for instance once what it measured is gone.
+func (g *GaugeVec) Delete(labelValues ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+
+	delete(g.f.series, seriesKey(labelValues))
+}
+
+// Observe records v in the series of labelValues.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.f.update(labelValues, func(s *metricSeries) {
+		if s.counts == nil {
+			s.counts = make([]uint64, len(h.f.buckets))
+		}
+		if i := sort.SearchFloat64s(h.f.buckets, v); i < len(h.f.buckets) {
+			s.counts[i]++
+		}
+		s.sum += v
+		s.count++
+	})
+}
+
+// ObserveDuration records the time elapsed since start, in seconds.
+func (h *HistogramVec) ObserveDuration(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// update applies fn to the series of labelValues, creating it when needed. Missing label values
+// are empty and extra ones are ignored.
+func (f *metricFamily) update(labelValues []string, fn func(*metricSeries)) {
+	values := make([]string, len(f.labels))
+	copy(values, labelValues)
+	key := seriesKey(values)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &metricSeries{labelValues: values}
+		f.series[key] = s
+	}
+	fn(s)
+}
+
+func seriesKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// MetricsWriter gathers the metrics a collector computes at scrape time.
+type MetricsWriter struct {
+	families map[string]*metricFamily
+}
+
+// Gauge adds a gauge sample. labelPairs alternate label names and values.
+func (w *MetricsWriter) Gauge(name, help string, value float64, labelPairs ...string) {
+	w.sample(name, help, MetricGauge, value, labelPairs)
+}
+
+// Counter adds a counter sample. labelPairs alternate label names and values.
+func (w *MetricsWriter) Counter(name, help string, value float64, labelPairs ...string) {
+	w.sample(name, help, MetricCounter, value, labelPairs)
+}
+
+func (w *MetricsWriter) sample(name, help string, kind MetricKind, value float64, labelPairs []string) {
+	labels := make([]string, 0, len(labelPairs)/2)
+	values := make([]string, 0, len(labelPairs)/2)
+	for i := 0; i+1 < len(labelPairs); i += 2 {
+		labels = append(labels, labelPairs[i])
+		values = append(values, labelPairs[i+1])
+	}
+	f, ok := w.families[name]
+	if !ok {
+		f = newMetricFamily(name, help, kind, labels, nil)
+		w.families[name] = f
+	}
+	f.series[seriesKey(values)] = &metricSeries{labelValues: values, value: value}
+}
+
+// WritePrometheus writes every registered family, and those the collectors compute, in the
+// Prometheus text exposition format (version 0.0.4).
+func (r *MetricsRegistry) WritePrometheus(w io.Writer, collectors ...func(*MetricsWriter)) error {
+	collected := &MetricsWriter{families: make(map[string]*metricFamily)}
+	for _, collect := range collectors {
+		collect(collected)
+	}
+
+	r.mu.Lock()
+	families := make([]*metricFamily, 0, len(r.families)+len(collected.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	for name, f := range collected.families {
+		if _, ok := r.families[name]; !ok {
+			families = append(families, f)
+		}
+	}
+	r.mu.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// write writes the family; a family without series is left out.
+func (f *metricFamily) write(w *bufio.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.series) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if f.help != "" {
+		_, _ = fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	}
+	_, _ = fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+	for _, key := range keys {
+		s := f.series[key]
+		if f.kind != MetricHistogram {
+			_, _ = fmt.Fprintf(w, "%s%s %s\n", f.name, formatLabels(f.labels, s.labelValues, "", ""), formatValue(s.value))
+			continue
+		}
+		var cumulative uint64
+		for i, bound := range f.buckets {
+			if s.counts != nil {
+				cumulative += s.counts[i]
+			}
+			_, _ = fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, formatLabels(f.labels, s.labelValues, "le", formatValue(bound)), cumulative)
+		}
+		_, _ = fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, formatLabels(f.labels, s.labelValues, "le", "+Inf"), s.count)
+		_, _ = fmt.Fprintf(w, "%s_sum%s %s\n", f.name, formatLabels(f.labels, s.labelValues, "", ""), formatValue(s.sum))
+		_, _ = fmt.Fprintf(w, "%s_count%s %d\n", f.name, formatLabels(f.labels, s.labelValues, "", ""), s.count)
+	}
+}
+
+// formatLabels renders {name="value",...}, with an extra label when extraName is set.
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(name)
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabel(values[i]))
+		sb.WriteByte('"')
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(extraName)
+		sb.WriteString(`="`)
+		sb.WriteString(extraValue)
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+// Lifecycle instruments, registered in DefaultMetrics.
+var (
+	stageTransitionsTotal   = DefaultMetrics.Counter("golife_stage_transitions_total", "Stage transitions, by source and target stage.", "from", "to")
+	eventTriggersTotal      = DefaultMetrics.Counter("golife_event_triggers_total", "Events triggered, by stage, event and result.", "stage", "event", "result")
+	eventTriggerSeconds     = DefaultMetrics.Histogram("golife_event_trigger_duration_seconds", "Time spent in event callbacks, by stage.", nil, "stage")
+	eventHandlerSeconds     = DefaultMetrics.Histogram("golife_event_handler_duration_seconds", "Time spent in event handlers wrapped by WithMetrics, by stage and event.", nil, "stage", "event")
+	eventHandlerErrorsTotal = DefaultMetrics.Counter("golife_event_handler_errors_total", "Errors returned by event handlers wrapped by WithMetrics, by stage and event.", "stage", "event")
+	workerTaskSeconds       = DefaultMetrics.Histogram("golife_worker_pool_task_duration_seconds", "Time spent running worker pool tasks, by pool.", nil, "pool")
+	workerTasksTotal        = DefaultMetrics.Counter("golife_worker_pool_tasks_total", "Worker pool tasks completed, by pool.", "pool")
+)
+
+// LifecycleCollector computes, at scrape time, the metrics of the processes, units and stages of lm.
+func LifecycleCollector(lm LifeCycleManager) func(*MetricsWriter) {
+	return func(w *MetricsWriter) {
+		for _, s := range lm.Snapshot() {
+			up := 0.0
+			if s.State == StateRunning || s.State == StatePaused {
+				up = 1
+			}
+			w.Gauge("golife_process_up", "Whether the process is running (1) or not (0).", up, "process", s.Name)
+			w.Counter("golife_process_restarts_total", "Restarts of the process by its supervisor.", float64(s.Restarts), "process", s.Name)
+			w.Gauge("golife_process_exit_code", "Exit code of the last run of the process.", float64(s.ExitCode), "process", s.Name)
+			w.Gauge("golife_process_uptime_seconds", "Time since the process started, zero when it is not running.", time.Duration(s.Uptime).Seconds(), "process", s.Name)
+			if s.Pid > 0 {
+				w.Gauge("golife_process_cpu_percent", "CPU usage of the process and its descendants.", s.Resources.CPUPercent, "process", s.Name)
+				w.Gauge("golife_process_resident_memory_bytes", "Resident memory of the process and its descendants.", float64(s.Resources.RSSBytes), "process", s.Name)
+			}
+		}
+		current := lm.GetCurrentStage()
+		for _, stage := range lm.GetStages() {
+			active := 0.0
+			if current != nil && current.ID() == stage.ID() {
+				active = 1
+			}
+			w.Gauge("golife_stage_current", "Whether the lifecycle is in the stage (1) or not (0).", active, "stage", stage.Name())
+			if stats, ok := stage.PoolStats(); ok {
+				w.Gauge("golife_worker_pool_queue_depth", "Tasks waiting for a worker, by pool.", float64(stats.Queued), "pool", stage.Name())
+				w.Gauge("golife_worker_pool_workers", "Workers of the pool.", float64(stats.Workers), "pool", stage.Name())
+				w.Gauge("golife_worker_pool_busy_workers", "Workers running a task.", float64(stats.Busy), "pool", stage.Name())
+			}
+		}
+	}
+}
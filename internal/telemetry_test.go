@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	r := NewMetricsRegistry()
+	requests := r.Counter("app_requests_total", "Requests served.", "code")
+	requests.Inc("200")
+	requests.Add(2, "200")
+	requests.Add(-1, "200") // Counters never go down
+	requests.Inc("500", "ignored")
+	temperature := r.Gauge("app_temperature", "Temperature,\nin \\degrees.", "room")
+	temperature.Set(21.5, `big "hall"`)
+	temperature.Add(-0.5, `big "hall"`)
+	temperature.Set(3, "gone")
+	temperature.Delete("gone")
+	latency := r.Histogram("app_latency_seconds", "", []float64{0.1, 1})
+	for _, v := range []float64{0.05, 0.1, 0.5, 3} {
+		latency.Observe(v)
+	}
+	r.Gauge("app_unused", "Never set.")
+	if again := r.Counter("app_requests_total", "Other help."); again.f != requests.f {
+		t.Fatal("registering a name twice created a second family")
+	}
+
+	var sb strings.Builder
+	err := r.WritePrometheus(&sb, func(w *MetricsWriter) {
+		w.Gauge("app_queue_depth", "Queued jobs.", 4, "queue", "mail")
+		w.Gauge("app_queue_depth", "Queued jobs.", 0, "queue", "sms")
+		w.Counter("app_requests_total", "Shadowed by the registered family.", 99)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{le="0.1"} 2
+app_latency_seconds_bucket{le="1"} 3
+app_latency_seconds_bucket{le="+Inf"} 4
+app_latency_seconds_sum 3.65
+app_latency_seconds_count 4
+# HELP app_queue_depth Queued jobs.
+# TYPE app_queue_depth gauge
+app_queue_depth{queue="mail"} 4
+app_queue_depth{queue="sms"} 0
+# HELP app_requests_total Requests served.
+# TYPE app_requests_total counter
+app_requests_total{code="200"} 3
+app_requests_total{code="500"} 1
+# HELP app_temperature Temperature,\nin \\degrees.
+# TYPE app_temperature gauge
+app_temperature{room="big \"hall\""} 21
+`
+	if sb.String() != want {
+		t.Fatalf("exposition:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+func TestLifecycleCollector(t *testing.T) {
+	lm := NewLifecycleManager(nil, nil, nil, nil, nil, nil)
+	for _, stage := range []IStage{NewStage("boot", "", ""), NewStage("running", "", "").AutoScale(2)} {
+		if err := lm.RegisterStage(stage); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lm.DefineStage("running"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lm.RegisterProcess("api", "sleep", []string{"30"}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = lm.StopAll() }()
+
+	var sb strings.Builder
+	if err := NewMetricsRegistry().WritePrometheus(&sb, LifecycleCollector(lm)); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, line := range []string{
+		`golife_process_up{process="api"} 0`,
+		`golife_process_restarts_total{process="api"} 0`,
+		`golife_stage_current{stage="boot"} 0`,
+		`golife_stage_current{stage="running"} 1`,
+		`golife_worker_pool_workers{pool="running"} 1`,
+		`golife_worker_pool_queue_depth{pool="running"} 0`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "golife_process_cpu_percent") {
+		t.Errorf("resource metrics of a process that never ran:\n%s", out)
+	}
+}
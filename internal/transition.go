@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"fmt"
+	l "github.com/rafa-mori/logz"
+	"time"
+)
+
+// DefaultTransitionHistory is the number of transitions kept when no limit is configured.
+const DefaultTransitionHistory = 100
+
+// TransitionGuard decides whether a transition may happen. Returning an error vetoes it.
+// from is nil when the lifecycle has no current stage yet.
+type TransitionGuard func(from, to IStage, data interface{}) error
+
+// StageTransition is a recorded stage change.
+type StageTransition struct {
+	From string      `json:"from,omitempty"`
+	To   string      `json:"to"`
+	At   time.Time   `json:"at"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// transitionGuard binds a guard to a target stage ("" means every stage).
+type transitionGuard struct {
+	stage string
+	guard TransitionGuard
+}
+
+// TransitionTo moves the lifecycle to stageName. The move must be allowed by the current stage's
+// PossibleNext and, when set, the target's PossiblePrev, and every guard must accept it.
+// The current stage's exit hook runs before the target's enter hook. Hooks must not call
+// TransitionTo themselves.
+func (lm *LifeCycle) TransitionTo(stageName string, data interface{}) error {
+	lm.transitionMu.Lock()
+	defer lm.transitionMu.Unlock()
+
+	to := lm.GetStage(stageName)
+	if to == nil {
+		return fmt.Errorf("stage %s not found", stageName)
+	}
+	from := lm.GetCurrentStage()
+
+	if from != nil {
+		if from.ID() == to.ID() {
+			return fmt.Errorf("lifecycle is already in stage %s", stageName)
+		}
+		if !from.CanTransitionTo(to.Name()) && !from.CanTransitionTo(to.ID()) {
+			return fmt.Errorf("transition from %s to %s is not allowed", from.Name(), to.Name())
+		}
+		if !to.CanTransitionFrom(from.Name()) && !to.CanTransitionFrom(from.ID()) {
+			return fmt.Errorf("stage %s cannot be entered from %s", to.Name(), from.Name())
+		}
+	}
+
+	lm.mu.Lock()
+	guards := make([]TransitionGuard, 0, len(lm.guards))
+	for _, g := range lm.guards {
+		if g.stage == "" || g.stage == to.Name() {
+			guards = append(guards, g.guard)
+		}
+	}
+	lm.mu.Unlock()
+	for _, guard := range guards {
+		if err := guard(from, to, data); err != nil {
+			return fmt.Errorf("transition to %s rejected: %w", to.Name(), err)
+		}
+	}
+
+	fromName := ""
+	if from != nil {
+		fromName = from.Name()
+		from.Exit()
+	}
+	to.Enter()
+
+	lm.stagesMu.Lock()
+	if from != nil {
+		lm.lastStage = from.ID()
+	}
+	lm.currentStage = to.ID()
+	lm.stagesMu.Unlock()
+
+	lm.mu.Lock()
+	transition := StageTransition{From: fromName, To: to.Name(), At: time.Now(), Data: data}
+	lm.history = append(lm.history, transition)
+	if limit := lm.historyLimit; limit > 0 && len(lm.history) > limit {
+		lm.history = append([]StageTransition(nil), lm.history[len(lm.history)-limit:]...)
+	}
+	lm.mu.Unlock()
+	stageTransitionsTotal.Inc(fromName, to.Name())
+
+	if from != nil {
+		lm.bus.Publish(LifecycleEvent{Type: EventStageExited, Stage: fromName, Data: data})
+	}
+	lm.bus.Publish(LifecycleEvent{Type: EventStageEntered, Stage: to.Name(), Data: transition})
+
+	l.Info(fmt.Sprintf("Stage transition %s -> %s", fromName, to.Name()), map[string]interface{}{"context": "GoLife", "from": fromName, "to": to.Name(), "showData": false})
+	return nil
+}
+
+// AddGuard registers a guard for transitions into stageName, or into any stage when stageName is empty.
+func (lm *LifeCycle) AddGuard(stageName string, guard TransitionGuard) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.guards = append(lm.guards, transitionGuard{stage: stageName, guard: guard})
+}
+
+// GetLastStage returns the stage the lifecycle was in before the current one.
+func (lm *LifeCycle) GetLastStage() IStage {
+	lm.stagesMu.RLock()
+	defer lm.stagesMu.RUnlock()
+
+	if s, ok := lm.stages[lm.lastStage]; ok {
+		return s
+	}
+	return nil
+}
+
+// History returns a copy of the recorded transitions, oldest first.
+func (lm *LifeCycle) History() []StageTransition {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	return append([]StageTransition(nil), lm.history...)
+}
+
+// SetHistoryLimit bounds the number of transitions kept in History.
+func (lm *LifeCycle) SetHistoryLimit(limit int) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.historyLimit = limit
+	if limit > 0 && len(lm.history) > limit {
+		lm.history = append([]StageTransition(nil), lm.history[len(lm.history)-limit:]...)
+	}
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newTransitionLifecycle builds boot -> running -> {boot, stopped, maintenance}, where stopped may
+// only be entered from running and maintenance only from stopped.
+func newTransitionLifecycle(t *testing.T) LifeCycleManager {
+	t.Helper()
+	lm := NewLifecycleManager(nil, nil, nil, nil, nil, nil)
+	stages := []IStage{
+		NewStage("boot", "", "").AllowNext("running"),
+		NewStage("running", "", "").AllowNext("boot", "stopped", "maintenance"),
+		NewStage("stopped", "", "").AllowNext("maintenance").AllowPrev("running"),
+		NewStage("maintenance", "", "").AllowPrev("stopped"),
+	}
+	for _, s := range stages {
+		if err := lm.RegisterStage(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return lm
+}
+
+func TestTransitionGuards(t *testing.T) {
+	veto := errors.New("not ready")
+	tests := []struct {
+		name    string
+		from    string // Empty for a lifecycle without a current stage
+		to      string
+		guards  map[string]TransitionGuard
+		err     string // Part of the expected error, empty when the transition must happen
+		current string
+	}{
+		{name: "first stage", to: "boot", current: "boot"},
+		{name: "allowed next", from: "boot", to: "running", current: "running"},
+		{name: "unknown stage", from: "boot", to: "nowhere", err: "stage nowhere not found", current: "boot"},
+		{name: "same stage", from: "running", to: "running", err: "already in stage running", current: "running"},
+		{name: "not in next", from: "boot", to: "stopped", err: "from boot to stopped is not allowed", current: "boot"},
+		{name: "not in prev", from: "running", to: "maintenance", err: "cannot be entered from running", current: "running"},
+		{name: "allowed prev", from: "stopped", to: "maintenance", current: "maintenance"},
+		{
+			name:    "global guard vetoes",
+			from:    "boot",
+			to:      "running",
+			guards:  map[string]TransitionGuard{"": func(from, to IStage, data interface{}) error { return veto }},
+			err:     "transition to running rejected: not ready",
+			current: "boot",
+		},
+		{
+			name:    "stage guard vetoes",
+			from:    "running",
+			to:      "stopped",
+			guards:  map[string]TransitionGuard{"stopped": func(from, to IStage, data interface{}) error { return veto }},
+			err:     "transition to stopped rejected",
+			current: "running",
+		},
+		{
+			name:    "guard of another stage is skipped",
+			from:    "running",
+			to:      "boot",
+			guards:  map[string]TransitionGuard{"stopped": func(from, to IStage, data interface{}) error { return veto }},
+			current: "boot",
+		},
+		{
+			name: "guard sees both stages and the data",
+			to:   "boot",
+			guards: map[string]TransitionGuard{"boot": func(from, to IStage, data interface{}) error {
+				if from != nil || to.Name() != "boot" || data != "payload" {
+					return veto
+				}
+				return nil
+			}},
+			current: "boot",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := newTransitionLifecycle(t)
+			if tt.from != "" {
+				if err := lm.DefineStage(tt.from); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for stage, guard := range tt.guards {
+				lm.AddGuard(stage, guard)
+			}
+
+			err := lm.TransitionTo(tt.to, "payload")
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("err = %v, want one containing %q", err, tt.err)
+			case tt.err != "" && strings.Contains(tt.err, "rejected") && !errors.Is(err, veto):
+				t.Fatalf("err = %v does not wrap the guard error", err)
+			}
+			if got := lm.GetCurrentStage(); got == nil || got.Name() != tt.current {
+				t.Fatalf("current stage = %v, want %s", got, tt.current)
+			}
+			if wantHistory := tt.err == ""; wantHistory != (len(lm.History()) == 1) {
+				t.Fatalf("history = %+v after err %v", lm.History(), err)
+			}
+		})
+	}
+}
+
+func TestTransitionHooksAndHistory(t *testing.T) {
+	lm := newTransitionLifecycle(t)
+	var calls []string
+	for _, name := range []string{"boot", "running"} {
+		name := name
+		lm.GetStage(name).OnEnter(func() { calls = append(calls, "enter "+name) }).OnExit(func() { calls = append(calls, "exit "+name) })
+	}
+	lm.(*LifeCycle).SetHistoryLimit(2)
+
+	for _, to := range []string{"boot", "running", "boot"} {
+		if err := lm.TransitionTo(to, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"enter boot", "exit boot", "enter running", "exit running", "enter boot"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Fatalf("hooks ran as %v, want %v", calls, want)
+	}
+	history := lm.History()
+	if len(history) != 2 || history[0].From != "boot" || history[0].To != "running" || history[1].From != "running" || history[1].To != "boot" {
+		t.Fatalf("history = %+v, want the last two transitions", history)
+	}
+	if last := lm.GetLastStage(); last == nil || last.Name() != "running" {
+		t.Fatalf("last stage = %v, want running", last)
+	}
+}
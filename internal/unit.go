@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// UnitKind tells what runs behind a managed unit.
+type UnitKind string
+
+const (
+	KindProcess   UnitKind = "process"   // An OS process
+	KindGoroutine UnitKind = "goroutine" // A GoroutineFunc
+	KindFunc      UnitKind = "func"      // A plain Go func supervised like a process
+)
+
+// IManagedUnit is the lifecycle every supervised unit shares, whatever runs behind it. The
+// optional capabilities are discovered with a type assertion: Pausable, Reloadable, Signalable.
+type IManagedUnit interface {
+	GetName() string
+	Kind() UnitKind
+
+	Start() error
+	Stop() error
+	Shutdown() (StopOutcome, error)
+	Restart() error
+	IsRunning() bool
+	Wait() error
+	String() string
+	State() ProcessState
+	Snapshot() ProcessStatus
+	OnStateChange(fn func(ProcessStateChange))
+}
+
+// Pausable is implemented by the units that can be suspended and resumed.
+type Pausable interface {
+	Pause() error
+	Resume() error
+	IsPaused() bool
+}
+
+// Reloadable is implemented by the units that can reload their configuration without a restart.
+type Reloadable interface {
+	Reload() error
+}
+
+// Signalable is implemented by the units that can receive OS signals.
+type Signalable interface {
+	Signal(sig os.Signal) error
+}
+
+// unitCore is the part of IManagedUnit an adapter takes from what it wraps.
+type unitCore interface {
+	GetName() string
+	Start() error
+	Stop() error
+	Shutdown() (StopOutcome, error)
+	Restart() error
+	IsRunning() bool
+	Wait() error
+	String() string
+	State() ProcessState
+	Snapshot() ProcessStatus
+	OnStateChange(fn func(ProcessStateChange))
+}
+
+// processUnit adapts a managed process running a Go func.
+type processUnit struct {
+	unitCore
+	proc IManagedProcess
+	kind UnitKind
+}
+
+func (u *processUnit) Kind() UnitKind           { return u.kind }
+func (u *processUnit) Process() IManagedProcess { return u.proc }
+func (u *processUnit) Reload() error            { return u.proc.Reload() }
+func (u *processUnit) Snapshot() ProcessStatus {
+	status := u.proc.Snapshot()
+	status.Kind = u.kind
+	return status
+}
+
+// commandUnit adapts a managed process running an OS command, which can be paused and signalled.
+type commandUnit struct {
+	processUnit
+}
+
+func (u *commandUnit) Pause() error   { return u.proc.Pause() }
+func (u *commandUnit) Resume() error  { return u.proc.Resume() }
+func (u *commandUnit) IsPaused() bool { return u.proc.IsPaused() }
+
+// Signal sends sig to the process group of the running command.
+func (u *commandUnit) Signal(sig os.Signal) error {
+	pid := u.proc.Pid()
+	if pid <= 0 {
+		return fmt.Errorf("process %s is not running", u.proc.GetName())
+	}
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return fmt.Errorf("unsupported signal %v", sig)
+	}
+	return signalGroup(pid, s)
+}
+
+// goroutineUnit adapts a managed goroutine, which can be paused at its checkpoints.
+type goroutineUnit struct {
+	unitCore
+	g IManagedGoroutine
+}
+
+func (u *goroutineUnit) Kind() UnitKind               { return KindGoroutine }
+func (u *goroutineUnit) Goroutine() IManagedGoroutine { return u.g }
+func (u *goroutineUnit) Pause() error                 { return u.g.Pause() }
+func (u *goroutineUnit) Resume() error                { return u.g.Resume() }
+func (u *goroutineUnit) IsPaused() bool               { return u.g.IsPaused() }
+func (u *goroutineUnit) Snapshot() ProcessStatus {
+	status := u.g.Snapshot()
+	status.Kind = KindGoroutine
+	return status
+}
+
+// NewProcessUnit adapts proc as a Reloadable unit. A process running a CustomFunc is a KindFunc
+// unit, any other one a Pausable and Signalable KindProcess unit.
+func NewProcessUnit(proc IManagedProcess) IManagedUnit {
+	if proc.GetCommand() == "" && proc.GetCustomFunc() != nil {
+		return &processUnit{unitCore: proc, proc: proc, kind: KindFunc}
+	}
+	return &commandUnit{processUnit{unitCore: proc, proc: proc, kind: KindProcess}}
+}
+
+// NewGoroutineUnit adapts g as a Pausable unit.
+func NewGoroutineUnit(g IManagedGoroutine) IManagedUnit {
+	return &goroutineUnit{unitCore: g, g: g}
+}
+
+// NewFuncUnit supervises fn like a process: it gets the restart, stop and health policies of one.
+func NewFuncUnit(name string, fn func() error) IManagedUnit {
+	return NewProcessUnit(NewManagedProcess(name, "", nil, false, fn))
+}
+
+// AsProcess returns the managed process behind u, if any.
+func AsProcess(u IManagedUnit) (IManagedProcess, bool) {
+	if pu, ok := u.(interface{ Process() IManagedProcess }); ok {
+		return pu.Process(), true
+	}
+	return nil, false
+}
+
+// AsGoroutine returns the managed goroutine behind u, if any.
+func AsGoroutine(u IManagedUnit) (IManagedGoroutine, bool) {
+	if gu, ok := u.(interface{ Goroutine() IManagedGoroutine }); ok {
+		return gu.Goroutine(), true
+	}
+	return nil, false
+}
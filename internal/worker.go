@@ -1,43 +1,420 @@
 package internal
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/rafa-mori/logz"
+	"runtime/debug"
 	"sync"
+	"time"
+)
+
+// Defaults applied to a WorkerPoolConfig.
+const (
+	DefaultPoolMinWorkers    = 1
+	DefaultPoolIdleTimeout   = 30 * time.Second
+	DefaultPoolMaxLatency    = 50 * time.Millisecond
+	DefaultPoolScaleInterval = 100 * time.Millisecond
+
+	// DefaultPoolShutdownTimeout bounds how long StopAll waits for the worker pools to drain.
+	DefaultPoolShutdownTimeout = 30 * time.Second
+)
+
+var (
+	// ErrPoolClosed is returned when a task is submitted to a pool being shut down.
+	ErrPoolClosed = errors.New("worker pool is shut down")
+	// ErrPoolFull is returned by TrySubmit when the queue of the pool is full.
+	ErrPoolFull = errors.New("worker pool queue is full")
 )
 
 type IWorkerPool interface {
-	Submit(task func())
+	Submit(task func()) error
+	SubmitContext(ctx context.Context, task func()) error
+	TrySubmit(task func()) error
 	Wait()
-	worker()
+	Shutdown(ctx context.Context) error
+	Resize(minWorkers, maxWorkers int)
+	Stats() WorkerPoolStats
+}
+
+// WorkerPoolConfig bounds an elastic worker pool. The pool starts MinWorkers workers and adds
+// one, up to MaxWorkers, whenever a task would wait for a worker or the tasks waited longer than
+// MaxLatency. A worker above MinWorkers exits once it has been idle for IdleTimeout.
+type WorkerPoolConfig struct {
+	Name          string        // Pool label of the worker pool metrics
+	MinWorkers    int           // Workers kept when idle; 0 lets the pool scale down to none
+	MaxWorkers    int           // Upper bound (at least MinWorkers and 1)
+	QueueSize     int           // Tasks waiting for a worker before Submit blocks (default 4*MaxWorkers)
+	MaxLatency    time.Duration // Queue wait above which a worker is added (default 50ms)
+	IdleTimeout   time.Duration // Idle time after which a worker above MinWorkers exits (default 30s)
+	ScaleInterval time.Duration // How often the queue latency is checked (default 100ms)
+}
+
+// WorkerPoolStats is a point-in-time view of a worker pool.
+type WorkerPoolStats struct {
+	Name       string   `json:"name"`
+	Workers    int      `json:"workers"`
+	Busy       int      `json:"busy"`
+	MinWorkers int      `json:"minWorkers"`
+	MaxWorkers int      `json:"maxWorkers"`
+	Queued     int      `json:"queued"`
+	Pending    int      `json:"pending"` // Queued and running tasks
+	Submitted  uint64   `json:"submitted"`
+	Completed  uint64   `json:"completed"`
+	Panicked   uint64   `json:"panicked"`
+	Rejected   uint64   `json:"rejected"`
+	ScaleUps   uint64   `json:"scaleUps"`
+	ScaleDowns uint64   `json:"scaleDowns"`
+	AvgWait    Duration `json:"avgWait"` // Average time a completed task waited in the queue
+	AvgRun     Duration `json:"avgRun"`  // Average run time of a completed task
+	Closed     bool     `json:"closed"`
+}
+
+type poolTask struct {
+	fn       func()
+	enqueued time.Time
 }
 
+// WorkerPool runs submitted tasks on an elastic set of workers.
 type WorkerPool struct {
-	Tasks chan func()
-	Wg    sync.WaitGroup
+	mu      sync.Mutex
+	idle    *sync.Cond // Signalled when the last pending task completes
+	name    string
+	cfg     WorkerPoolConfig
+	tasks   chan poolTask
+	closing chan struct{} // Closed by Shutdown to release blocked submitters
+	closed  bool
+
+	workers int
+	busy    int
+	pending int
+	maxWait time.Duration // Longest queue wait since the last scaling check
+
+	submitted, completed, panicked, rejected uint64
+	scaleUps, scaleDowns                     uint64
+	waitTotal, runTotal                      time.Duration
+
+	submitters sync.WaitGroup // Submitters that may still send to tasks
+	running    sync.WaitGroup // Workers
+	done       chan struct{}  // Closed once every worker has exited
+	stopScaler chan struct{}
 }
 
-func (wp *WorkerPool) worker() {
-	for task := range wp.Tasks {
-		task()
-		wp.Wg.Done()
-		logz.Info("Task completed", nil)
+// Submit queues task, blocking while the queue is full.
+func (wp *WorkerPool) Submit(task func()) error {
+	return wp.submit(context.Background(), task, true)
+}
+
+// SubmitContext queues task, blocking while the queue is full until ctx is done.
+func (wp *WorkerPool) SubmitContext(ctx context.Context, task func()) error {
+	return wp.submit(ctx, task, true)
+}
+
+// TrySubmit queues task, or returns ErrPoolFull at once when the queue is full.
+func (wp *WorkerPool) TrySubmit(task func()) error {
+	return wp.submit(context.Background(), task, false)
+}
+
+func (wp *WorkerPool) submit(ctx context.Context, task func(), block bool) error {
+	wp.mu.Lock()
+	if wp.closed {
+		wp.rejected++
+		wp.mu.Unlock()
+		return ErrPoolClosed
+	}
+	wp.pending++
+	wp.submitted++
+	// Add a worker when no idle one is left for this task.
+	if len(wp.tasks)+1 > wp.workers-wp.busy {
+		wp.grow()
+	}
+	wp.submitters.Add(1)
+	wp.mu.Unlock()
+	defer wp.submitters.Done()
+
+	t := poolTask{fn: task, enqueued: time.Now()}
+	select {
+	case wp.tasks <- t:
+		return nil
+	default:
+	}
+	if !block {
+		wp.unsubmit()
+		return ErrPoolFull
+	}
+	select {
+	case wp.tasks <- t:
+		return nil
+	case <-wp.closing:
+		wp.unsubmit()
+		return ErrPoolClosed
+	case <-ctx.Done():
+		wp.unsubmit()
+		return ctx.Err()
 	}
 }
-func (wp *WorkerPool) Submit(task func()) {
-	wp.Wg.Add(1)
-	wp.Tasks <- task
-	logz.Info("Task submitted", nil)
+
+// unsubmit takes back a task that could not be queued.
+func (wp *WorkerPool) unsubmit() {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.submitted--
+	wp.rejected++
+	wp.finish()
 }
+
+// Wait blocks until every submitted task has completed.
 func (wp *WorkerPool) Wait() {
-	wp.Wg.Wait()
-	logz.Info("All tasks completed", nil)
+	wp.mu.Lock()
+	for wp.pending > 0 {
+		wp.idle.Wait()
+	}
+	wp.mu.Unlock()
+	logz.Info("All tasks completed", map[string]interface{}{"pool": wp.name})
+}
+
+// Shutdown stops accepting tasks, lets the workers drain the queue and waits for them to exit,
+// or for ctx to be done. It can be called more than once.
+func (wp *WorkerPool) Shutdown(ctx context.Context) error {
+	wp.mu.Lock()
+	if !wp.closed {
+		wp.closed = true
+		close(wp.closing)
+		close(wp.stopScaler)
+		wp.mu.Unlock()
+		// No task is sent once the blocked submitters are released.
+		wp.submitters.Wait()
+		// The scaler is gone, so start a worker for the tasks left behind by an idle one that
+		// retired before they were queued.
+		wp.mu.Lock()
+		if len(wp.tasks) > 0 && wp.workers == 0 {
+			wp.grow()
+		}
+		wp.mu.Unlock()
+		close(wp.tasks)
+		go func() {
+			wp.running.Wait()
+			close(wp.done)
+		}()
+	} else {
+		wp.mu.Unlock()
+	}
+
+	select {
+	case <-wp.done:
+		logz.Info("Worker pool shut down", map[string]interface{}{"pool": wp.name})
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("worker pool %s: %w", wp.name, ctx.Err())
+	}
+}
+
+// Resize changes the worker bounds. Workers are added at once to reach minWorkers; the ones
+// above maxWorkers exit after their current task.
+func (wp *WorkerPool) Resize(minWorkers, maxWorkers int) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.cfg.MinWorkers, wp.cfg.MaxWorkers = normalizeBounds(minWorkers, maxWorkers)
+	for !wp.closed && wp.workers < wp.cfg.MinWorkers {
+		wp.grow()
+	}
 }
 
+// Stats returns the current figures of the pool.
+func (wp *WorkerPool) Stats() WorkerPoolStats {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	stats := WorkerPoolStats{
+		Name:       wp.name,
+		Workers:    wp.workers,
+		Busy:       wp.busy,
+		MinWorkers: wp.cfg.MinWorkers,
+		MaxWorkers: wp.cfg.MaxWorkers,
+		Queued:     len(wp.tasks),
+		Pending:    wp.pending,
+		Submitted:  wp.submitted,
+		Completed:  wp.completed,
+		Panicked:   wp.panicked,
+		Rejected:   wp.rejected,
+		ScaleUps:   wp.scaleUps,
+		ScaleDowns: wp.scaleDowns,
+		Closed:     wp.closed,
+	}
+	if wp.completed > 0 {
+		stats.AvgWait = Duration(wp.waitTotal / time.Duration(wp.completed))
+		stats.AvgRun = Duration(wp.runTotal / time.Duration(wp.completed))
+	}
+	return stats
+}
+
+// grow starts a worker unless the pool is at its maximum. Callers must hold wp.mu.
+func (wp *WorkerPool) grow() {
+	if wp.workers >= wp.cfg.MaxWorkers {
+		return
+	}
+	wp.workers++
+	wp.scaleUps++
+	wp.running.Add(1)
+	go wp.worker()
+}
+
+// retire lets the calling worker exit when the pool has more workers than it needs. Callers must
+// hold wp.mu.
+func (wp *WorkerPool) retire(idle bool) bool {
+	if wp.workers > wp.cfg.MaxWorkers || (idle && wp.workers > wp.cfg.MinWorkers && len(wp.tasks) == 0) {
+		wp.workers--
+		wp.scaleDowns++
+		return true
+	}
+	return false
+}
+
+// finish accounts for a task that left the pool. Callers must hold wp.mu.
+func (wp *WorkerPool) finish() {
+	wp.pending--
+	if wp.pending == 0 {
+		wp.idle.Broadcast()
+	}
+}
+
+func (wp *WorkerPool) worker() {
+	defer wp.running.Done()
+
+	idle := time.NewTimer(wp.cfg.IdleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case t, ok := <-wp.tasks:
+			if !ok {
+				wp.mu.Lock()
+				wp.workers--
+				wp.mu.Unlock()
+				return
+			}
+			wp.run(t)
+			wp.mu.Lock()
+			retired := wp.retire(false)
+			wp.mu.Unlock()
+			if retired {
+				return
+			}
+			idle.Reset(wp.cfg.IdleTimeout)
+		case <-idle.C:
+			wp.mu.Lock()
+			retired := !wp.closed && wp.retire(true)
+			wp.mu.Unlock()
+			if retired {
+				return
+			}
+			idle.Reset(wp.cfg.IdleTimeout)
+		}
+	}
+}
+
+// run runs one task, recovering a panic so that the worker survives it.
+func (wp *WorkerPool) run(t poolTask) {
+	started := time.Now()
+	wait := started.Sub(t.enqueued)
+	wp.mu.Lock()
+	wp.busy++
+	wp.maxWait = max(wp.maxWait, wait)
+	wp.mu.Unlock()
+
+	panicked := false
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = true
+				logz.Error(fmt.Sprintf("Task of worker pool %s panicked: %v", wp.name, r), map[string]interface{}{"pool": wp.name, "stack": string(debug.Stack()), "showData": true})
+			}
+		}()
+		t.fn()
+	}()
+	elapsed := time.Since(started)
+	workerTaskSeconds.Observe(elapsed.Seconds(), wp.name)
+	workerTasksTotal.Inc(wp.name)
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	wp.busy--
+	wp.completed++
+	if panicked {
+		wp.panicked++
+	}
+	wp.waitTotal += wait
+	wp.runTotal += elapsed
+	wp.finish()
+}
+
+// scale adds a worker whenever the tasks waited longer than MaxLatency since the previous check,
+// or when tasks are queued and no worker is left.
+func (wp *WorkerPool) scale() {
+	ticker := time.NewTicker(wp.cfg.ScaleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wp.stopScaler:
+			return
+		case <-ticker.C:
+		}
+		wp.mu.Lock()
+		if len(wp.tasks) > 0 && (wp.maxWait > wp.cfg.MaxLatency || wp.workers == 0) {
+			wp.grow()
+		}
+		wp.maxWait = 0
+		wp.mu.Unlock()
+	}
+}
+
+func normalizeBounds(minWorkers, maxWorkers int) (int, int) {
+	minWorkers = max(minWorkers, 0)
+	maxWorkers = max(maxWorkers, minWorkers, 1)
+	return minWorkers, maxWorkers
+}
+
+// NewWorkerPool creates a pool of one to size workers.
 func NewWorkerPool(size int) IWorkerPool {
-	pool := WorkerPool{Tasks: make(chan func(), size)}
-	for i := 0; i < size; i++ {
-		go pool.worker()
+	return NewElasticWorkerPool(WorkerPoolConfig{MinWorkers: DefaultPoolMinWorkers, MaxWorkers: size})
+}
+
+// NewElasticWorkerPool creates a pool scaling within the bounds of config.
+func NewElasticWorkerPool(config WorkerPoolConfig) IWorkerPool {
+	config.MinWorkers, config.MaxWorkers = normalizeBounds(config.MinWorkers, config.MaxWorkers)
+	if config.QueueSize <= 0 {
+		config.QueueSize = 4 * config.MaxWorkers
+	}
+	if config.MaxLatency <= 0 {
+		config.MaxLatency = DefaultPoolMaxLatency
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = DefaultPoolIdleTimeout
+	}
+	if config.ScaleInterval <= 0 {
+		config.ScaleInterval = DefaultPoolScaleInterval
+	}
+
+	pool := &WorkerPool{
+		name:       config.Name,
+		cfg:        config,
+		tasks:      make(chan poolTask, config.QueueSize),
+		closing:    make(chan struct{}),
+		done:       make(chan struct{}),
+		stopScaler: make(chan struct{}),
+	}
+	pool.idle = sync.NewCond(&pool.mu)
+	pool.mu.Lock()
+	for pool.workers < config.MinWorkers {
+		pool.grow()
 	}
-	logz.Info("Worker pool created", map[string]interface{}{"size": size})
-	return &pool
+	pool.scaleUps = 0
+	pool.mu.Unlock()
+	go pool.scale()
+	logz.Info("Worker pool created", map[string]interface{}{"pool": config.Name, "minWorkers": config.MinWorkers, "maxWorkers": config.MaxWorkers})
+	return pool
 }
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolShutdownDrains(t *testing.T) {
+	tests := []struct {
+		name   string
+		config WorkerPoolConfig
+		idle   time.Duration // Wait before submitting, long enough for idle workers to retire
+	}{
+		{name: "single worker", config: WorkerPoolConfig{MinWorkers: 1, MaxWorkers: 1, QueueSize: 16}},
+		{name: "elastic", config: WorkerPoolConfig{MinWorkers: 1, MaxWorkers: 4, QueueSize: 16}},
+		{name: "scaled down to none", config: WorkerPoolConfig{MinWorkers: 0, MaxWorkers: 2, QueueSize: 16, IdleTimeout: time.Millisecond}, idle: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := NewElasticWorkerPool(tt.config)
+			time.Sleep(tt.idle)
+
+			gate := make(chan struct{})
+			var ran atomic.Int32
+			const tasks = 10
+			for i := 0; i < tasks; i++ {
+				if err := pool.Submit(func() { <-gate; ran.Add(1) }); err != nil {
+					t.Fatalf("Submit %d: %v", i, err)
+				}
+			}
+
+			shut := make(chan error, 1)
+			go func() { shut <- pool.Shutdown(context.Background()) }()
+			time.Sleep(20 * time.Millisecond)
+			if err := pool.Submit(func() {}); !errors.Is(err, ErrPoolClosed) {
+				t.Fatalf("Submit during Shutdown: err = %v, want %v", err, ErrPoolClosed)
+			}
+			close(gate)
+
+			select {
+			case err := <-shut:
+				if err != nil {
+					t.Fatalf("Shutdown: %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Shutdown did not return")
+			}
+			if got := ran.Load(); got != tasks {
+				t.Fatalf("%d tasks ran, want %d", got, tasks)
+			}
+			if stats := pool.Stats(); !stats.Closed || stats.Pending != 0 || stats.Workers != 0 {
+				t.Fatalf("stats after Shutdown = %+v", stats)
+			}
+		})
+	}
+}
+
+func TestWorkerPoolShutdownTimeout(t *testing.T) {
+	pool := NewElasticWorkerPool(WorkerPoolConfig{MinWorkers: 1, MaxWorkers: 1})
+	gate := make(chan struct{})
+	if err := pool.Submit(func() { <-gate }); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown with a running task: err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(gate)
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown: %v", err)
+	}
+}
+
+func TestWorkerPoolWait(t *testing.T) {
+	pool := NewElasticWorkerPool(WorkerPoolConfig{MinWorkers: 1, MaxWorkers: 3, QueueSize: 4})
+	defer func() { _ = pool.Shutdown(context.Background()) }()
+
+	var ran atomic.Int32
+	const tasks = 20
+	for i := 0; i < tasks; i++ {
+		if err := pool.Submit(func() { time.Sleep(time.Millisecond); ran.Add(1) }); err != nil {
+			t.Fatalf("Submit %d: %v", i, err)
+		}
+	}
+	pool.Wait()
+	if got := ran.Load(); got != tasks {
+		t.Fatalf("%d tasks ran after Wait, want %d", got, tasks)
+	}
+}
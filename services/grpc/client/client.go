@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "github.com/rafa-mori/golife/services/proto"
+)
+
+// Client is a Go client of the golife gRPC API.
+type Client struct {
+	conn *grpc.ClientConn
+	api  pb.LifecycleManagerClient
+}
+
+// Dial connects to a golife gRPC server. Without options the connection is insecure, which
+// suits local and Unix-socket targets ("unix:///run/golife.grpc").
+func Dial(target string, opts ...grpc.DialOption) (*Client, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, api: pb.NewLifecycleManagerClient(conn)}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error { return c.conn.Close() }
+
+// API returns the generated client for the calls not wrapped here.
+func (c *Client) API() pb.LifecycleManagerClient { return c.api }
+
+// Start starts a process, registering it first when command is not empty.
+func (c *Client) Start(ctx context.Context, name, command string, args []string, restart bool) error {
+	_, err := c.api.StartProcess(ctx, &pb.StartProcessRequest{Name: name, Command: command, Args: args, Restart: restart})
+	return err
+}
+
+// Stop stops a process, or every process when name is empty, and returns the outcome of each one.
+func (c *Client) Stop(ctx context.Context, name string) ([]*pb.StopResult, error) {
+	resp, err := c.api.StopProcess(ctx, &pb.StopProcessRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Results, nil
+}
+
+// Restart restarts a process, or every process when name is empty.
+func (c *Client) Restart(ctx context.Context, name string) error {
+	_, err := c.api.RestartProcess(ctx, &pb.RestartProcessRequest{Name: name})
+	return err
+}
+
+// Pause freezes the process tree of a process.
+func (c *Client) Pause(ctx context.Context, name string) error {
+	_, err := c.api.PauseProcess(ctx, &pb.PauseProcessRequest{Name: name})
+	return err
+}
+
+// Resume lets a paused process run again.
+func (c *Client) Resume(ctx context.Context, name string) error {
+	_, err := c.api.ResumeProcess(ctx, &pb.ResumeProcessRequest{Name: name})
+	return err
+}
+
+// Reload applies the reload strategy of a process.
+func (c *Client) Reload(ctx context.Context, name string) error {
+	_, err := c.api.ReloadProcess(ctx, &pb.ReloadProcessRequest{Name: name})
+	return err
+}
+
+// Status returns the human readable status of the processes.
+func (c *Client) Status(ctx context.Context) (string, error) {
+	resp, err := c.api.GetStatus(ctx, &pb.GetStatusRequest{})
+	if err != nil {
+		return "", err
+	}
+	return resp.Status, nil
+}
+
+// List returns the structured status of every process.
+func (c *Client) List(ctx context.Context) ([]*pb.ProcessStatus, error) {
+	resp, err := c.api.ListProcesses(ctx, &pb.ListProcessesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Processes, nil
+}
+
+// Trigger triggers event in stage.
+func (c *Client) Trigger(ctx context.Context, stage, event, data string) error {
+	_, err := c.api.TriggerEvent(ctx, &pb.TriggerEventRequest{Stage: stage, Event: event, Data: data})
+	return err
+}
+
+// Transition moves the lifecycle to stage.
+func (c *Client) Transition(ctx context.Context, stage, data string) error {
+	_, err := c.api.TransitionStage(ctx, &pb.TransitionStageRequest{Stage: stage, Data: data})
+	return err
+}
+
+// RegisterStage adds a stage to the lifecycle.
+func (c *Client) RegisterStage(ctx context.Context, req *pb.RegisterStageRequest) error {
+	_, err := c.api.RegisterStage(ctx, req)
+	return err
+}
+
+// RegisterEvent adds event to stage.
+func (c *Client) RegisterEvent(ctx context.Context, stage, event string) error {
+	_, err := c.api.RegisterEvent(ctx, &pb.RegisterEventRequest{Stage: stage, Event: event})
+	return err
+}
+
+// Watch calls fn for every event matching req until ctx is cancelled, the server ends the
+// stream or fn returns an error.
+func (c *Client) Watch(ctx context.Context, req *pb.WatchEventsRequest, fn func(*pb.Event) error) error {
+	if req == nil {
+		req = &pb.WatchEventsRequest{}
+	}
+	stream, err := c.api.WatchEvents(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		ev, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+}
@@ -2,59 +2,434 @@ package grpc
 
 import (
 	"context"
-	"log"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net"
+	"os"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/rafa-mori/golife/internal"
 	pb "github.com/rafa-mori/golife/services/proto"
+	l "github.com/rafa-mori/logz"
 )
 
-type server struct {
+// DefaultAddr is the listen address used when Config.Addr and $GOLIFE_GRPC_ADDR are empty.
+const DefaultAddr = ":50051"
+
+// Config configures the gRPC server.
+type Config struct {
+	Network    string              // "tcp" (default) or "unix"
+	Addr       string              // Listen address; $GOLIFE_GRPC_ADDR, then DefaultAddr, when empty
+	Options    []grpc.ServerOption // Extra options passed to grpc.NewServer
+	Reflection bool                // Register the reflection service
+}
+
+// Server serves a LifeCycleManager over gRPC.
+type Server struct {
 	pb.UnimplementedLifecycleManagerServer
 	lifecycleManager internal.LifeCycleManager
+
+	config Config
+	srv    *grpc.Server
+	mu     sync.Mutex
+	lis    net.Listener
+}
+
+// NewServer creates a server for lifecycleManager. Call Listen and Serve, or ListenAndServe, to run it.
+func NewServer(lifecycleManager internal.LifeCycleManager, config Config) *Server {
+	if config.Network == "" {
+		config.Network = "tcp"
+	}
+	if config.Addr == "" {
+		config.Addr = os.Getenv("GOLIFE_GRPC_ADDR")
+	}
+	if config.Addr == "" {
+		config.Addr = DefaultAddr
+	}
+	s := &Server{lifecycleManager: lifecycleManager, config: config, srv: grpc.NewServer(config.Options...)}
+	pb.RegisterLifecycleManagerServer(s.srv, s)
+	if config.Reflection {
+		reflection.Register(s.srv)
+	}
+	return s
 }
 
-func (s *server) StartProcess(ctx context.Context, req *pb.StartProcessRequest) (*pb.StartProcessResponse, error) {
-	process := internal.NewManagedProcess(req.Name, req.Command, req.Args, req.Wait, nil)
-	err := s.lifecycleManager.StartProcess(process)
+// Listen binds the configured address.
+func (s *Server) Listen() error {
+	if s.config.Network == "unix" {
+		_ = os.Remove(s.config.Addr)
+	}
+	lis, err := net.Listen(s.config.Network, s.config.Addr)
 	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.config.Addr, err)
+	}
+	s.mu.Lock()
+	s.lis = lis
+	s.mu.Unlock()
+	return nil
+}
+
+// Serve handles connections until Shutdown is called.
+func (s *Server) Serve() error {
+	s.mu.Lock()
+	lis := s.lis
+	s.mu.Unlock()
+	if lis == nil {
+		return fmt.Errorf("server is not listening")
+	}
+	l.Info(fmt.Sprintf("gRPC server listening on %s", lis.Addr()), map[string]interface{}{"context": "GoLife", "showData": false})
+	if err := s.srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// ListenAndServe binds the configured address and serves in the background.
+func (s *Server) ListenAndServe() error {
+	if err := s.Listen(); err != nil {
+		return err
+	}
+	go func() {
+		if err := s.Serve(); err != nil {
+			l.Error(fmt.Sprintf("gRPC server error: %v", err), map[string]interface{}{"context": "GoLife", "showData": true})
+		}
+	}()
+	return nil
+}
+
+// Addr returns the bound address, or nil before Listen.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lis == nil {
+		return nil
+	}
+	return s.lis.Addr()
+}
+
+// Shutdown stops accepting connections and waits for the pending RPCs. Streams still open
+// when ctx is done are cut with a hard stop.
+func (s *Server) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// StartGRPCServer serves lifecycleManager on addr in the background and returns the running server.
+func StartGRPCServer(lifecycleManager internal.LifeCycleManager, addr string) (*Server, error) {
+	s := NewServer(lifecycleManager, Config{Addr: addr, Reflection: true})
+	if err := s.ListenAndServe(); err != nil {
 		return nil, err
 	}
+	return s, nil
+}
+
+func (s *Server) StartProcess(ctx context.Context, req *pb.StartProcessRequest) (*pb.StartProcessResponse, error) {
+	process := s.lifecycleManager.GetProcess(req.Name)
+	if process == nil {
+		if req.Command == "" {
+			return nil, status.Errorf(codes.NotFound, "process %s is not registered and no command was given", req.Name)
+		}
+		process = internal.NewManagedProcess(req.Name, req.Command, req.Args, req.Wait, nil)
+		if req.Restart {
+			process.SetRestartPolicy(internal.DefaultSupervisorPolicy(internal.RestartAlways))
+		}
+		if err := s.lifecycleManager.AddProcess(process); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if err := s.lifecycleManager.StartProcess(process); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
 	return &pb.StartProcessResponse{Success: true}, nil
 }
 
-func (s *server) StopProcess(ctx context.Context, req *pb.StopProcessRequest) (*pb.StopProcessResponse, error) {
+func (s *Server) StopProcess(ctx context.Context, req *pb.StopProcessRequest) (*pb.StopProcessResponse, error) {
+	var report internal.StopReport
+	if req.Name == "" {
+		report = s.lifecycleManager.StopProcesses()
+	} else {
+		process := s.lifecycleManager.GetProcess(req.Name)
+		if process == nil {
+			return nil, status.Errorf(codes.NotFound, "process %s not found", req.Name)
+		}
+		started := time.Now()
+		outcome, err := process.Shutdown()
+		report = internal.StopReport{{Name: req.Name, Outcome: outcome, Duration: time.Since(started), Err: err}}
+	}
+
+	resp := &pb.StopProcessResponse{Success: report.Err() == nil}
+	for _, res := range report {
+		result := &pb.StopResult{Name: res.Name, Outcome: string(res.Outcome), DurationMs: res.Duration.Milliseconds()}
+		if res.Err != nil {
+			result.Error = res.Err.Error()
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+func (s *Server) RestartProcess(ctx context.Context, req *pb.RestartProcessRequest) (*pb.RestartProcessResponse, error) {
+	if req.Name == "" {
+		if err := s.lifecycleManager.Restart(); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return &pb.RestartProcessResponse{Success: true}, nil
+	}
 	process := s.lifecycleManager.GetProcess(req.Name)
 	if process == nil {
-		return nil, fmt.Errorf("process not found")
+		return nil, status.Errorf(codes.NotFound, "process %s not found", req.Name)
 	}
-	err := s.lifecycleManager.StopProcess(process)
-	if err != nil {
+	if err := process.Restart(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.RestartProcessResponse{Success: true}, nil
+}
+
+func (s *Server) PauseProcess(ctx context.Context, req *pb.PauseProcessRequest) (*pb.PauseProcessResponse, error) {
+	if err := s.pausable(req.Name); err != nil {
+		return nil, err
+	}
+	if err := s.lifecycleManager.Pause(req.Name); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &pb.PauseProcessResponse{Success: true}, nil
+}
+
+func (s *Server) ResumeProcess(ctx context.Context, req *pb.ResumeProcessRequest) (*pb.ResumeProcessResponse, error) {
+	if err := s.pausable(req.Name); err != nil {
 		return nil, err
 	}
-	return &pb.StopProcessResponse{Success: true}, nil
+	if err := s.lifecycleManager.Resume(req.Name); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &pb.ResumeProcessResponse{Success: true}, nil
+}
+
+func (s *Server) ReloadProcess(ctx context.Context, req *pb.ReloadProcessRequest) (*pb.ReloadProcessResponse, error) {
+	unit := s.lifecycleManager.GetUnit(req.Name)
+	if unit == nil {
+		return nil, status.Errorf(codes.NotFound, "process %s not found", req.Name)
+	}
+	if _, ok := unit.(internal.Reloadable); !ok {
+		return nil, status.Errorf(codes.Unimplemented, "%s %s cannot be reloaded", unit.Kind(), req.Name)
+	}
+	if err := s.lifecycleManager.Reload(req.Name); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &pb.ReloadProcessResponse{Success: true}, nil
+}
+
+// pausable maps a missing or non pausable unit to its status code.
+func (s *Server) pausable(name string) error {
+	unit := s.lifecycleManager.GetUnit(name)
+	if unit == nil {
+		return status.Errorf(codes.NotFound, "process %s not found", name)
+	}
+	if _, ok := unit.(internal.Pausable); !ok {
+		return status.Errorf(codes.Unimplemented, "%s %s cannot be paused", unit.Kind(), name)
+	}
+	return nil
 }
 
-func (s *server) GetStatus(ctx context.Context, req *pb.GetStatusRequest) (*pb.GetStatusResponse, error) {
-	status := s.lifecycleManager.Status()
-	return &pb.GetStatusResponse{Status: status}, nil
+func (s *Server) GetStatus(ctx context.Context, req *pb.GetStatusRequest) (*pb.GetStatusResponse, error) {
+	resp := &pb.GetStatusResponse{}
+	for _, process := range s.lifecycleManager.Snapshot() {
+		resp.Status += process.String() + "\n"
+		resp.Processes = append(resp.Processes, toProcessStatus(process))
+	}
+	return resp, nil
+}
+
+func (s *Server) ListProcesses(ctx context.Context, req *pb.ListProcessesRequest) (*pb.ListProcessesResponse, error) {
+	resp := &pb.ListProcessesResponse{}
+	for _, process := range s.lifecycleManager.Snapshot() {
+		resp.Processes = append(resp.Processes, toProcessStatus(process))
+	}
+	return resp, nil
+}
+
+func (s *Server) TriggerEvent(ctx context.Context, req *pb.TriggerEventRequest) (*pb.TriggerEventResponse, error) {
+	stage := s.lifecycleManager.GetStage(req.Stage)
+	if stage == nil {
+		return nil, status.Errorf(codes.NotFound, "stage %s not found", req.Stage)
+	}
+	if !s.lifecycleManager.Handles(req.Stage, req.Event) {
+		return nil, status.Errorf(codes.NotFound, "event %s not found in stage %s", req.Event, req.Stage)
+	}
+	if err := s.lifecycleManager.TriggerContext(ctx, req.Stage, req.Event, req.Data); err != nil {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	return &pb.TriggerEventResponse{Success: true}, nil
 }
 
-func StartGRPCServer(lifecycleManager internal.LifeCycleManager) {
-	lis, err := net.Listen("tcp", ":50051")
+func (s *Server) TransitionStage(ctx context.Context, req *pb.TransitionStageRequest) (*pb.TransitionStageResponse, error) {
+	from := ""
+	if current := s.lifecycleManager.GetCurrentStage(); current != nil {
+		from = current.Name()
+	}
+	var data interface{}
+	if req.Data != "" {
+		data = req.Data
+	}
+	if err := s.lifecycleManager.TransitionTo(req.Stage, data); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &pb.TransitionStageResponse{Success: true, From: from, To: req.Stage}, nil
+}
+
+func (s *Server) RegisterStage(ctx context.Context, req *pb.RegisterStageRequest) (*pb.RegisterStageResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "stage name is required")
+	}
+	if s.lifecycleManager.GetStage(req.Name) != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "stage %s already registered", req.Name)
+	}
+	stage := internal.NewStage(req.Name, req.Description, req.Type).AllowNext(req.Next...).AllowPrev(req.Prev...)
+	if req.Workers > 0 {
+		stage.AutoScale(int(req.Workers))
+	}
+	if err := s.lifecycleManager.RegisterStage(stage); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.RegisterStageResponse{Success: true}, nil
+}
+
+func (s *Server) RegisterEvent(ctx context.Context, req *pb.RegisterEventRequest) (*pb.RegisterEventResponse, error) {
+	stage, event := req.Stage, req.Event
+	err := s.lifecycleManager.RegisterEvent(event, stage, func(data interface{}) {
+		l.Info(fmt.Sprintf("Event %s received in stage %s", event, stage), map[string]interface{}{"context": "GoLife", "stage": stage, "event": event, "data": data, "showData": true})
+	})
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &pb.RegisterEventResponse{Success: true}, nil
+}
+
+func (s *Server) WatchEvents(req *pb.WatchEventsRequest, stream grpc.ServerStreamingServer[pb.Event]) error {
+	filter := func(ev internal.LifecycleEvent) bool {
+		if len(req.Stages) > 0 && !contains(req.Stages, ev.Stage) {
+			return false
+		}
+		if len(req.Processes) > 0 && !contains(req.Processes, ev.Process) {
+			return false
+		}
+		if len(req.Types) == 0 {
+			return true
+		}
+		for _, topic := range req.Types {
+			if internal.MatchTopic(topic, ev.Type) {
+				return true
+			}
+		}
+		return false
 	}
 
-	s := grpc.NewServer()
-	pb.RegisterLifecycleManagerServer(s, &server{lifecycleManager: lifecycleManager})
-	reflection.Register(s)
+	// Subscribe before replaying so that nothing published in between is lost.
+	bus := s.lifecycleManager.Bus()
+	sub := bus.SubscribeFunc(256, filter)
+	defer sub.Close()
+
+	sent := req.SinceId
+	if req.SinceId > 0 {
+		for _, ev := range bus.Since(req.SinceId) {
+			if !filter(ev) {
+				continue
+			}
+			if err := stream.Send(toEvent(ev)); err != nil {
+				return err
+			}
+			sent = ev.ID
+		}
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-sub.C():
+			if !ok {
+				return nil
+			}
+			if ev.ID <= sent {
+				continue
+			}
+			if err := stream.Send(toEvent(ev)); err != nil {
+				return err
+			}
+			sent = ev.ID
+		}
+	}
+}
+
+func toProcessStatus(process internal.ProcessStatus) *pb.ProcessStatus {
+	out := &pb.ProcessStatus{
+		Name:       process.Name,
+		Command:    process.Command,
+		Args:       process.Args,
+		Pid:        int32(process.Pid),
+		State:      string(process.State),
+		ExitCode:   int32(process.ExitCode),
+		ExitSignal: process.ExitSignal,
+		ExitReason: string(process.ExitReason),
+		Restarts:   int32(process.Restarts),
+		Health:     string(process.Health.Status),
+		Ready:      process.Health.Ready,
+		DependsOn:  process.DependsOn,
+		UptimeMs:   time.Duration(process.Uptime).Milliseconds(),
+		Resources: &pb.ResourceUsage{
+			CpuPercent: process.Resources.CPUPercent,
+			RssBytes:   process.Resources.RSSBytes,
+			Threads:    int32(process.Resources.Threads),
+			Fds:        int32(process.Resources.FDs),
+			ReadBytes:  process.Resources.ReadBytes,
+			WriteBytes: process.Resources.WriteBytes,
+			Processes:  int32(process.Resources.Processes),
+		},
+	}
+	if !process.StartedAt.IsZero() {
+		out.StartedAt = timestamppb.New(process.StartedAt)
+	}
+	return out
+}
+
+func toEvent(ev internal.LifecycleEvent) *pb.Event {
+	out := &pb.Event{Id: ev.ID, Type: string(ev.Type), Time: timestamppb.New(ev.Time), Process: ev.Process, Stage: ev.Stage, Event: ev.Event}
+	if ev.Data != nil {
+		if data, err := json.Marshal(ev.Data); err == nil {
+			out.Data = string(data)
+		} else {
+			out.Data = fmt.Sprint(ev.Data)
+		}
+	}
+	return out
+}
 
-	log.Println("gRPC server is running on port 50051")
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
 	}
+	return false
 }
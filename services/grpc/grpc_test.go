@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rafa-mori/golife/internal"
+	"github.com/rafa-mori/golife/services/grpc/client"
+	pb "github.com/rafa-mori/golife/services/proto"
+)
+
+// serveLifecycle serves a fresh lifecycle on a Unix socket and returns it with a connected client.
+func serveLifecycle(t *testing.T) (internal.LifeCycleManager, *client.Client) {
+	t.Helper()
+	lm := internal.NewLifecycleManager(nil, nil, nil, nil, nil, nil)
+	path := filepath.Join(t.TempDir(), "golife.grpc")
+	srv := NewServer(lm, Config{Network: "unix", Addr: path})
+	if err := srv.ListenAndServe(); err != nil {
+		t.Fatal(err)
+	}
+	c, err := client.Dial("unix://" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = c.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	})
+	return lm, c
+}
+
+func TestServerErrorCodes(t *testing.T) {
+	lm, c := serveLifecycle(t)
+	if err := lm.RegisterStage(internal.NewStage("boot", "", "")); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{name: "start unknown process without command", call: func() error { return c.Start(ctx, "api", "", nil, false) }, code: codes.NotFound},
+		{name: "stop unknown process", call: func() error { _, err := c.Stop(ctx, "api"); return err }, code: codes.NotFound},
+		{name: "restart unknown process", call: func() error { return c.Restart(ctx, "api") }, code: codes.NotFound},
+		{name: "pause unknown process", call: func() error { return c.Pause(ctx, "api") }, code: codes.NotFound},
+		{name: "resume unknown process", call: func() error { return c.Resume(ctx, "api") }, code: codes.NotFound},
+		{name: "reload unknown process", call: func() error { return c.Reload(ctx, "api") }, code: codes.NotFound},
+		{name: "trigger in unknown stage", call: func() error { return c.Trigger(ctx, "nowhere", "go", "") }, code: codes.NotFound},
+		{name: "trigger unknown event", call: func() error { return c.Trigger(ctx, "boot", "go", "") }, code: codes.NotFound},
+		{name: "transition to unknown stage", call: func() error { return c.Transition(ctx, "nowhere", "") }, code: codes.FailedPrecondition},
+		{name: "register unnamed stage", call: func() error { return c.RegisterStage(ctx, &pb.RegisterStageRequest{}) }, code: codes.InvalidArgument},
+		{name: "register stage twice", call: func() error { return c.RegisterStage(ctx, &pb.RegisterStageRequest{Name: "boot"}) }, code: codes.AlreadyExists},
+		{name: "register event in unknown stage", call: func() error { return c.RegisterEvent(ctx, "nowhere", "go") }, code: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != tt.code {
+				t.Fatalf("code = %s, want %s", got, tt.code)
+			}
+		})
+	}
+}
+
+func TestServerStagesAndEvents(t *testing.T) {
+	lm, c := serveLifecycle(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, req := range []*pb.RegisterStageRequest{{Name: "boot", Next: []string{"running"}}, {Name: "running", Prev: []string{"boot"}}} {
+		if err := c.RegisterStage(ctx, req); err != nil {
+			t.Fatalf("RegisterStage %s: %v", req.Name, err)
+		}
+	}
+	if err := c.RegisterEvent(ctx, "boot", "ping"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Transition(ctx, "boot", ""); err != nil {
+		t.Fatal(err)
+	}
+	since := lm.Bus().LastID()
+	if err := c.Trigger(ctx, "boot", "ping", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Transition(ctx, "running", "ready"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Transition(ctx, "boot", ""); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("transition back to boot: err = %v, want %s", err, codes.FailedPrecondition)
+	}
+
+	// The stage events published after since are replayed, the trigger is filtered out.
+	done := errors.New("done")
+	got := make([]string, 0)
+	err := c.Watch(ctx, &pb.WatchEventsRequest{SinceId: since, Types: []string{"stage.*"}}, func(ev *pb.Event) error {
+		got = append(got, ev.Type+" "+ev.Stage)
+		if len(got) == 2 {
+			return done
+		}
+		return nil
+	})
+	if !errors.Is(err, done) {
+		t.Fatalf("Watch: %v", err)
+	}
+	if want := []string{"stage.exited boot", "stage.entered running"}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("watched %v, want %v", got, want)
+	}
+	if current := lm.GetCurrentStage(); current == nil || current.Name() != "running" {
+		t.Fatalf("current stage = %v, want running", current)
+	}
+}
@@ -0,0 +1,611 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: lifecycle.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LifecycleManager_StartProcess_FullMethodName    = "/golife.v1.LifecycleManager/StartProcess"
+	LifecycleManager_StopProcess_FullMethodName     = "/golife.v1.LifecycleManager/StopProcess"
+	LifecycleManager_RestartProcess_FullMethodName  = "/golife.v1.LifecycleManager/RestartProcess"
+	LifecycleManager_PauseProcess_FullMethodName    = "/golife.v1.LifecycleManager/PauseProcess"
+	LifecycleManager_ResumeProcess_FullMethodName   = "/golife.v1.LifecycleManager/ResumeProcess"
+	LifecycleManager_ReloadProcess_FullMethodName   = "/golife.v1.LifecycleManager/ReloadProcess"
+	LifecycleManager_GetStatus_FullMethodName       = "/golife.v1.LifecycleManager/GetStatus"
+	LifecycleManager_ListProcesses_FullMethodName   = "/golife.v1.LifecycleManager/ListProcesses"
+	LifecycleManager_TriggerEvent_FullMethodName    = "/golife.v1.LifecycleManager/TriggerEvent"
+	LifecycleManager_TransitionStage_FullMethodName = "/golife.v1.LifecycleManager/TransitionStage"
+	LifecycleManager_RegisterStage_FullMethodName   = "/golife.v1.LifecycleManager/RegisterStage"
+	LifecycleManager_RegisterEvent_FullMethodName   = "/golife.v1.LifecycleManager/RegisterEvent"
+	LifecycleManager_WatchEvents_FullMethodName     = "/golife.v1.LifecycleManager/WatchEvents"
+)
+
+// LifecycleManagerClient is the client API for LifecycleManager service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LifecycleManager exposes a golife lifecycle manager over gRPC.
+type LifecycleManagerClient interface {
+	// StartProcess starts a registered process, registering it first when a command is given.
+	StartProcess(ctx context.Context, in *StartProcessRequest, opts ...grpc.CallOption) (*StartProcessResponse, error)
+	// StopProcess stops one process, or every process when no name is given.
+	StopProcess(ctx context.Context, in *StopProcessRequest, opts ...grpc.CallOption) (*StopProcessResponse, error)
+	// RestartProcess restarts one process, or every process when no name is given.
+	RestartProcess(ctx context.Context, in *RestartProcessRequest, opts ...grpc.CallOption) (*RestartProcessResponse, error)
+	// PauseProcess freezes the process tree of a process.
+	PauseProcess(ctx context.Context, in *PauseProcessRequest, opts ...grpc.CallOption) (*PauseProcessResponse, error)
+	// ResumeProcess lets a paused process run again.
+	ResumeProcess(ctx context.Context, in *ResumeProcessRequest, opts ...grpc.CallOption) (*ResumeProcessResponse, error)
+	// ReloadProcess applies the reload strategy of a process without restarting it.
+	ReloadProcess(ctx context.Context, in *ReloadProcessRequest, opts ...grpc.CallOption) (*ReloadProcessResponse, error)
+	// GetStatus returns the human readable status of the processes.
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+	// ListProcesses returns the structured status of every process.
+	ListProcesses(ctx context.Context, in *ListProcessesRequest, opts ...grpc.CallOption) (*ListProcessesResponse, error)
+	// TriggerEvent triggers an event in a stage.
+	TriggerEvent(ctx context.Context, in *TriggerEventRequest, opts ...grpc.CallOption) (*TriggerEventResponse, error)
+	// TransitionStage moves the lifecycle to another stage.
+	TransitionStage(ctx context.Context, in *TransitionStageRequest, opts ...grpc.CallOption) (*TransitionStageResponse, error)
+	// RegisterStage adds a stage to the lifecycle.
+	RegisterStage(ctx context.Context, in *RegisterStageRequest, opts ...grpc.CallOption) (*RegisterStageResponse, error)
+	// RegisterEvent adds an event to a stage; the handler logs the received data.
+	RegisterEvent(ctx context.Context, in *RegisterEventRequest, opts ...grpc.CallOption) (*RegisterEventResponse, error)
+	// WatchEvents streams the lifecycle events until the client cancels.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type lifecycleManagerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLifecycleManagerClient(cc grpc.ClientConnInterface) LifecycleManagerClient {
+	return &lifecycleManagerClient{cc}
+}
+
+func (c *lifecycleManagerClient) StartProcess(ctx context.Context, in *StartProcessRequest, opts ...grpc.CallOption) (*StartProcessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartProcessResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_StartProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) StopProcess(ctx context.Context, in *StopProcessRequest, opts ...grpc.CallOption) (*StopProcessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopProcessResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_StopProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) RestartProcess(ctx context.Context, in *RestartProcessRequest, opts ...grpc.CallOption) (*RestartProcessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestartProcessResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_RestartProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) PauseProcess(ctx context.Context, in *PauseProcessRequest, opts ...grpc.CallOption) (*PauseProcessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseProcessResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_PauseProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) ResumeProcess(ctx context.Context, in *ResumeProcessRequest, opts ...grpc.CallOption) (*ResumeProcessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumeProcessResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_ResumeProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) ReloadProcess(ctx context.Context, in *ReloadProcessRequest, opts ...grpc.CallOption) (*ReloadProcessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReloadProcessResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_ReloadProcess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) ListProcesses(ctx context.Context, in *ListProcessesRequest, opts ...grpc.CallOption) (*ListProcessesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProcessesResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_ListProcesses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) TriggerEvent(ctx context.Context, in *TriggerEventRequest, opts ...grpc.CallOption) (*TriggerEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TriggerEventResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_TriggerEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) TransitionStage(ctx context.Context, in *TransitionStageRequest, opts ...grpc.CallOption) (*TransitionStageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransitionStageResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_TransitionStage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) RegisterStage(ctx context.Context, in *RegisterStageRequest, opts ...grpc.CallOption) (*RegisterStageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterStageResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_RegisterStage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) RegisterEvent(ctx context.Context, in *RegisterEventRequest, opts ...grpc.CallOption) (*RegisterEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterEventResponse)
+	err := c.cc.Invoke(ctx, LifecycleManager_RegisterEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifecycleManagerClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LifecycleManager_ServiceDesc.Streams[0], LifecycleManager_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LifecycleManager_WatchEventsClient = grpc.ServerStreamingClient[Event]
+
+// LifecycleManagerServer is the server API for LifecycleManager service.
+// All implementations must embed UnimplementedLifecycleManagerServer
+// for forward compatibility.
+//
+// LifecycleManager exposes a golife lifecycle manager over gRPC.
+type LifecycleManagerServer interface {
+	// StartProcess starts a registered process, registering it first when a command is given.
+	StartProcess(context.Context, *StartProcessRequest) (*StartProcessResponse, error)
+	// StopProcess stops one process, or every process when no name is given.
+	StopProcess(context.Context, *StopProcessRequest) (*StopProcessResponse, error)
+	// RestartProcess restarts one process, or every process when no name is given.
+	RestartProcess(context.Context, *RestartProcessRequest) (*RestartProcessResponse, error)
+	// PauseProcess freezes the process tree of a process.
+	PauseProcess(context.Context, *PauseProcessRequest) (*PauseProcessResponse, error)
+	// ResumeProcess lets a paused process run again.
+	ResumeProcess(context.Context, *ResumeProcessRequest) (*ResumeProcessResponse, error)
+	// ReloadProcess applies the reload strategy of a process without restarting it.
+	ReloadProcess(context.Context, *ReloadProcessRequest) (*ReloadProcessResponse, error)
+	// GetStatus returns the human readable status of the processes.
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
+	// ListProcesses returns the structured status of every process.
+	ListProcesses(context.Context, *ListProcessesRequest) (*ListProcessesResponse, error)
+	// TriggerEvent triggers an event in a stage.
+	TriggerEvent(context.Context, *TriggerEventRequest) (*TriggerEventResponse, error)
+	// TransitionStage moves the lifecycle to another stage.
+	TransitionStage(context.Context, *TransitionStageRequest) (*TransitionStageResponse, error)
+	// RegisterStage adds a stage to the lifecycle.
+	RegisterStage(context.Context, *RegisterStageRequest) (*RegisterStageResponse, error)
+	// RegisterEvent adds an event to a stage; the handler logs the received data.
+	RegisterEvent(context.Context, *RegisterEventRequest) (*RegisterEventResponse, error)
+	// WatchEvents streams the lifecycle events until the client cancels.
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedLifecycleManagerServer()
+}
+
+// UnimplementedLifecycleManagerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLifecycleManagerServer struct{}
+
+func (UnimplementedLifecycleManagerServer) StartProcess(context.Context, *StartProcessRequest) (*StartProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartProcess not implemented")
+}
+func (UnimplementedLifecycleManagerServer) StopProcess(context.Context, *StopProcessRequest) (*StopProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopProcess not implemented")
+}
+func (UnimplementedLifecycleManagerServer) RestartProcess(context.Context, *RestartProcessRequest) (*RestartProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestartProcess not implemented")
+}
+func (UnimplementedLifecycleManagerServer) PauseProcess(context.Context, *PauseProcessRequest) (*PauseProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseProcess not implemented")
+}
+func (UnimplementedLifecycleManagerServer) ResumeProcess(context.Context, *ResumeProcessRequest) (*ResumeProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeProcess not implemented")
+}
+func (UnimplementedLifecycleManagerServer) ReloadProcess(context.Context, *ReloadProcessRequest) (*ReloadProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadProcess not implemented")
+}
+func (UnimplementedLifecycleManagerServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedLifecycleManagerServer) ListProcesses(context.Context, *ListProcessesRequest) (*ListProcessesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProcesses not implemented")
+}
+func (UnimplementedLifecycleManagerServer) TriggerEvent(context.Context, *TriggerEventRequest) (*TriggerEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerEvent not implemented")
+}
+func (UnimplementedLifecycleManagerServer) TransitionStage(context.Context, *TransitionStageRequest) (*TransitionStageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransitionStage not implemented")
+}
+func (UnimplementedLifecycleManagerServer) RegisterStage(context.Context, *RegisterStageRequest) (*RegisterStageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterStage not implemented")
+}
+func (UnimplementedLifecycleManagerServer) RegisterEvent(context.Context, *RegisterEventRequest) (*RegisterEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterEvent not implemented")
+}
+func (UnimplementedLifecycleManagerServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedLifecycleManagerServer) mustEmbedUnimplementedLifecycleManagerServer() {}
+func (UnimplementedLifecycleManagerServer) testEmbeddedByValue()                          {}
+
+// UnsafeLifecycleManagerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LifecycleManagerServer will
+// result in compilation errors.
+type UnsafeLifecycleManagerServer interface {
+	mustEmbedUnimplementedLifecycleManagerServer()
+}
+
+func RegisterLifecycleManagerServer(s grpc.ServiceRegistrar, srv LifecycleManagerServer) {
+	// If the following call pancis, it indicates UnimplementedLifecycleManagerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LifecycleManager_ServiceDesc, srv)
+}
+
+func _LifecycleManager_StartProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).StartProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_StartProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).StartProcess(ctx, req.(*StartProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_StopProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).StopProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_StopProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).StopProcess(ctx, req.(*StopProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_RestartProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestartProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).RestartProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_RestartProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).RestartProcess(ctx, req.(*RestartProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_PauseProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).PauseProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_PauseProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).PauseProcess(ctx, req.(*PauseProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_ResumeProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).ResumeProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_ResumeProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).ResumeProcess(ctx, req.(*ResumeProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_ReloadProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).ReloadProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_ReloadProcess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).ReloadProcess(ctx, req.(*ReloadProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_ListProcesses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProcessesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).ListProcesses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_ListProcesses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).ListProcesses(ctx, req.(*ListProcessesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_TriggerEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).TriggerEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_TriggerEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).TriggerEvent(ctx, req.(*TriggerEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_TransitionStage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransitionStageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).TransitionStage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_TransitionStage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).TransitionStage(ctx, req.(*TransitionStageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_RegisterStage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterStageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).RegisterStage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_RegisterStage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).RegisterStage(ctx, req.(*RegisterStageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_RegisterEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifecycleManagerServer).RegisterEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LifecycleManager_RegisterEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifecycleManagerServer).RegisterEvent(ctx, req.(*RegisterEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LifecycleManager_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LifecycleManagerServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LifecycleManager_WatchEventsServer = grpc.ServerStreamingServer[Event]
+
+// LifecycleManager_ServiceDesc is the grpc.ServiceDesc for LifecycleManager service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LifecycleManager_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "golife.v1.LifecycleManager",
+	HandlerType: (*LifecycleManagerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartProcess",
+			Handler:    _LifecycleManager_StartProcess_Handler,
+		},
+		{
+			MethodName: "StopProcess",
+			Handler:    _LifecycleManager_StopProcess_Handler,
+		},
+		{
+			MethodName: "RestartProcess",
+			Handler:    _LifecycleManager_RestartProcess_Handler,
+		},
+		{
+			MethodName: "PauseProcess",
+			Handler:    _LifecycleManager_PauseProcess_Handler,
+		},
+		{
+			MethodName: "ResumeProcess",
+			Handler:    _LifecycleManager_ResumeProcess_Handler,
+		},
+		{
+			MethodName: "ReloadProcess",
+			Handler:    _LifecycleManager_ReloadProcess_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _LifecycleManager_GetStatus_Handler,
+		},
+		{
+			MethodName: "ListProcesses",
+			Handler:    _LifecycleManager_ListProcesses_Handler,
+		},
+		{
+			MethodName: "TriggerEvent",
+			Handler:    _LifecycleManager_TriggerEvent_Handler,
+		},
+		{
+			MethodName: "TransitionStage",
+			Handler:    _LifecycleManager_TransitionStage_Handler,
+		},
+		{
+			MethodName: "RegisterStage",
+			Handler:    _LifecycleManager_RegisterStage_Handler,
+		},
+		{
+			MethodName: "RegisterEvent",
+			Handler:    _LifecycleManager_RegisterEvent_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _LifecycleManager_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "lifecycle.proto",
+}
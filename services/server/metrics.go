@@ -0,0 +1,28 @@
+package server
+
+import (
+	"github.com/rafa-mori/golife/internal"
+	"github.com/rafa-mori/logz"
+	"net/http"
+)
+
+// MetricsHandler serves DefaultMetrics and the process, stage and worker pool metrics of the
+// manager returned by manager in the Prometheus text format.
+func MetricsHandler(manager func() internal.LifeCycleManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var collectors []func(*internal.MetricsWriter)
+		if manager != nil {
+			if lm := manager(); lm != nil {
+				collectors = append(collectors, internal.LifecycleCollector(lm))
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := internal.DefaultMetrics.WritePrometheus(w, collectors...); err != nil {
+			logz.Warn("Error writing metrics: "+err.Error(), nil)
+		}
+	}
+}
+
+func RegisterMetricsEndpoint(mux *http.ServeMux, manager func() internal.LifeCycleManager) {
+	mux.HandleFunc("/metrics", MetricsHandler(manager))
+}
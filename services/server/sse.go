@@ -1,41 +1,160 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/rafa-mori/golife/internal"
 	"github.com/rafa-mori/logz"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func SSEHandler(w http.ResponseWriter, r *http.Request) {
-	// Set appropriate headers for SSE
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
+// SSEKeepAlive is the interval of the comment lines that keep idle connections open.
+var SSEKeepAlive = 15 * time.Second
 
-	// Create a channel to receive events
-	events := make(chan internal.IManagedProcessEvents)
+// SSEHandler streams the lifecycle events of the manager returned by manager as server-sent events.
+//
+// Events can be filtered with the stage, process and type query parameters; each one can be
+// repeated or hold a comma-separated list, and type accepts topics such as "process.*".
+// A client reconnecting with Last-Event-ID (or ?lastEventId=) first receives the events it missed,
+// as long as they are still in the bus history.
+func SSEHandler(manager func() internal.LifeCycleManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var lm internal.LifeCycleManager
+		if manager != nil {
+			lm = manager()
+		}
+		if lm == nil {
+			http.Error(w, "no lifecycle manager running", http.StatusServiceUnavailable)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		query := r.URL.Query()
+		filter := eventFilter(queryValues(query["stage"]), queryValues(query["process"]), queryValues(query["type"]))
+		lastID := r.Header.Get("Last-Event-ID")
+		if lastID == "" {
+			lastID = query.Get("lastEventId")
+		}
+
+		// Set appropriate headers for SSE
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		// Subscribe before replaying so that nothing published in between is lost.
+		bus := lm.Bus()
+		sub := bus.SubscribeFunc(256, filter)
+		defer sub.Close()
+
+		var sent uint64
+		if lastID != "" {
+			since, err := strconv.ParseUint(lastID, 10, 64)
+			if err == nil {
+				sent = since
+				for _, ev := range bus.Since(since) {
+					if !filter(ev) {
+						continue
+					}
+					if err := writeEvent(w, ev); err != nil {
+						return
+					}
+					sent = ev.ID
+				}
+				flusher.Flush()
+			}
+		}
+
+		keepAlive := time.NewTicker(SSEKeepAlive)
+		defer keepAlive.Stop()
+
+		ctx := r.Context()
+		for {
+			select {
+			case <-ctx.Done():
+				logz.Info("Client disconnected", nil)
+				return
+			case ev, ok := <-sub.C():
+				if !ok {
+					return
+				}
+				if ev.ID <= sent {
+					continue
+				}
+				if err := writeEvent(w, ev); err != nil {
+					return
+				}
+				sent = ev.ID
+				flusher.Flush()
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
 
-	// Register the channel to receive events from the lifecycle manager
-	//internal.RegisterEventChannel(events)
+func RegisterSSEEndpoint(mux *http.ServeMux, manager func() internal.LifeCycleManager) {
+	mux.HandleFunc("/events", SSEHandler(manager))
+}
 
-	// Handle client disconnection
-	ctx := r.Context()
-	go func() {
-		<-ctx.Done()
-		close(events)
-		logz.Info("Client disconnected", nil)
-	}()
+func writeEvent(w http.ResponseWriter, ev internal.LifecycleEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		data, _ = json.Marshal(internal.LifecycleEvent{ID: ev.ID, Type: ev.Type, Time: ev.Time, Process: ev.Process, Stage: ev.Stage, Event: ev.Event, Data: fmt.Sprint(ev.Data)})
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+	return err
+}
 
-	// Stream events to the client
-	for event := range events {
-		fmt.Fprintf(w, "data: %v\n\n", event)
-		if f, ok := w.(http.Flusher); ok {
-			f.Flush()
+// eventFilter accepts the events matching every non-empty list.
+func eventFilter(stages, processes, topics []string) func(internal.LifecycleEvent) bool {
+	return func(ev internal.LifecycleEvent) bool {
+		if len(stages) > 0 && !contains(stages, ev.Stage) {
+			return false
+		}
+		if len(processes) > 0 && !contains(processes, ev.Process) {
+			return false
+		}
+		if len(topics) == 0 {
+			return true
+		}
+		for _, topic := range topics {
+			if internal.MatchTopic(topic, ev.Type) {
+				return true
+			}
 		}
+		return false
 	}
 }
 
-func RegisterSSEEndpoint(mux *http.ServeMux) {
-	mux.HandleFunc("/events", SSEHandler)
+func queryValues(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"github.com/rafa-mori/golife/internal"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventFilter(t *testing.T) {
+	started := internal.LifecycleEvent{Type: internal.EventProcessStarted, Process: "api"}
+	entered := internal.LifecycleEvent{Type: internal.EventStageEntered, Stage: "running"}
+	tests := []struct {
+		name  string
+		query string
+		want  []bool // Verdicts for started and entered
+	}{
+		{name: "no filter", query: "", want: []bool{true, true}},
+		{name: "process", query: "process=api", want: []bool{true, false}},
+		{name: "other process", query: "process=db", want: []bool{false, false}},
+		{name: "stage", query: "stage=running", want: []bool{false, true}},
+		{name: "topic pattern", query: "type=process.*", want: []bool{true, false}},
+		{name: "comma separated topics", query: "type=process.exited,stage.%2A", want: []bool{false, true}},
+		{name: "repeated topics", query: "type=process.started&type=stage.entered", want: []bool{true, true}},
+		{name: "every list must match", query: "process=api&type=process.exited", want: []bool{false, false}},
+		{name: "blank values are ignored", query: "process=,+,&type=%2A", want: []bool{true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filter := eventFilter(queryValues(query["stage"]), queryValues(query["process"]), queryValues(query["type"]))
+			if got := []bool{filter(started), filter(entered)}; !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("verdicts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSEReplay(t *testing.T) {
+	lm := internal.NewLifecycleManager(nil, nil, nil, nil, nil, nil)
+	bus := lm.Bus()
+	first := bus.Publish(internal.LifecycleEvent{Type: internal.EventProcessStarted, Process: "api"})
+	bus.Publish(internal.LifecycleEvent{Type: internal.EventProcessStarted, Process: "db"})
+	missed := bus.Publish(internal.LifecycleEvent{Type: internal.EventProcessExited, Process: "api"})
+
+	srv := httptest.NewServer(SSEHandler(func() internal.LifeCycleManager { return lm }))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?process=api", nil)
+	req.Header.Set("Last-Event-ID", strconv.FormatUint(first.ID, 10))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	live := bus.Publish(internal.LifecycleEvent{Type: internal.EventProcessStopped, Process: "api"})
+	ids := make([]string, 0)
+	scanner := bufio.NewScanner(resp.Body)
+	for len(ids) < 2 && scanner.Scan() {
+		if id, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+			ids = append(ids, id)
+		}
+	}
+	// The missed event of api is replayed once, db is filtered out and the live event follows.
+	if want := []string{strconv.FormatUint(missed.ID, 10), strconv.FormatUint(live.ID, 10)}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("received ids %v, want %v", ids, want)
+	}
+}
+
+func TestSSEWithoutManager(t *testing.T) {
+	rec := httptest.NewRecorder()
+	SSEHandler(func() internal.LifeCycleManager { return nil })(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}